@@ -0,0 +1,130 @@
+// Package auth implements password authentication in the VERIFY state.
+//
+// The hub sends random data in GPA, the client answers with the hash of its
+// password followed by the random data in PAS. The hash function is the one
+// negotiated for the session, i.e. Tiger (TIGR).
+//
+// On the client side, PasswordFunc is plugged into client.Config. On the hub
+// side, Authenticator is plugged into hub.Config, it looks up passwords in a
+// CredentialStore.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/tth"
+)
+
+// Constants related to challenges.
+const (
+	// ChallengeSize is the size of the random data sent in GPA.
+	ChallengeSize int = tth.Size
+)
+
+// Error variables related to Authenticator.
+var (
+	ErrNotRegistered = errors.New("user is not registered")
+)
+
+// ComputePAS returns the response to the random data gpaData of a GPA
+// message, which is sent in PAS: the Tiger hash of password followed by
+// gpaData.
+func ComputePAS(password string, gpaData *encoding.Base32Value) *encoding.Base32Value {
+	data := make([]byte, 0, len(password)+len(gpaData.Raw()))
+	data = append(data, password...)
+	data = append(data, gpaData.Raw()...)
+
+	sum := tth.Tiger(data)
+
+	return encoding.NewBase32Value(sum[:])
+}
+
+// PasswordFunc returns a function computing PAS responses for password. It
+// is meant to be used as client.Config.Password.
+func PasswordFunc(password string) func(gpaData *encoding.Base32Value) (*encoding.Base32Value, error) {
+	return func(gpaData *encoding.Base32Value) (*encoding.Base32Value, error) {
+		return ComputePAS(password, gpaData), nil
+	}
+}
+
+// NewChallenge returns ChallengeSize bytes of random data to be sent in GPA.
+func NewChallenge() (*encoding.Base32Value, error) {
+	data := make([]byte, ChallengeSize)
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+
+	return encoding.NewBase32Value(data), nil
+}
+
+// VerifyPAS returns whether response, the password sent in PAS, matches
+// password and the random data gpaData sent in GPA. The responses are
+// compared in constant time.
+func VerifyPAS(password string, gpaData, response *encoding.Base32Value) bool {
+	if response == nil {
+		return false
+	}
+
+	expected := ComputePAS(password, gpaData)
+
+	return subtle.ConstantTimeCompare(expected.Raw(), response.Raw()) == 1
+}
+
+// CredentialStore provides the passwords of registered users.
+type CredentialStore interface {
+	// Password returns the password of the user with the passed in nick
+	// and CID. ok is false if the user is not registered.
+	Password(nick string, cid *encoding.Base32Value) (password string, ok bool, err error)
+}
+
+// MapStore is a CredentialStore mapping nicks to passwords.
+type MapStore map[string]string
+
+func (m MapStore) Password(nick string, _ *encoding.Base32Value) (string, bool, error) {
+	password, ok := m[nick]
+	return password, ok, nil
+}
+
+// Authenticator requests passwords from users registered in Store. It
+// implements hub.Authenticator.
+type Authenticator struct {
+	Store CredentialStore
+	// RegisteredOnly rejects users not registered in Store with
+	// ErrNotRegistered. Otherwise, they are let in without a password.
+	RegisteredOnly bool
+}
+
+// Challenge returns random data for users registered in Store.
+func (a *Authenticator) Challenge(info *message.INFContent) (data *encoding.Base32Value, required bool, err error) {
+	_, ok, err := a.Store.Password(info.NI.Value, info.ID.Value)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		if a.RegisteredOnly {
+			return nil, false, ErrNotRegistered
+		}
+		return nil, false, nil
+	}
+
+	data, err = NewChallenge()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// Verify verifies response against the password stored for the user.
+func (a *Authenticator) Verify(info *message.INFContent, data, response *encoding.Base32Value) bool {
+	password, ok, err := a.Store.Password(info.NI.Value, info.ID.Value)
+	if err != nil || !ok {
+		return false
+	}
+
+	return VerifyPAS(password, data, response)
+}
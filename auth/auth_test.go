@@ -0,0 +1,115 @@
+package auth_test
+
+import (
+	"net"
+	"time"
+
+	. "github.com/seoester/adcl/auth"
+	"github.com/seoester/adcl/client"
+	"github.com/seoester/adcl/hub"
+	"github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ hub.Authenticator = &Authenticator{}
+
+func infWithNick(nick string) *message.INFContent {
+	info := &message.INFContent{}
+	info.NI.Set(nick)
+	return info
+}
+
+var _ = Describe("PAS", func() {
+	var data *encoding.Base32Value
+
+	BeforeEach(func() {
+		var err error
+		data, err = NewChallenge()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(data.Raw()).Should(HaveLen(ChallengeSize))
+	})
+
+	It("should hash the password followed by the random data", func() {
+		sum := tth.Tiger(append([]byte("secret"), data.Raw()...))
+		Ω(ComputePAS("secret", data).Raw()).Should(Equal(sum[:]))
+
+		pas, err := PasswordFunc("secret")(data)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(pas.String()).Should(Equal(ComputePAS("secret", data).String()))
+	})
+
+	It("should verify responses", func() {
+		Ω(VerifyPAS("secret", data, ComputePAS("secret", data))).Should(BeTrue())
+		Ω(VerifyPAS("secret", data, ComputePAS("guess", data))).Should(BeFalse())
+		Ω(VerifyPAS("secret", data, encoding.NewBase32Value([]byte("short")))).Should(BeFalse())
+		Ω(VerifyPAS("secret", data, nil)).Should(BeFalse())
+
+		other, err := NewChallenge()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(VerifyPAS("secret", other, ComputePAS("secret", data))).Should(BeFalse())
+	})
+})
+
+var _ = Describe("Authenticator", func() {
+	var a *Authenticator
+
+	BeforeEach(func() {
+		a = &Authenticator{Store: MapStore{"alice": "secret"}}
+	})
+
+	It("should challenge registered users", func() {
+		info := infWithNick("alice")
+
+		data, required, err := a.Challenge(info)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(required).Should(BeTrue())
+
+		Ω(a.Verify(info, data, ComputePAS("secret", data))).Should(BeTrue())
+		Ω(a.Verify(info, data, ComputePAS("guess", data))).Should(BeFalse())
+		Ω(a.Verify(infWithNick("bob"), data, ComputePAS("secret", data))).Should(BeFalse())
+	})
+
+	It("should let unregistered users in unless RegisteredOnly is set", func() {
+		_, required, err := a.Challenge(infWithNick("bob"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(required).Should(BeFalse())
+
+		a.RegisteredOnly = true
+		_, _, err = a.Challenge(infWithNick("bob"))
+		Ω(err).Should(Equal(ErrNotRegistered))
+	})
+
+	It("should authenticate clients at a hub", func() {
+		h := hub.New(hub.Config{Authenticator: a})
+		defer h.Close()
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		go h.Serve(l)
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		id, err := identity.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		config := client.Config{PID: id.PID, CID: id.CID, Password: PasswordFunc("secret")}
+		config.INF.NI.Set("alice")
+
+		c := client.New(conn, config)
+		Ω(c.Start()).Should(Succeed())
+
+		for c.State() != message.StateNormal {
+			_, err := c.ReadEvent()
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+		Ω(h.Users()).Should(HaveLen(1))
+	})
+})
@@ -0,0 +1,411 @@
+// Package client implements the client side of hub connections. It drives a
+// connection through the states defined by BASE (PROTOCOL, IDENTIFY, VERIFY
+// and NORMAL) and reports everything happening on the connection as events.
+//
+// Usage:
+//
+//	c := client.New(conn, client.Config{PID: pid, CID: cid, INF: inf})
+//	if err := c.Start(); err != nil {
+//	    ...
+//	}
+//
+//	for {
+//	    ev, err := c.ReadEvent()
+//	    if err != nil {
+//	        ...
+//	    }
+//
+//	    switch ev := ev.(type) {
+//	    case client.StateEvent:
+//	        ...
+//	    case client.MessageEvent:
+//	        ...
+//	    }
+//	}
+//
+// The client does not start any goroutines, messages are only read when
+// ReadEvent is called.
+package client
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/protocol/writer"
+)
+
+// Error variables related to Client.
+var (
+	ErrAlreadyStarted    = errors.New("client has already been started")
+	ErrMissingIdentity   = errors.New("PID and CID are required")
+	ErrPasswordRequired  = errors.New("hub requested a password, but none is configured")
+	ErrUnexpectedMessage = errors.New("message is not allowed in the current state")
+	ErrBASENotSupported  = errors.New("hub does not support BASE")
+)
+
+// QuitError is returned by ReadEvent if the hub disconnects the client, i.e.
+// sends a QUI with the client's own SID. Quit contains the reason (MS), the
+// redirect address (RD) and the time to wait before reconnecting (TL), if
+// they have been sent by the hub.
+type QuitError struct {
+	Quit *message.QUIContent
+}
+
+func (e *QuitError) Error() string {
+	if e.Quit.MS.IsSet && e.Quit.MS.Value != "" {
+		return "disconnected by the hub: " + e.Quit.MS.Value
+	}
+
+	return "disconnected by the hub"
+}
+
+// Features which are always requested in the SUP sent by the client.
+var defaultFeatures = []string{"BASE", "TIGR"}
+
+// Config contains the parameters of a client session.
+type Config struct {
+	// PID and CID identify the client. They are required, see the identity
+	// package for generating them.
+	PID *encoding.Base32Value
+	CID *encoding.Base32Value
+
+	// INF is the INF sent in the IDENTIFY state. ID and PD are set from PID
+	// and CID, all other fields (e.g. NI, SU) are sent as they are.
+	INF message.INFContent
+
+	// Features are requested in addition to BASE and TIGR in the SUP sent to
+	// the hub.
+	Features []string
+
+	// Password computes the response to the hub's password request (GPA)
+	// from the random data sent by the hub. If Password is nil and the hub
+	// requests a password, ReadEvent returns ErrPasswordRequired.
+	Password func(data *encoding.Base32Value) (*encoding.Base32Value, error)
+}
+
+// Client is the client side of a hub connection.
+//
+// ReadEvent must not be called concurrently, all other methods are safe for
+// concurrent use.
+type Client struct {
+	config Config
+	parser *parser.Parser
+	writer *writer.Writer
+
+	// pending contains events, which are returned by the next calls of
+	// ReadEvent.
+	pending []Event
+
+	// inf is the INF last sent to the hub, see UpdateINF.
+	infMu sync.Mutex
+	inf   message.INFContent
+
+	mu          sync.Mutex
+	started     bool
+	state       message.State
+	sid         *encoding.Base32Value
+	hubFeatures message.FeatureSet
+}
+
+// New creates a new Client communicating over conn. Nothing is sent until
+// Start is called.
+func New(conn io.ReadWriter, config Config) *Client {
+	return &Client{
+		config:      config,
+		parser:      parser.New(bufio.NewReader(conn)),
+		writer:      writer.New(bufio.NewWriter(conn), writer.FlushPolicy{}),
+		state:       message.StateProtocol,
+		hubFeatures: message.NewFeatureSet(),
+	}
+}
+
+// State returns the current state of the session.
+func (c *Client) State() message.State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+// SID returns the SID assigned by the hub. It is nil before the IDENTIFY
+// state.
+func (c *Client) SID() *encoding.Base32Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sid
+}
+
+// HubFeatures returns the features announced by the hub (ISUP).
+func (c *Client) HubFeatures() message.FeatureSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return message.NewFeatureSet(c.hubFeatures.Features()...)
+}
+
+// Start starts the session by sending the SUP message to the hub.
+func (c *Client) Start() error {
+	if c.config.PID == nil || c.config.CID == nil {
+		return ErrMissingIdentity
+	}
+
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	c.started = true
+	c.mu.Unlock()
+
+	sup := &message.SUPContent{}
+	for _, feature := range append(defaultFeatures, c.config.Features...) {
+		sup.FeatureOps = append(sup.FeatureOps, message.FeatureOp{
+			OpAction: message.FeatureOpAdd,
+			Feature:  feature,
+		})
+	}
+
+	return c.Send(&message.Message{
+		Type:         message.TypeHubmessage,
+		Command:      message.CommandSUP,
+		HeaderFields: message.HubHeaderFields{},
+		Content:      sup,
+	})
+}
+
+// Send writes mes to the hub.
+func (c *Client) Send(mes *message.Message) error {
+	return c.writer.WriteMessage(mes)
+}
+
+// ReadEvent reads messages from the hub until an event occurs. Messages
+// relevant to the session state (SUP, SID, GPA and the client's own INF) are
+// handled by the client. An error is returned if the connection fails or the
+// hub violates the protocol. If the hub disconnects the client, a *QuitError
+// is returned.
+func (c *Client) ReadEvent() (Event, error) {
+	for len(c.pending) == 0 {
+		mes, err := c.parser.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.handleMessage(&mes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ev := c.pending[0]
+	c.pending = c.pending[1:]
+
+	return ev, nil
+}
+
+func (c *Client) emit(ev Event) {
+	c.pending = append(c.pending, ev)
+}
+
+// setState changes the state to state and emits a StateEvent.
+func (c *Client) setState(state message.State) {
+	c.mu.Lock()
+	from := c.state
+	c.state = state
+	c.mu.Unlock()
+
+	c.emit(StateEvent{From: from, To: state})
+}
+
+func (c *Client) handleMessage(mes *message.Message) error {
+	state := c.State()
+
+	switch {
+	case mes.Type == message.TypeInfomessage && mes.Command == message.CommandSUP:
+		return c.handleSUP(mes, state)
+	case mes.Type == message.TypeInfomessage && mes.Command == message.CommandSID:
+		return c.handleSID(mes, state)
+	case mes.Type == message.TypeInfomessage && mes.Command == message.CommandGPA:
+		return c.handleGPA(mes, state)
+	case mes.Type == message.TypeInfomessage && mes.Command == message.CommandINF:
+		c.emit(HubInfoEvent{Message: mes, Info: mes.Content.(*message.INFContent)})
+	case mes.Type == message.TypeBroadcast && mes.Command == message.CommandINF:
+		return c.handleINF(mes, state)
+	case mes.Type == message.TypeInfomessage && mes.Command == message.CommandSTA:
+		c.emit(StatusEvent{Message: mes, Status: mes.Content.(*message.STAContent)})
+	case mes.Type == message.TypeInfomessage && mes.Command == message.CommandQUI:
+		return c.handleQUI(mes)
+	default:
+		if state != message.StateNormal {
+			return ErrUnexpectedMessage
+		}
+		c.emit(MessageEvent{Message: mes})
+	}
+
+	return nil
+}
+
+func (c *Client) handleSUP(mes *message.Message, state message.State) error {
+	if state != message.StateProtocol && state != message.StateNormal {
+		return ErrUnexpectedMessage
+	}
+
+	c.mu.Lock()
+	c.hubFeatures.Apply(mes.Content.(*message.SUPContent).FeatureOps)
+	hasBASE := c.hubFeatures.Has("BASE")
+	c.mu.Unlock()
+
+	if !hasBASE {
+		return ErrBASENotSupported
+	}
+
+	return nil
+}
+
+func (c *Client) handleSID(mes *message.Message, state message.State) error {
+	if state != message.StateProtocol {
+		return ErrUnexpectedMessage
+	}
+
+	c.mu.Lock()
+	if len(c.hubFeatures) == 0 {
+		// SID must be preceded by SUP.
+		c.mu.Unlock()
+		return ErrUnexpectedMessage
+	}
+	sid := mes.Content.(*message.SIDContent).SID
+	c.sid = sid
+	c.mu.Unlock()
+
+	c.setState(message.StateIdentify)
+
+	c.infMu.Lock()
+	defer c.infMu.Unlock()
+
+	c.inf = c.identifiedINF(&c.config.INF)
+
+	return c.Send(&message.Message{
+		Type:         message.TypeBroadcast,
+		Command:      message.CommandINF,
+		HeaderFields: message.BroadcastHeaderFields{MySID: sid},
+		Content:      &c.inf,
+	})
+}
+
+// UpdateINF sends the fields of info which differ from the INF last sent to
+// the hub, i.e. info replaces the client's INF. Fields missing in info are
+// removed. ID and PD are set from PID and CID as in the INF sent in the
+// IDENTIFY state. Nothing is sent if no field has changed.
+//
+// ErrUnexpectedMessage is returned if the session is not in the NORMAL
+// state.
+func (c *Client) UpdateINF(info *message.INFContent) error {
+	if c.State() != message.StateNormal {
+		return ErrUnexpectedMessage
+	}
+
+	c.infMu.Lock()
+	defer c.infMu.Unlock()
+
+	next := c.identifiedINF(info)
+
+	update, changed := next.Diff(&c.inf)
+	if !changed {
+		return nil
+	}
+
+	err := c.Send(&message.Message{
+		Type:         message.TypeBroadcast,
+		Command:      message.CommandINF,
+		HeaderFields: message.BroadcastHeaderFields{MySID: c.SID()},
+		Content:      update,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.inf = next
+
+	return nil
+}
+
+// identifiedINF returns a copy of info with ID and PD set from the
+// configured CID and PID.
+func (c *Client) identifiedINF(info *message.INFContent) message.INFContent {
+	var inf message.INFContent
+	inf.Merge(info)
+
+	cons := message.INFContentConstructor{Content: &inf}
+	cons.SetID(c.config.CID, "ID"+c.config.CID.String())
+	cons.SetPD(c.config.PID, "PD"+c.config.PID.String())
+
+	return inf
+}
+
+func (c *Client) handleGPA(mes *message.Message, state message.State) error {
+	if state != message.StateIdentify {
+		return ErrUnexpectedMessage
+	}
+
+	c.setState(message.StateVerify)
+
+	if c.config.Password == nil {
+		return ErrPasswordRequired
+	}
+
+	password, err := c.config.Password(mes.Content.(*message.GPAContent).Data)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(&message.Message{
+		Type:         message.TypeHubmessage,
+		Command:      message.CommandPAS,
+		HeaderFields: message.HubHeaderFields{},
+		Content:      &message.PASContent{Password: password},
+	})
+}
+
+func (c *Client) handleINF(mes *message.Message, state message.State) error {
+	if state == message.StateProtocol {
+		return ErrUnexpectedMessage
+	}
+
+	sid, _ := mes.SourceSID()
+	own := sid.String() == c.SID().String()
+
+	c.emit(UserInfoEvent{
+		Message: mes,
+		SID:     sid,
+		Info:    mes.Content.(*message.INFContent),
+		Own:     own,
+	})
+
+	// The hub concludes the IDENTIFY/VERIFY state by sending the client's
+	// own INF.
+	if own && state != message.StateNormal {
+		c.setState(message.StateNormal)
+	}
+
+	return nil
+}
+
+// handleQUI handles QUI messages, which are allowed in every state. Before a
+// SID has been assigned, a QUI can only be directed at the client itself.
+func (c *Client) handleQUI(mes *message.Message) error {
+	quit := mes.Content.(*message.QUIContent)
+
+	own := c.SID()
+	if own == nil || quit.SID.String() == own.String() {
+		return &QuitError{Quit: quit}
+	}
+
+	c.emit(QuitEvent{Message: mes, SID: quit.SID, Quit: quit})
+
+	return nil
+}
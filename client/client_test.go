@@ -0,0 +1,346 @@
+package client_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+
+	. "github.com/seoester/adcl/client"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	testPID = "LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"
+	testCID = "AQEACQ5NQ6QXWXQQ2OLUJJTAFAEGMVVD7QDWWDI"
+)
+
+func mustBase32(s string) *encoding.Base32Value {
+	v, err := encoding.ParseBase32Value(s)
+	Ω(err).ShouldNot(HaveOccurred())
+	return v
+}
+
+// hubStandIn is the hub side of a net.Pipe connection.
+type hubStandIn struct {
+	conn       net.Conn
+	connReader *parser.ConnReader
+	// done is closed when the script passed to run has finished.
+	done chan struct{}
+}
+
+func newHubStandIn(conn net.Conn) *hubStandIn {
+	return &hubStandIn{
+		conn:       conn,
+		connReader: parser.NewConnReader(bufio.NewReader(conn)),
+	}
+}
+
+// read reads the next message sent by the client.
+func (h *hubStandIn) read() message.Message {
+	line, err := h.connReader.ReadMessageLine()
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+	mes, err := parser.ParseMessage(parser.NewMessageReader(line))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+	return mes
+}
+
+// send sends lines to the client with a single write.
+func (h *hubStandIn) send(lines ...string) {
+	_, err := h.conn.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+}
+
+// run runs script on a separate goroutine. If script fails, the connection is
+// closed so that the client does not wait for further messages.
+func (h *hubStandIn) run(script func()) {
+	h.done = make(chan struct{})
+
+	go func() {
+		defer GinkgoRecover()
+		defer close(h.done)
+		defer func() {
+			if r := recover(); r != nil {
+				h.conn.Close()
+				panic(r)
+			}
+		}()
+
+		script()
+	}()
+}
+
+// readEventsUntil reads events from c until an event satisfying stop is read,
+// it returns all events read.
+func readEventsUntil(c *Client, stop func(Event) bool) (events []Event) {
+	for {
+		ev, err := c.ReadEvent()
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+		events = append(events, ev)
+		if stop(ev) {
+			return
+		}
+	}
+}
+
+func isStateEvent(state message.State) func(Event) bool {
+	return func(ev Event) bool {
+		stateEv, ok := ev.(StateEvent)
+		return ok && stateEv.To == state
+	}
+}
+
+var _ = Describe("Client", func() {
+	var (
+		clientConn, hubConn net.Conn
+		hub                 *hubStandIn
+		config              Config
+	)
+
+	BeforeEach(func() {
+		clientConn, hubConn = net.Pipe()
+		hub = newHubStandIn(hubConn)
+
+		config = Config{
+			PID: mustBase32(testPID),
+			CID: mustBase32(testCID),
+		}
+		config.INF.NI.Set("tester")
+	})
+
+	AfterEach(func() {
+		if hub.done != nil {
+			Eventually(hub.done).Should(BeClosed())
+		}
+
+		clientConn.Close()
+		hubConn.Close()
+	})
+
+	It("should reach NORMAL state", func() {
+		c := New(clientConn, config)
+		Ω(c.State()).Should(Equal(message.StateProtocol))
+
+		hub.run(func() {
+
+			sup := hub.read()
+			Ω(sup.Type).Should(Equal(message.TypeHubmessage))
+			Ω(sup.Content.(*message.SUPContent).FeatureOps).Should(ConsistOf(
+				message.FeatureOp{OpAction: message.FeatureOpAdd, Feature: "BASE"},
+				message.FeatureOp{OpAction: message.FeatureOpAdd, Feature: "TIGR"},
+			))
+
+			hub.send("ISUP ADBASE ADTIGR", "ISID AAAB", "IINF CT32 VEtest\\shub NIhub")
+
+			inf := hub.read()
+			Ω(mustSourceSID(&inf)).Should(Equal("AAAB"))
+			cnt := inf.Content.(*message.INFContent)
+			Ω(cnt.ID.Value.String()).Should(Equal(testCID))
+			Ω(cnt.PD.Value.String()).Should(Equal(testPID))
+			Ω(cnt.NI.Value).Should(Equal("tester"))
+
+			hub.send("BINF AAAC NIother", "BINF AAAB IDAQEACQ5NQ6QXWXQQ2OLUJJTAFAEGMVVD7QDWWDI NItester")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		events := readEventsUntil(c, isStateEvent(message.StateNormal))
+		Ω(events).Should(HaveLen(5))
+		Ω(events[0]).Should(Equal(StateEvent{From: message.StateProtocol, To: message.StateIdentify}))
+		Ω(events[1]).Should(BeAssignableToTypeOf(HubInfoEvent{}))
+		Ω(events[1].(HubInfoEvent).Info.NI.Value).Should(Equal("hub"))
+		Ω(events[2].(UserInfoEvent).Own).Should(BeFalse())
+		Ω(events[2].(UserInfoEvent).SID.String()).Should(Equal("AAAC"))
+		Ω(events[3].(UserInfoEvent).Own).Should(BeTrue())
+		Ω(events[4]).Should(Equal(StateEvent{From: message.StateIdentify, To: message.StateNormal}))
+
+		Ω(c.State()).Should(Equal(message.StateNormal))
+		Ω(c.SID().String()).Should(Equal("AAAB"))
+		Ω(c.HubFeatures()).Should(Equal(message.NewFeatureSet("BASE", "TIGR")))
+
+		Eventually(hub.done).Should(BeClosed())
+
+		hub.run(func() {
+			hub.send("BMSG AAAC hello")
+		})
+		ev, err := c.ReadEvent()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ev.(MessageEvent).Message.Command).Should(Equal(message.CommandMSG))
+	})
+
+	It("should answer password requests in VERIFY state", func() {
+		config.Password = func(data *encoding.Base32Value) (*encoding.Base32Value, error) {
+			Ω(data.String()).Should(Equal("ABCDEFGH"))
+			return mustBase32("HGFEDCBA"), nil
+		}
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISUP ADBASE ADTIGR", "ISID AAAB")
+			hub.read()
+			hub.send("IGPA ABCDEFGH")
+
+			pas := hub.read()
+			Ω(pas.Command).Should(Equal(message.CommandPAS))
+			Ω(pas.Content.(*message.PASContent).Password.String()).Should(Equal("HGFEDCBA"))
+
+			hub.send("BINF AAAB NItester")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		events := readEventsUntil(c, isStateEvent(message.StateNormal))
+		Ω(events).Should(ContainElement(StateEvent{From: message.StateIdentify, To: message.StateVerify}))
+		Ω(events[len(events)-1]).Should(Equal(StateEvent{From: message.StateVerify, To: message.StateNormal}))
+	})
+
+	It("should send only changed INF fields", func() {
+		config.INF.DE.Set("some description")
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISUP ADBASE ADTIGR", "ISID AAAB")
+			hub.read()
+			hub.send("BINF AAAB IDAQEACQ5NQ6QXWXQQ2OLUJJTAFAEGMVVD7QDWWDI NItester")
+		})
+
+		info := message.INFContent{}
+		info.NI.Set("tester")
+		info.DE.Set("some description")
+		Ω(c.UpdateINF(&info)).Should(Equal(ErrUnexpectedMessage))
+
+		Ω(c.Start()).Should(Succeed())
+		readEventsUntil(c, isStateEvent(message.StateNormal))
+		Eventually(hub.done).Should(BeClosed())
+
+		hub.run(func() {
+			inf := hub.read()
+			Ω(mustSourceSID(&inf)).Should(Equal("AAAB"))
+			Ω(inf.Content.(*message.INFContent).Named()).Should(Equal(map[string]string{
+				"NI": "renamed",
+				"DE": "",
+			}))
+		})
+
+		// Unchanged, nothing is sent.
+		Ω(c.UpdateINF(&info)).Should(Succeed())
+
+		info.NI.Set("renamed")
+		info.DE.Unset()
+		Ω(c.UpdateINF(&info)).Should(Succeed())
+	})
+
+	It("should fail if a password is requested but not configured", func() {
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISUP ADBASE", "ISID AAAB")
+			hub.read()
+			hub.send("IGPA ABCDEFGH")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		var err error
+		for err == nil {
+			_, err = c.ReadEvent()
+		}
+		Ω(err).Should(Equal(ErrPasswordRequired))
+		Ω(c.State()).Should(Equal(message.StateVerify))
+	})
+
+	It("should report the hub's status messages and disconnection before NORMAL state", func() {
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISUP ADBASE", "ISID AAAB")
+			hub.read()
+			hub.send("ISTA 222 Nick\\staken", "IQUI AAAB MSNick\\staken")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		events := readEventsUntil(c, func(ev Event) bool {
+			_, ok := ev.(StatusEvent)
+			return ok
+		})
+		status := events[len(events)-1].(StatusEvent).Status
+		Ω(status.Code.Error).Should(Equal(message.ErrorCodeNickTaken))
+		Ω(status.Description).Should(Equal("Nick taken"))
+
+		_, err := c.ReadEvent()
+		Ω(err).Should(BeAssignableToTypeOf(&QuitError{}))
+		Ω(err.(*QuitError).Quit.MS.Value).Should(Equal("Nick taken"))
+		Ω(err.Error()).Should(ContainSubstring("Nick taken"))
+	})
+
+	It("should report other clients leaving", func() {
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISUP ADBASE", "ISID AAAB")
+			hub.read()
+			hub.send("BINF AAAC NIother", "BINF AAAB NItester", "IQUI AAAC")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		events := readEventsUntil(c, func(ev Event) bool {
+			_, ok := ev.(QuitEvent)
+			return ok
+		})
+		Ω(events[len(events)-1].(QuitEvent).SID.String()).Should(Equal("AAAC"))
+		Ω(c.State()).Should(Equal(message.StateNormal))
+	})
+
+	It("should reject protocol violations", func() {
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISID AAAB")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		_, err := c.ReadEvent()
+		Ω(err).Should(Equal(ErrUnexpectedMessage))
+	})
+
+	It("should require a hub supporting BASE", func() {
+		c := New(clientConn, config)
+
+		hub.run(func() {
+			hub.read()
+			hub.send("ISUP ADTIGR")
+		})
+
+		Ω(c.Start()).Should(Succeed())
+
+		_, err := c.ReadEvent()
+		Ω(err).Should(Equal(ErrBASENotSupported))
+	})
+
+	It("should require PID and CID", func() {
+		c := New(clientConn, Config{})
+		Ω(c.Start()).Should(Equal(ErrMissingIdentity))
+	})
+})
+
+func mustSourceSID(mes *message.Message) string {
+	sid, ok := mes.SourceSID()
+	ExpectWithOffset(1, ok).Should(BeTrue())
+	return sid.String()
+}
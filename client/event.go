@@ -0,0 +1,54 @@
+package client
+
+import (
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Event is returned by Client.ReadEvent. It is one of the ...Event types of
+// this package.
+type Event interface{}
+
+// StateEvent is emitted when the state of the session changes.
+type StateEvent struct {
+	From message.State
+	To   message.State
+}
+
+// HubInfoEvent is emitted when the hub sends its own INF (IINF).
+type HubInfoEvent struct {
+	Message *message.Message
+	Info    *message.INFContent
+}
+
+// UserInfoEvent is emitted when the hub sends the INF of a client (BINF),
+// including the client's own INF. The INF may be partial, it only contains
+// the fields which have been updated. Use INFContent.Merge to apply it to the
+// INF received before.
+type UserInfoEvent struct {
+	Message *message.Message
+	SID     *encoding.Base32Value
+	Info    *message.INFContent
+	// Own is true if the INF is the client's own INF.
+	Own bool
+}
+
+// QuitEvent is emitted when another client leaves the hub (IQUI). The client's
+// own QUI is returned as *QuitError by ReadEvent instead.
+type QuitEvent struct {
+	Message *message.Message
+	SID     *encoding.Base32Value
+	Quit    *message.QUIContent
+}
+
+// StatusEvent is emitted when the hub sends a status message (ISTA), which
+// may happen in every state.
+type StatusEvent struct {
+	Message *message.Message
+	Status  *message.STAContent
+}
+
+// MessageEvent is emitted for all messages not handled by the client itself.
+type MessageEvent struct {
+	Message *message.Message
+}
@@ -0,0 +1,560 @@
+// Package download downloads files from multiple sources in parallel.
+//
+// A file is split into segments, which are requested from the sources over
+// client-client connections (see the transfer package) and verified against
+// the leaf data (tthl) of the file before being written. Segments failing
+// verification are requested again from another source. The verified
+// segments are recorded in a progress file, so that an interrupted download
+// resumes where it stopped. The target file is synced before the progress
+// file is saved, which happens at most once per second and when Run returns.
+//
+// Usage:
+//
+//	config, err := download.ConfigFromResult(res, "/home/user/song.mp3")
+//	if err != nil {
+//	    ...
+//	}
+//
+//	d, err := download.New(config)
+//	if err != nil {
+//	    ...
+//	}
+//	defer d.Close()
+//
+//	err = d.Run([]download.Source{
+//	    {Name: cid1.String(), Conn: conn1},
+//	    {Name: cid2.String(), Conn: conn2},
+//	})
+package download
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/transfer"
+	"github.com/seoester/adcl/tth"
+)
+
+// Error variables related to Download.
+var (
+	ErrMissingTTH    = errors.New("TTH of the file is required")
+	ErrInvalidSize   = errors.New("invalid size, it must not be negative")
+	ErrNoSources     = errors.New("no source is able to provide the remaining segments")
+	ErrShortResponse = errors.New("source sent less data than requested")
+)
+
+// Constants related to Download.
+const (
+	// DefaultSegmentSize is the default size of the segments requested from
+	// sources.
+	DefaultSegmentSize int64 = 1 << 20
+	// ProgressSuffix is appended to the path of the target file to form the
+	// default path of the progress file.
+	ProgressSuffix = ".progress"
+
+	// saveInterval is the minimum time between two saves of the progress
+	// file while segments are downloaded.
+	saveInterval = time.Second
+)
+
+// Requester requests data from a source. It is implemented by
+// *transfer.Conn.
+type Requester interface {
+	Download(w io.Writer, get *message.GETContent) (n int64, err error)
+}
+
+var _ Requester = (*transfer.Conn)(nil)
+
+// Source is a client sharing the file.
+type Source struct {
+	// Name identifies the source, e.g. by its CID. Segments which failed
+	// verification are not requested again from a source of the same name.
+	Name string
+	// Conn is the connection to the source. It is only used by a single
+	// goroutine at a time.
+	Conn Requester
+}
+
+// Config contains the parameters of a Download.
+type Config struct {
+	// TTH is the Tiger tree hash root of the file, it is required.
+	TTH *encoding.Base32Value
+	// Size is the size of the file in bytes.
+	Size int64
+
+	// Path is the path of the target file.
+	Path string
+	// ProgressPath is the path of the progress file, it defaults to Path
+	// followed by ProgressSuffix. The progress file is removed once the
+	// download has completed.
+	ProgressPath string
+
+	// SegmentSize is the size of the segments requested from sources, it
+	// defaults to DefaultSegmentSize. It is rounded up to a multiple of the
+	// block size of the leaf data.
+	SegmentSize int64
+}
+
+// ConfigFromResult returns the Config downloading the file of the search
+// result res to path. ErrMissingTTH is returned if res lacks the TR field.
+func ConfigFromResult(res *message.RESContent, path string) (Config, error) {
+	if !res.TR.IsSet {
+		return Config{}, ErrMissingTTH
+	}
+
+	return Config{TTH: res.TR.Value, Size: int64(res.SI), Path: path}, nil
+}
+
+// Download is the download of a single file.
+type Download struct {
+	config Config
+	file   *os.File
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	progress *progress
+	verifier *tth.Verifier
+	done     []bool
+	// saving is set while the progress is saved, saved is the time of the
+	// last save.
+	saving bool
+	saved  time.Time
+
+	// The following fields describe the current Run.
+	pending []int
+	// failed contains the names of the sources which sent data failing
+	// verification for each segment.
+	failed   map[int]map[string]bool
+	inFlight int
+	// live counts the sources still in use by name.
+	live map[string]int
+	err  error
+}
+
+// New creates a Download. If a progress file of the file exists, the
+// download is resumed, otherwise the target file is created or truncated.
+// When resuming, the segments recorded as done are verified again, segments
+// which have been modified since are downloaded again. The progress file is
+// ignored if the target file does not exist anymore.
+func New(config Config) (*Download, error) {
+	if config.TTH == nil {
+		return nil, ErrMissingTTH
+	}
+	if config.Size < 0 {
+		return nil, ErrInvalidSize
+	}
+	if config.ProgressPath == "" {
+		config.ProgressPath = config.Path + ProgressSuffix
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = DefaultSegmentSize
+	}
+
+	p, err := loadProgress(config.ProgressPath, config.TTH.String(), config.Size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(config.Path); p != nil && os.IsNotExist(err) {
+		p = nil
+	}
+
+	file, err := os.OpenFile(config.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Download{config: config, file: file, progress: p}
+	d.cond = sync.NewCond(&d.mu)
+
+	if p == nil {
+		d.progress = &progress{
+			Version: progressVersion,
+			TTH:     config.TTH.String(),
+			Size:    config.Size,
+		}
+		if err := file.Truncate(0); err != nil {
+			file.Close()
+			return nil, err
+		}
+	} else if len(p.TTHL) > 0 || p.SegmentSize > 0 {
+		if err := d.setTTHL(p.TTHL); err != nil {
+			// The leaf data is requested again.
+			p.TTHL, p.SegmentSize, p.Done = nil, 0, nil
+		}
+	}
+
+	if err := file.Truncate(config.Size); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if d.verifier != nil {
+		if err := d.verifyDone(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// verifyDone verifies the segments recorded as done against the target file
+// and drops those failing verification, e.g. because the target file has been
+// truncated or modified.
+func (d *Download) verifyDone() error {
+	p := d.progress
+	buf := make([]byte, p.SegmentSize)
+	done := p.Done[:0]
+
+	for _, seg := range p.Done {
+		if seg < 0 || seg >= len(d.done) || !d.done[seg] {
+			continue
+		}
+
+		offset, n := d.segment(seg)
+		if _, err := d.file.ReadAt(buf[:n], offset); err != nil {
+			return err
+		}
+		if d.verifier.VerifySegment(offset, buf[:n]) != nil {
+			d.done[seg] = false
+			continue
+		}
+
+		done = append(done, seg)
+	}
+	p.Done = done
+
+	return nil
+}
+
+// setTTHL creates the verifier from the leaf data tthl and determines the
+// segments.
+func (d *Download) setTTHL(tthl []byte) error {
+	v, err := tth.NewVerifier(d.config.TTH, d.config.Size, tthl)
+	if err != nil {
+		return err
+	}
+
+	p := d.progress
+	if p.SegmentSize <= 0 || p.SegmentSize%v.BlockSize() != 0 {
+		blocks := (d.config.SegmentSize + v.BlockSize() - 1) / v.BlockSize()
+		p.SegmentSize, p.Done = blocks*v.BlockSize(), nil
+	}
+	p.TTHL = tthl
+
+	d.verifier = v
+	d.done = make([]bool, (d.config.Size+p.SegmentSize-1)/p.SegmentSize)
+	for _, seg := range p.Done {
+		if seg >= 0 && seg < len(d.done) {
+			d.done[seg] = true
+		}
+	}
+
+	return nil
+}
+
+// Progress returns the number of bytes downloaded and verified and the size
+// of the file.
+func (d *Download) Progress() (done, total int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for seg, ok := range d.done {
+		if ok {
+			_, n := d.segment(seg)
+			done += n
+		}
+	}
+
+	return done, d.config.Size
+}
+
+// segment returns the offset and the length of the segment seg.
+func (d *Download) segment(seg int) (offset, n int64) {
+	offset = int64(seg) * d.progress.SegmentSize
+	n = d.progress.SegmentSize
+	if offset+n > d.config.Size {
+		n = d.config.Size - offset
+	}
+
+	return offset, n
+}
+
+// Run downloads the missing segments from sources, each source is used by a
+// separate goroutine. A source is not used anymore after a request fails.
+// Run returns once the file is complete or ErrNoSources if the remaining
+// segments cannot be downloaded from any of the sources. Run may be called
+// again with other sources.
+func (d *Download) Run(sources []Source) error {
+	if d.config.Size > 0 && d.verifier == nil {
+		if err := d.fetchTTHL(sources); err != nil {
+			return err
+		}
+	}
+
+	d.mu.Lock()
+	d.pending = nil
+	for seg, ok := range d.done {
+		if !ok {
+			d.pending = append(d.pending, seg)
+		}
+	}
+	d.failed = make(map[int]map[string]bool)
+	d.inFlight, d.err = 0, nil
+	d.live = make(map[string]int)
+	for _, src := range sources {
+		d.live[src.Name]++
+	}
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for _, src := range sources {
+		go d.worker(src, &wg)
+	}
+	wg.Wait()
+
+	d.mu.Lock()
+	err, complete := d.err, len(d.pending) == 0
+	d.mu.Unlock()
+
+	if err != nil || !complete {
+		if cErr := d.checkpoint(true); err == nil {
+			err = cErr
+		}
+		if err == nil {
+			err = ErrNoSources
+		}
+		return err
+	}
+
+	if err := d.file.Sync(); err != nil {
+		return err
+	}
+	if err := os.Remove(d.config.ProgressPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// checkpoint saves the progress, at most once per saveInterval unless force
+// is set. The target file is synced first, so that the progress file only
+// lists segments which have reached the disk. checkpoint does nothing while
+// another save is in progress.
+func (d *Download) checkpoint(force bool) error {
+	d.mu.Lock()
+	if d.saving || (!force && time.Since(d.saved) < saveInterval) {
+		d.mu.Unlock()
+		return nil
+	}
+	d.saving = true
+	p := *d.progress
+	p.Done = append([]int(nil), d.progress.Done...)
+	d.mu.Unlock()
+
+	err := d.file.Sync()
+	if err == nil {
+		err = p.save(d.config.ProgressPath)
+	}
+
+	d.mu.Lock()
+	d.saving = false
+	d.saved = time.Now()
+	d.mu.Unlock()
+
+	return err
+}
+
+// fetchTTHL requests the leaf data from the first source able to provide it.
+func (d *Download) fetchTTHL(sources []Source) error {
+	var buf bytes.Buffer
+
+	for _, src := range sources {
+		buf.Reset()
+		_, err := src.Conn.Download(&buf, &message.GETContent{
+			Namespace: transfer.NamespaceTTHL,
+			Identifer: transfer.TTHIdentifier(d.config.TTH),
+			Bytes:     -1,
+		})
+		if err != nil {
+			continue
+		}
+
+		d.mu.Lock()
+		err = d.setTTHL(append([]byte(nil), buf.Bytes()...))
+		d.mu.Unlock()
+		if err == nil {
+			return d.checkpoint(true)
+		}
+	}
+
+	return ErrNoSources
+}
+
+func (d *Download) worker(src Source, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	var buf bytes.Buffer
+	for {
+		seg, ok := d.next(src.Name)
+		if !ok {
+			return
+		}
+
+		offset, err := d.fetch(src, seg, &buf)
+		if err == nil {
+			if _, err := d.file.WriteAt(buf.Bytes(), offset); err != nil {
+				d.abort(src.Name, seg, err)
+				return
+			}
+		}
+
+		if !d.finish(src.Name, seg, err) {
+			return
+		}
+		if err := d.checkpoint(false); err != nil {
+			d.fail(err)
+		}
+	}
+}
+
+// next returns the next segment to be requested from the source name. false
+// is returned if there is none left or the download has failed.
+func (d *Download) next(name string) (seg int, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		if d.err != nil || (len(d.pending) == 0 && d.inFlight == 0) {
+			d.leave(name)
+			return 0, false
+		}
+
+		for i, seg := range d.pending {
+			if !d.failed[seg][name] {
+				d.pending = append(d.pending[:i], d.pending[i+1:]...)
+				d.inFlight++
+				return seg, true
+			}
+		}
+
+		// The pending segments have failed verification with this source.
+		// Unless another source may still provide one of them, the
+		// download cannot complete.
+		if d.inFlight == 0 && !d.retryable() {
+			d.err = ErrNoSources
+			continue
+		}
+
+		d.cond.Wait()
+	}
+}
+
+// retryable reports whether a pending segment may be requested from a
+// source still in use, which has not sent data failing verification for it.
+func (d *Download) retryable() bool {
+	for _, seg := range d.pending {
+		for name := range d.live {
+			if !d.failed[seg][name] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// leave records that the source name is not used anymore.
+func (d *Download) leave(name string) {
+	if d.live[name]--; d.live[name] <= 0 {
+		delete(d.live, name)
+	}
+	d.cond.Broadcast()
+}
+
+// fetch requests the segment seg from src into buf and verifies it.
+func (d *Download) fetch(src Source, seg int, buf *bytes.Buffer) (offset int64, err error) {
+	offset, n := d.segment(seg)
+
+	buf.Reset()
+	m, err := src.Conn.Download(buf, &message.GETContent{
+		Namespace: transfer.NamespaceFile,
+		Identifer: transfer.TTHIdentifier(d.config.TTH),
+		StartPos:  int(offset),
+		Bytes:     int(n),
+	})
+	if err != nil {
+		return offset, err
+	}
+	if m != n {
+		return offset, ErrShortResponse
+	}
+
+	return offset, d.verifier.VerifySegment(offset, buf.Bytes())
+}
+
+// finish records the result err of requesting the segment seg from the
+// source name. It returns false if the source is not to be used anymore.
+func (d *Download) finish(name string, seg int, err error) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.cond.Broadcast()
+
+	d.inFlight--
+
+	switch {
+	case err == nil:
+		d.done[seg] = true
+		d.progress.Done = append(d.progress.Done, seg)
+		return true
+
+	case errors.Is(err, tth.ErrSegmentMismatch):
+		if d.failed[seg] == nil {
+			d.failed[seg] = make(map[string]bool)
+		}
+		d.failed[seg][name] = true
+		d.pending = append(d.pending, seg)
+		return true
+
+	default:
+		d.pending = append(d.pending, seg)
+		d.leave(name)
+		return false
+	}
+}
+
+// abort fails the download with err, which occurred while the source name
+// wrote the segment seg.
+func (d *Download) abort(name string, seg int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inFlight--
+	d.pending = append(d.pending, seg)
+	d.err = err
+	d.leave(name)
+}
+
+// fail fails the download with err.
+func (d *Download) fail(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err == nil {
+		d.err = err
+	}
+	d.cond.Broadcast()
+}
+
+// Close closes the target file. The progress file is kept if the download
+// has not completed.
+func (d *Download) Close() error {
+	return d.file.Close()
+}
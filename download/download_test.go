@@ -0,0 +1,323 @@
+package download_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/seoester/adcl/download"
+	"github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/share"
+	"github.com/seoester/adcl/transfer"
+	"github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var errConnection = errors.New("connection failed")
+
+// memSource serves data and its leaf data from memory.
+type memSource struct {
+	data []byte
+	tthl []byte
+
+	// corrupt flips a byte of every segment sent.
+	corrupt bool
+	// failAfter fails all requests after failAfter segments have been sent,
+	// if it is positive.
+	failAfter int
+	// requested is closed when the first segment is requested.
+	requested chan struct{}
+	// wait delays all requests until it is closed.
+	wait chan struct{}
+	// delay delays all segments sent.
+	delay time.Duration
+
+	mu       sync.Mutex
+	segments int
+	tthls    int
+}
+
+func (m *memSource) Download(w io.Writer, get *message.GETContent) (int64, error) {
+	if m.wait != nil {
+		<-m.wait
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if get.Namespace == transfer.NamespaceTTHL {
+		m.tthls++
+		n, err := w.Write(m.tthl)
+		return int64(n), err
+	}
+
+	if m.failAfter > 0 && m.segments >= m.failAfter {
+		return 0, errConnection
+	}
+	if m.segments == 0 && m.requested != nil {
+		close(m.requested)
+	}
+	m.segments++
+	time.Sleep(m.delay)
+
+	data := append([]byte(nil), m.data[get.StartPos:get.StartPos+get.Bytes]...)
+	if m.corrupt {
+		data[0] ^= 0xff
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+var _ = Describe("Download", func() {
+	var (
+		dir    string
+		data   []byte
+		root   *encoding.Base32Value
+		tthl   []byte
+		config Config
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "download")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		data = make([]byte, 300*1024+123)
+		rand.New(rand.NewSource(1)).Read(data)
+
+		tree := tth.NewWithDepth(6)
+		tree.Write(data)
+		root, tthl = tree.Root(), tree.TTHL()
+
+		config = Config{
+			TTH:         root,
+			Size:        int64(len(data)),
+			Path:        filepath.Join(dir, "file.bin"),
+			SegmentSize: 32 * 1024,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	source := func() *memSource {
+		return &memSource{data: data, tthl: tthl}
+	}
+
+	run := func(sources ...Source) error {
+		d, err := New(config)
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+		defer d.Close()
+		return d.Run(sources)
+	}
+
+	expectComplete := func() {
+		written, err := ioutil.ReadFile(config.Path)
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+		ExpectWithOffset(1, written).Should(Equal(data))
+		ExpectWithOffset(1, config.Path+ProgressSuffix).ShouldNot(BeAnExistingFile())
+	}
+
+	It("should download from multiple sources", func() {
+		a, b := source(), source()
+		Ω(run(Source{Name: "a", Conn: a}, Source{Name: "b", Conn: b})).Should(Succeed())
+		expectComplete()
+
+		// 300 KiB and 123 bytes in segments of 32 KiB.
+		Ω(a.segments + b.segments).Should(Equal(10))
+		Ω(a.tthls + b.tthls).Should(Equal(1))
+	})
+
+	It("should retry segments failing verification from other sources", func() {
+		bad, good := source(), source()
+		bad.corrupt = true
+		bad.requested = make(chan struct{})
+		good.wait = bad.requested
+
+		Ω(run(Source{Name: "bad", Conn: bad}, Source{Name: "good", Conn: good})).Should(Succeed())
+		expectComplete()
+		Ω(bad.segments).Should(BeNumerically(">=", 1))
+	})
+
+	It("should retry a single segment from the other source", func() {
+		config.SegmentSize = int64(len(data))
+
+		// Whichever source requests the segment first, a corrupt segment
+		// must be retried from the other one.
+		for i := 0; i < 50; i++ {
+			bad, good := source(), source()
+			bad.corrupt = true
+			bad.delay = 5 * time.Millisecond
+
+			Ω(run(Source{Name: "good", Conn: good}, Source{Name: "bad", Conn: bad})).Should(Succeed())
+			expectComplete()
+			Ω(good.segments).Should(Equal(1))
+		}
+	})
+
+	It("should fail if no source provides valid data", func() {
+		bad := source()
+		bad.corrupt = true
+
+		Ω(run(Source{Name: "bad", Conn: bad})).Should(Equal(ErrNoSources))
+	})
+
+	It("should drop failing sources", func() {
+		flaky, good := source(), source()
+		flaky.failAfter = 1
+
+		Ω(run(Source{Name: "flaky", Conn: flaky}, Source{Name: "good", Conn: good})).Should(Succeed())
+		expectComplete()
+	})
+
+	It("should resume from the progress file", func() {
+		flaky := source()
+		flaky.failAfter = 3
+
+		d, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(d.Run([]Source{{Name: "flaky", Conn: flaky}})).Should(Equal(ErrNoSources))
+		done, total := d.Progress()
+		Ω(done).Should(Equal(int64(3 * 32 * 1024)))
+		Ω(total).Should(Equal(int64(len(data))))
+		Ω(d.Close()).Should(Succeed())
+		Ω(config.Path + ProgressSuffix).Should(BeAnExistingFile())
+
+		d, err = New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer d.Close()
+		done, _ = d.Progress()
+		Ω(done).Should(Equal(int64(3 * 32 * 1024)))
+
+		good := source()
+		Ω(d.Run([]Source{{Name: "good", Conn: good}})).Should(Succeed())
+		expectComplete()
+		Ω(good.segments).Should(Equal(7))
+		Ω(good.tthls).Should(Equal(0))
+	})
+
+	// interrupt downloads the first three segments and leaves the progress
+	// file behind.
+	interrupt := func() {
+		flaky := source()
+		flaky.failAfter = 3
+
+		d, err := New(config)
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+		ExpectWithOffset(1, d.Run([]Source{{Name: "flaky", Conn: flaky}})).Should(Equal(ErrNoSources))
+		ExpectWithOffset(1, d.Close()).Should(Succeed())
+	}
+
+	It("should download modified segments again when resuming", func() {
+		interrupt()
+
+		f, err := os.OpenFile(config.Path, os.O_RDWR, 0644)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = f.WriteAt([]byte("modified"), 32*1024+10)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(f.Close()).Should(Succeed())
+
+		d, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer d.Close()
+		done, _ := d.Progress()
+		Ω(done).Should(Equal(int64(2 * 32 * 1024)))
+
+		good := source()
+		Ω(d.Run([]Source{{Name: "good", Conn: good}})).Should(Succeed())
+		expectComplete()
+		Ω(good.segments).Should(Equal(8))
+	})
+
+	It("should start over if the target file has been removed", func() {
+		interrupt()
+		Ω(os.Remove(config.Path)).Should(Succeed())
+
+		d, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer d.Close()
+		done, _ := d.Progress()
+		Ω(done).Should(BeZero())
+
+		good := source()
+		Ω(d.Run([]Source{{Name: "good", Conn: good}})).Should(Succeed())
+		expectComplete()
+		Ω(good.segments).Should(Equal(10))
+	})
+
+	It("should start over if the progress file is corrupt", func() {
+		interrupt()
+		Ω(ioutil.WriteFile(config.Path+ProgressSuffix, []byte("{"), 0644)).Should(Succeed())
+
+		d, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer d.Close()
+		done, _ := d.Progress()
+		Ω(done).Should(BeZero())
+
+		Ω(d.Run([]Source{{Name: "good", Conn: source()}})).Should(Succeed())
+		expectComplete()
+	})
+
+	It("should download over client-client connections", func() {
+		shared := filepath.Join(dir, "shared")
+		Ω(os.MkdirAll(shared, 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(shared, "file.bin"), data, 0644)).Should(Succeed())
+
+		s, err := share.New(share.Config{Dirs: map[string]string{"Files": shared}})
+		Ω(err).ShouldNot(HaveOccurred())
+		defer s.Close()
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		uploader, err := identity.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		loader, err := identity.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var sources []Source
+		for i := 0; i < 2; i++ {
+			loaderConn, uploaderConn := net.Pipe()
+			defer loaderConn.Close()
+
+			go func() {
+				defer uploaderConn.Close()
+				c := transfer.New(uploaderConn, transfer.Config{CID: uploader.CID})
+				if c.Accept(func(string) (*encoding.Base32Value, bool) { return loader.CID, true }) == nil {
+					_ = c.Serve(&transfer.ShareSource{Share: s})
+				}
+			}()
+
+			c := transfer.New(loaderConn, transfer.Config{CID: loader.CID})
+			Ω(c.Connect("token", uploader.CID)).Should(Succeed())
+			sources = append(sources, Source{Name: fmt.Sprint(i), Conn: c})
+		}
+
+		Ω(run(sources...)).Should(Succeed())
+		expectComplete()
+	})
+
+	It("should create the config from search results", func() {
+		res := &message.RESContent{FN: "/file.bin", SI: len(data)}
+		_, err := ConfigFromResult(res, config.Path)
+		Ω(err).Should(Equal(ErrMissingTTH))
+
+		res.TR.Set(root)
+		c, err := ConfigFromResult(res, config.Path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(c.TTH.String()).Should(Equal(root.String()))
+		Ω(c.Size).Should(Equal(int64(len(data))))
+	})
+})
@@ -0,0 +1,75 @@
+package download
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// progressVersion is incremented whenever the format of the progress file
+// changes, progress files of other versions are discarded.
+const progressVersion = 1
+
+// progress is the persisted state of a download.
+type progress struct {
+	Version     int    `json:"version"`
+	TTH         string `json:"tth"`
+	Size        int64  `json:"size"`
+	SegmentSize int64  `json:"segment_size"`
+	// TTHL is the leaf data the segments are verified against, it is
+	// persisted so that it does not have to be requested again.
+	TTHL []byte `json:"tthl,omitempty"`
+	// Done contains the indexes of the verified segments written to the
+	// target file.
+	Done []int `json:"done"`
+}
+
+// loadProgress loads the progress stored at path. nil is returned if the file
+// does not exist, cannot be parsed, is of another version or belongs to
+// another file.
+func loadProgress(path, tth string, size int64) (*progress, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var p progress
+	if err := json.Unmarshal(data, &p); err != nil {
+		// A corrupt progress file is treated like a missing one, the
+		// download starts over.
+		return nil, nil
+	}
+	if p.Version != progressVersion || p.TTH != tth || p.Size != size {
+		return nil, nil
+	}
+
+	return &p, nil
+}
+
+// save writes p to path. The file is replaced atomically, so that a crash
+// does not leave a corrupt progress file behind.
+func (p *progress) save(path string) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
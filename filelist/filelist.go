@@ -0,0 +1,195 @@
+// Package filelist generates and parses ADC file lists.
+//
+// File lists are XML documents describing the files of a share, they are
+// requested with GET list (partial lists, uncompressed) or as the file
+// files.xml.bz2 (the complete list, compressed with bzip2). Both are written
+// from the files of a share by Write and WriteBZ2.
+//
+// Downloaded lists are read with a Decoder, which streams the entries of the
+// list and detects bzip2 compression, or parsed into a tree with Parse.
+//
+// Usage:
+//
+//	l, err := filelist.Parse(r)
+//	if err != nil {
+//	    ...
+//	}
+//
+//	dir, file := l.Lookup("/Music/song.mp3")
+package filelist
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Constants related to file lists.
+const (
+	// FileName is the name of the complete file list in the file namespace.
+	FileName = "files.xml.bz2"
+	// Namespace is the GET namespace of partial file lists.
+	Namespace = "list"
+	// Version is the version of the file list format.
+	Version = "1"
+	// Generator is the default value of the Generator attribute.
+	Generator = "adcl"
+)
+
+// Error variables related to file lists.
+var (
+	ErrInvalidBase    = errors.New("invalid base, it must start and end with a slash")
+	ErrInvalidRequest = errors.New("request is not a GET of a partial file list")
+	ErrNoFileListing  = errors.New("root element is not FileListing")
+	ErrInvalidEntry   = errors.New("invalid entry in file list")
+)
+
+// Listing is a parsed file list.
+type Listing struct {
+	CID       *encoding.Base32Value
+	Base      string
+	Generator string
+
+	// Root is the directory Base.
+	Root *Directory
+}
+
+// Directory is a directory of a file list.
+type Directory struct {
+	Name string
+	// Incomplete is true if the contents of the directory are not part of
+	// the list, they can be requested with a partial list.
+	Incomplete bool
+
+	Dirs  []*Directory
+	Files []*File
+}
+
+// File is a file of a file list.
+type File struct {
+	Name string
+	Size int64
+	TTH  *encoding.Base32Value
+}
+
+// Dir returns the subdirectory name of d or nil.
+func (d *Directory) Dir(name string) *Directory {
+	for _, sub := range d.Dirs {
+		if sub.Name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// File returns the file name of d or nil.
+func (d *Directory) File(name string) *File {
+	for _, f := range d.Files {
+		if f.Name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// Size returns the total size of the files below d, as far as they are part
+// of the list.
+func (d *Directory) Size() (size int64) {
+	for _, f := range d.Files {
+		size += f.Size
+	}
+	for _, sub := range d.Dirs {
+		size += sub.Size()
+	}
+
+	return size
+}
+
+// Lookup returns the directory or the file with the virtual path vpath. Paths
+// of directories may end with a slash. Both return values are nil if vpath is
+// not part of the list.
+func (l *Listing) Lookup(vpath string) (*Directory, *File) {
+	if vpath+"/" == l.Base {
+		return l.Root, nil
+	}
+	if !strings.HasPrefix(vpath, l.Base) {
+		return nil, nil
+	}
+
+	dir := l.Root
+	names := splitPath(strings.TrimPrefix(vpath, l.Base))
+	for i, name := range names {
+		if sub := dir.Dir(name); sub != nil {
+			dir = sub
+			continue
+		}
+		if i == len(names)-1 && !strings.HasSuffix(vpath, "/") {
+			if f := dir.File(name); f != nil {
+				return nil, f
+			}
+		}
+
+		return nil, nil
+	}
+
+	return dir, nil
+}
+
+// Merge inserts the partial list partial into l, replacing the directory at
+// partial.Base. ErrInvalidBase is returned if the directory is not part of l.
+func (l *Listing) Merge(partial *Listing) error {
+	if partial.Base == l.Base {
+		*l.Root = *partial.Root
+		return nil
+	}
+
+	parent, _ := l.Lookup(path.Dir(strings.TrimSuffix(partial.Base, "/")))
+	if parent == nil {
+		return ErrInvalidBase
+	}
+
+	name := path.Base(partial.Base)
+	dir := parent.Dir(name)
+	if dir == nil {
+		dir = &Directory{}
+		parent.Dirs = append(parent.Dirs, dir)
+	}
+	*dir = *partial.Root
+	dir.Name = name
+
+	return nil
+}
+
+// Request returns the base and whether the list is to be recursive for a
+// GET of a partial list.
+func Request(get *message.GETContent) (base string, recursive bool, err error) {
+	if get.Namespace != Namespace {
+		return "", false, ErrInvalidRequest
+	}
+	if !validBase(get.Identifer) {
+		return "", false, ErrInvalidBase
+	}
+
+	return get.Identifer, get.RE.IsSet && get.RE.Value == 1, nil
+}
+
+// validBase returns true if base is a valid directory path, i.e. it starts
+// and ends with a slash.
+func validBase(base string) bool {
+	return strings.HasPrefix(base, "/") && strings.HasSuffix(base, "/")
+}
+
+// splitPath splits the relative path p into its names.
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+
+	return strings.Split(p, "/")
+}
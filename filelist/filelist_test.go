@@ -0,0 +1,179 @@
+package filelist_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/seoester/adcl/filelist"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/share"
+	"github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func tthOf(data string) *encoding.Base32Value {
+	sum := tth.Sum([]byte(data))
+	return encoding.NewBase32Value(sum[:])
+}
+
+func file(path string, size int64) share.File {
+	return share.File{Path: path, Size: size, TTH: tthOf(path)}
+}
+
+var _ = Describe("File lists", func() {
+	var files []share.File
+	var cid *encoding.Base32Value
+
+	BeforeEach(func() {
+		cid = tthOf("cid")
+		files = []share.File{
+			file("/Music/Band/Live/concert.mp3", 5000),
+			file("/Music/Band/lyrics & notes.txt", 10),
+			file("/Music/Band/song.mp3", 1000),
+			file("/Music/intro.mp3", 100),
+			file(`/Videos/"quoted" <name>.mkv`, 7),
+		}
+	})
+
+	write := func(opts Options) []byte {
+		var buf bytes.Buffer
+		ExpectWithOffset(1, Write(&buf, files, opts)).Should(Succeed())
+		return buf.Bytes()
+	}
+
+	It("should write and parse complete lists", func() {
+		var buf bytes.Buffer
+		Ω(WriteBZ2(&buf, files, Options{CID: cid, Recursive: true})).Should(Succeed())
+		Ω(buf.String()).Should(HavePrefix("BZh"))
+
+		l, err := Parse(&buf)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(l.CID.String()).Should(Equal(cid.String()))
+		Ω(l.Base).Should(Equal("/"))
+		Ω(l.Generator).Should(Equal(Generator))
+		Ω(l.Root.Size()).Should(Equal(int64(6117)))
+
+		for _, f := range files {
+			dir, lf := l.Lookup(f.Path)
+			Ω(dir).Should(BeNil())
+			Ω(lf).ShouldNot(BeNil(), f.Path)
+			Ω(lf.Size).Should(Equal(f.Size))
+			Ω(lf.TTH.String()).Should(Equal(f.TTH.String()))
+		}
+
+		band, _ := l.Lookup("/Music/Band/")
+		Ω(band.Name).Should(Equal("Band"))
+		Ω(band.Files).Should(HaveLen(2))
+		Ω(band.Dirs).Should(HaveLen(1))
+		Ω(band.Size()).Should(Equal(int64(6010)))
+
+		music, _ := l.Lookup("/Music")
+		Ω(music.Dir("Band")).Should(BeIdenticalTo(band))
+		Ω(music.File("intro.mp3").Size).Should(Equal(int64(100)))
+
+		dir, f := l.Lookup("/Music/missing")
+		Ω(dir).Should(BeNil())
+		Ω(f).Should(BeNil())
+	})
+
+	It("should stream the entries of a list", func() {
+		dec, err := NewDecoder(bytes.NewReader(write(Options{Recursive: true})))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var paths []string
+		for {
+			e, err := dec.Next()
+			if err == io.EOF {
+				break
+			}
+			Ω(err).ShouldNot(HaveOccurred())
+			paths = append(paths, e.Path)
+		}
+
+		Ω(paths).Should(Equal([]string{
+			"/Music/",
+			"/Music/Band/",
+			"/Music/Band/Live/",
+			"/Music/Band/Live/concert.mp3",
+			"/Music/Band/lyrics & notes.txt",
+			"/Music/Band/song.mp3",
+			"/Music/intro.mp3",
+			"/Videos/",
+			`/Videos/"quoted" <name>.mkv`,
+		}))
+	})
+
+	It("should write partial lists", func() {
+		l, err := Parse(bytes.NewReader(write(Options{Base: "/Music/"})))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(l.Base).Should(Equal("/Music/"))
+		Ω(l.Root.Files).Should(HaveLen(1))
+		Ω(l.Root.Dirs).Should(HaveLen(1))
+		Ω(l.Root.Dirs[0].Name).Should(Equal("Band"))
+		Ω(l.Root.Dirs[0].Incomplete).Should(BeTrue())
+		Ω(l.Root.Dirs[0].Files).Should(BeEmpty())
+
+		_, f := l.Lookup("/Music/intro.mp3")
+		Ω(f).ShouldNot(BeNil())
+
+		partial, err := Parse(bytes.NewReader(write(Options{Base: "/Music/Band/", Recursive: true})))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(l.Merge(partial)).Should(Succeed())
+
+		band, _ := l.Lookup("/Music/Band/")
+		Ω(band.Incomplete).Should(BeFalse())
+		Ω(band.Size()).Should(Equal(int64(6010)))
+
+		Ω(l.Merge(&Listing{Base: "/Videos/", Root: &Directory{}})).Should(Equal(ErrInvalidBase))
+		Ω(Write(ioutil.Discard, files, Options{Base: "Music"})).Should(Equal(ErrInvalidBase))
+	})
+
+	It("should derive the options of GET list requests", func() {
+		get := &message.GETContent{Namespace: "list", Identifer: "/Music/"}
+		get.RE.Set(1)
+		base, recursive, err := Request(get)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(base).Should(Equal("/Music/"))
+		Ω(recursive).Should(BeTrue())
+
+		get = &message.GETContent{Namespace: "file", Identifer: FileName}
+		_, _, err = Request(get)
+		Ω(err).Should(Equal(ErrInvalidRequest))
+	})
+
+	It("should reject invalid lists", func() {
+		_, err := Parse(strings.NewReader(`<Other/>`))
+		Ω(err).Should(Equal(ErrNoFileListing))
+
+		_, err = Parse(strings.NewReader(`<FileListing Version="1" Base="/"><File Name="a" Size="x"/></FileListing>`))
+		Ω(err).Should(Equal(ErrInvalidEntry))
+
+		_, err = Parse(strings.NewReader(`<FileListing Version="1" Base="/"><Directory Name=".."/></FileListing>`))
+		Ω(err).Should(Equal(ErrInvalidEntry))
+
+		_, err = Parse(strings.NewReader(`<FileListing Version="1" Base="/"><Directory Name="a">`))
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should keep returning the first error", func() {
+		dec, err := NewDecoder(strings.NewReader(`<FileListing Version="1" Base="/"><Directory Name=".."></Directory><File Name="a" Size="1"/></FileListing>`))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			_, err = dec.Next()
+			Ω(err).Should(Equal(ErrInvalidEntry))
+		}
+	})
+
+	It("should skip unknown elements", func() {
+		l, err := Parse(strings.NewReader(`<FileListing Version="1" Base="/"><Meta><File Name="x" Size="1"/></Meta><File Name="a" Size="1"/></FileListing>`))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(l.Root.Files).Should(HaveLen(1))
+		Ω(l.Root.Files[0].Name).Should(Equal("a"))
+	})
+})
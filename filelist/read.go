@@ -0,0 +1,233 @@
+package filelist
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/encoding"
+)
+
+// Entry is a file or directory read from a file list.
+type Entry struct {
+	// Path is the virtual path of the entry, the paths of directories end
+	// with a slash.
+	Path string
+	// Size is the size of a file in bytes.
+	Size int64
+	// TTH is the Tiger tree hash root of a file, nil for directories.
+	TTH   *encoding.Base32Value
+	IsDir bool
+	// Incomplete is true for directories whose contents are not part of
+	// the list.
+	Incomplete bool
+}
+
+// Decoder reads the entries of a file list one by one, so that lists do not
+// have to fit into memory.
+type Decoder struct {
+	CID       *encoding.Base32Value
+	Base      string
+	Generator string
+
+	d *xml.Decoder
+	// dirs contains the paths of the open directory elements, the last
+	// one is the current directory.
+	dirs []string
+	done bool
+	// err is the first error returned by Next.
+	err error
+}
+
+// NewDecoder creates a Decoder reading the file list from r and reads the
+// attributes of the list. bzip2 compressed lists are detected and
+// decompressed.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(3); bytes.Equal(magic, []byte("BZh")) {
+		r = bzip2.NewReader(br)
+	} else {
+		r = br
+	}
+
+	dec := &Decoder{d: xml.NewDecoder(r)}
+
+	for {
+		tok, err := dec.d.Token()
+		if err == io.EOF {
+			return nil, ErrNoFileListing
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "FileListing" {
+			return nil, ErrNoFileListing
+		}
+
+		dec.Base = attr(start, "Base")
+		if dec.Base == "" {
+			dec.Base = "/"
+		}
+		if !validBase(dec.Base) {
+			return nil, ErrInvalidBase
+		}
+		dec.Generator = attr(start, "Generator")
+		if cid := attr(start, "CID"); cid != "" {
+			if dec.CID, err = encoding.ParseBase32Value(cid); err != nil {
+				return nil, err
+			}
+		}
+		dec.dirs = []string{dec.Base}
+
+		return dec, nil
+	}
+}
+
+// Next returns the next entry of the list. Directories are returned before
+// their contents. io.EOF is returned at the end of the list. Once Next has
+// returned an error, all further calls return the same error, as the
+// position in the directory tree is unknown after an invalid entry.
+func (d *Decoder) Next() (*Entry, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	e, err := d.next()
+	if err != nil {
+		d.err = err
+	}
+
+	return e, err
+}
+
+func (d *Decoder) next() (*Entry, error) {
+	for !d.done {
+		tok, err := d.d.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e, err := d.start(tok)
+			if e != nil || err != nil {
+				return e, err
+			}
+		case xml.EndElement:
+			if tok.Name.Local == "Directory" {
+				d.dirs = d.dirs[:len(d.dirs)-1]
+			} else if tok.Name.Local == "FileListing" {
+				d.done = true
+			}
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// start returns the entry of the element el. Unknown elements are skipped,
+// nil is returned for them.
+func (d *Decoder) start(el xml.StartElement) (*Entry, error) {
+	if el.Name.Local != "Directory" && el.Name.Local != "File" {
+		return nil, d.d.Skip()
+	}
+
+	dir := d.dirs[len(d.dirs)-1]
+	name := attr(el, "Name")
+	if name == "" || strings.Contains(name, "/") || name == "." || name == ".." {
+		return nil, ErrInvalidEntry
+	}
+
+	if el.Name.Local == "Directory" {
+		e := &Entry{
+			Path:       dir + name + "/",
+			IsDir:      true,
+			Incomplete: attr(el, "Incomplete") == "1",
+		}
+		d.dirs = append(d.dirs, e.Path)
+		return e, nil
+	}
+
+	size, err := strconv.ParseInt(attr(el, "Size"), 10, 64)
+	if err != nil || size < 0 {
+		return nil, ErrInvalidEntry
+	}
+	e := &Entry{Path: dir + name, Size: size}
+	if tth := attr(el, "TTH"); tth != "" {
+		if e.TTH, err = encoding.ParseBase32Value(tth); err != nil {
+			return nil, ErrInvalidEntry
+		}
+	}
+
+	// File elements have no contents, but are not required to be empty.
+	if err := d.d.Skip(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Parse reads the file list from r into a tree.
+func Parse(r io.Reader) (*Listing, error) {
+	dec, err := NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listing{
+		CID:       dec.CID,
+		Base:      dec.Base,
+		Generator: dec.Generator,
+		Root:      &Directory{},
+	}
+	dirs := map[string]*Directory{dec.Base: l.Root}
+
+	for {
+		e, err := dec.Next()
+		if err == io.EOF {
+			return l, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		parentPath, name := splitEntry(e.Path)
+		parent := dirs[parentPath]
+
+		if e.IsDir {
+			dir := &Directory{Name: name, Incomplete: e.Incomplete}
+			parent.Dirs = append(parent.Dirs, dir)
+			dirs[e.Path] = dir
+		} else {
+			parent.Files = append(parent.Files, &File{Name: name, Size: e.Size, TTH: e.TTH})
+		}
+	}
+}
+
+// splitEntry splits the path of an entry into the path of its directory and
+// its name.
+func splitEntry(p string) (dir, name string) {
+	trimmed := strings.TrimSuffix(p, "/")
+	i := strings.LastIndex(trimmed, "/")
+
+	return trimmed[:i+1], trimmed[i+1:]
+}
+
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,156 @@
+package filelist
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/seoester/adcl/internal/bzip2"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/share"
+)
+
+// Options contains the parameters of a written file list.
+type Options struct {
+	// CID is the CID of the client sharing the files.
+	CID *encoding.Base32Value
+	// Base is the directory the list starts at, it must start and end with
+	// a slash. It defaults to "/".
+	Base string
+	// Recursive includes the contents of all directories below Base. If it
+	// is false, only the files directly in Base are included and its
+	// subdirectories are marked as incomplete.
+	Recursive bool
+	// Generator identifies the software that generated the list, it
+	// defaults to Generator.
+	Generator string
+}
+
+// WriteBZ2 writes the bzip2 compressed file list of files to w, as served as
+// files.xml.bz2.
+func WriteBZ2(w io.Writer, files []share.File, opts Options) error {
+	z := bzip2.NewWriter(w)
+	if err := Write(z, files, opts); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+// Write writes the file list of files to w. files must be sorted by their
+// path, as returned by Share.Files. The list is written as it is generated,
+// so only the current path is kept in memory.
+func Write(w io.Writer, files []share.File, opts Options) error {
+	if opts.Base == "" {
+		opts.Base = "/"
+	}
+	if !validBase(opts.Base) {
+		return ErrInvalidBase
+	}
+	if opts.Generator == "" {
+		opts.Generator = Generator
+	}
+
+	lw := &listWriter{w: bufio.NewWriter(w)}
+
+	lw.str(`<?xml version="1.0" encoding="utf-8" standalone="yes"?>` + "\n")
+	lw.str(`<FileListing Version="` + Version + `"`)
+	if opts.CID != nil {
+		lw.attr("CID", opts.CID.String())
+	}
+	lw.attr("Base", opts.Base)
+	lw.attr("Generator", opts.Generator)
+	lw.str(">\n")
+
+	// open contains the names of the directories whose elements are open.
+	var open []string
+	// incomplete is the name of the last subdirectory marked as
+	// incomplete.
+	var incomplete string
+
+	for i := range files {
+		f := &files[i]
+		if !strings.HasPrefix(f.Path, opts.Base) {
+			continue
+		}
+
+		names := splitPath(strings.TrimPrefix(f.Path, opts.Base))
+		dirs, name := names[:len(names)-1], names[len(names)-1]
+
+		if !opts.Recursive && len(dirs) > 0 {
+			if dirs[0] != incomplete {
+				incomplete = dirs[0]
+				lw.indent(0)
+				lw.str("<Directory")
+				lw.attr("Name", incomplete)
+				lw.str(` Incomplete="1"/>` + "\n")
+			}
+			continue
+		}
+
+		common := 0
+		for common < len(open) && common < len(dirs) && open[common] == dirs[common] {
+			common++
+		}
+		for len(open) > common {
+			open = open[:len(open)-1]
+			lw.indent(len(open))
+			lw.str("</Directory>\n")
+		}
+		for _, dir := range dirs[common:] {
+			lw.indent(len(open))
+			lw.str("<Directory")
+			lw.attr("Name", dir)
+			lw.str(">\n")
+			open = append(open, dir)
+		}
+
+		lw.indent(len(open))
+		lw.str("<File")
+		lw.attr("Name", name)
+		lw.attr("Size", strconv.FormatInt(f.Size, 10))
+		if f.TTH != nil {
+			lw.attr("TTH", f.TTH.String())
+		}
+		lw.str("/>\n")
+	}
+
+	for len(open) > 0 {
+		open = open[:len(open)-1]
+		lw.indent(len(open))
+		lw.str("</Directory>\n")
+	}
+	lw.str("</FileListing>\n")
+
+	if lw.err != nil {
+		return lw.err
+	}
+	return lw.w.Flush()
+}
+
+// listWriter writes the elements of a file list, keeping the first error.
+type listWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (l *listWriter) str(s string) {
+	if l.err == nil {
+		_, l.err = l.w.WriteString(s)
+	}
+}
+
+func (l *listWriter) attr(name, value string) {
+	l.str(" " + name + `="`)
+	if l.err == nil {
+		l.err = xml.EscapeText(l.w, []byte(value))
+	}
+	l.str(`"`)
+}
+
+// indent indents an element at depth below FileListing.
+func (l *listWriter) indent(depth int) {
+	l.str(strings.Repeat("\t", depth+1))
+}
@@ -0,0 +1,343 @@
+// Package hub implements the hub side of ADC. A Hub accepts client
+// connections, drives them through the states defined by BASE (PROTOCOL,
+// IDENTIFY, VERIFY and NORMAL) and routes messages between the clients in the
+// NORMAL state.
+//
+// Usage:
+//
+//	h := hub.New(hub.Config{Name: "My Hub"})
+//	defer h.Close()
+//
+//	if err := h.ListenAndServe(":1511"); err != hub.ErrHubClosed {
+//	    ...
+//	}
+//
+// Every connection is served by a goroutine reading messages from the client
+// and a goroutine writing messages to the client. Messages to a client are
+// queued, clients which do not keep up with reading are disconnected.
+package hub
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/writer"
+)
+
+// Error variables related to Hub.
+var (
+	ErrHubClosed = errors.New("hub has been closed")
+	ErrHubFull   = errors.New("no SID is available")
+)
+
+// Constants related to Hub.
+const (
+	// DefaultQueueLength is the default length of the queue of messages
+	// waiting to be written to a client.
+	DefaultQueueLength int = 256
+)
+
+// Features which are always announced in the SUP sent by the hub.
+var defaultFeatures = []string{"BASE", "TIGR"}
+
+// Authenticator decides whether a client has to enter the VERIFY state and
+// checks the password sent by the client.
+type Authenticator interface {
+	// Challenge is called with the INF sent by a client in the IDENTIFY
+	// state. If required is true, the client enters the VERIFY state and data
+	// is sent to the client in GPA. Otherwise, the client enters the NORMAL
+	// state directly. If err is not nil, the client is disconnected.
+	Challenge(info *message.INFContent) (data *encoding.Base32Value, required bool, err error)
+	// Verify returns whether response, the password sent by the client in
+	// PAS, is valid for the random data previously returned by Challenge.
+	Verify(info *message.INFContent, data, response *encoding.Base32Value) bool
+}
+
+// ClientTyper may be implemented by an Authenticator to assign client types,
+// e.g. message.ClientTypeOperator, to users who have sent a valid password.
+// Such users are always assigned message.ClientTypeRegistered. The CT field
+// sent by clients is ignored.
+type ClientTyper interface {
+	ClientType(info *message.INFContent) message.ClientType
+}
+
+// Config contains the parameters of a Hub.
+type Config struct {
+	// Name and Description are sent to clients in the hub's INF.
+	Name        string
+	Description string
+
+	// Features are announced in addition to BASE and TIGR in the SUP sent to
+	// clients.
+	Features []string
+
+	// Authenticator is consulted when a client sends its INF in the IDENTIFY
+	// state. If Authenticator is nil, no passwords are requested.
+	Authenticator Authenticator
+
+	// QueueLength is the number of messages queued for a client before the
+	// client is disconnected. If QueueLength is zero, DefaultQueueLength is
+	// used.
+	QueueLength int
+	// FlushPolicy is the flush policy of the connection writers.
+	FlushPolicy writer.FlushPolicy
+}
+
+// Hub is an ADC hub. All methods of Hub are safe for concurrent use.
+type Hub struct {
+	config Config
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	// sessions contains all connected sessions keyed by their SID,
+	// regardless of their state.
+	sessions map[string]*session
+	// users contains the users of sessions in the NORMAL state.
+	users   Registry
+	nextSID uint32
+}
+
+// New creates a new Hub. No connections are accepted until Serve,
+// ListenAndServe or ServeConn is called.
+func New(config Config) *Hub {
+	if config.QueueLength == 0 {
+		config.QueueLength = DefaultQueueLength
+	}
+
+	return &Hub{
+		config:    config,
+		listeners: make(map[net.Listener]struct{}),
+		sessions:  make(map[string]*session),
+	}
+}
+
+// ListenAndServe listens on the TCP network address addr and serves
+// incoming connections. See Serve.
+func (h *Hub) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return h.Serve(l)
+}
+
+// Serve accepts connections on l and serves each one on a new goroutine. l
+// is closed when Serve returns. After Close has been called, ErrHubClosed is
+// returned.
+func (h *Hub) Serve(l net.Listener) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		l.Close()
+		return ErrHubClosed
+	}
+	h.listeners[l] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.listeners, l)
+		h.mu.Unlock()
+
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if h.isClosed() {
+				return ErrHubClosed
+			}
+			return err
+		}
+
+		go h.ServeConn(conn)
+	}
+}
+
+// ServeConn serves a single client connection and returns when the
+// connection has been closed. conn is closed when ServeConn returns.
+func (h *Hub) ServeConn(conn net.Conn) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.Close()
+		return ErrHubClosed
+	}
+
+	sid, err := h.allocateSID()
+	if err != nil {
+		h.mu.Unlock()
+		conn.Close()
+		return err
+	}
+
+	s := newSession(h, conn, sid)
+	h.sessions[sid.String()] = s
+	h.wg.Add(1)
+	h.mu.Unlock()
+
+	defer h.wg.Done()
+
+	return s.run()
+}
+
+// Close stops accepting connections and disconnects all clients. Close waits
+// until all connections have been closed.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHubClosed
+	}
+	h.closed = true
+
+	for l := range h.listeners {
+		l.Close()
+	}
+	for _, s := range h.sessions {
+		s.conn.Close()
+	}
+	h.mu.Unlock()
+
+	h.wg.Wait()
+
+	return nil
+}
+
+// Users returns all users in the NORMAL state ordered by SID.
+func (h *Hub) Users() []User {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	registered := h.users.Users()
+	users := make([]User, len(registered))
+	for i, u := range registered {
+		users[i] = *u
+		users[i].session = nil
+	}
+
+	return users
+}
+
+// User returns the user in the NORMAL state with the passed in SID.
+func (h *Hub) User(sid *encoding.Base32Value) (User, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	u, ok := h.users.BySID(sid)
+	if !ok {
+		return User{}, false
+	}
+
+	user := *u
+	user.session = nil
+
+	return user, true
+}
+
+func (h *Hub) isClosed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.closed
+}
+
+// Constants related to SID allocation.
+const (
+	sidAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	// sidCount is the number of distinct SIDs, SIDs consist of four base32
+	// characters.
+	sidCount = 32 * 32 * 32 * 32
+)
+
+// allocateSID returns a SID not used by any session. h.mu must be held.
+func (h *Hub) allocateSID() (*encoding.Base32Value, error) {
+	if len(h.sessions) >= sidCount {
+		return nil, ErrHubFull
+	}
+
+	for {
+		n := h.nextSID
+		h.nextSID = (h.nextSID + 1) % sidCount
+
+		str := string([]byte{
+			sidAlphabet[n>>15&31],
+			sidAlphabet[n>>10&31],
+			sidAlphabet[n>>5&31],
+			sidAlphabet[n&31],
+		})
+		if _, ok := h.sessions[str]; ok {
+			continue
+		}
+
+		return encoding.ParseBase32Value(str)
+	}
+}
+
+// info returns the hub's own INF (IINF). An error is returned if the name or
+// the description is not a valid UTF-8 string.
+func (h *Hub) info() (*message.INFContent, error) {
+	info := &message.INFContent{}
+	info.SetClientType(message.ClientTypeHub)
+
+	cons := message.INFContentConstructor{Content: info}
+	cons.SetVE("adcl", string(message.INFFlagVE)+"adcl")
+
+	fields := []struct {
+		flag  message.INFFlag
+		value string
+		set   func(string, string)
+	}{
+		{message.INFFlagNI, h.config.Name, cons.SetNI},
+		{message.INFFlagDE, h.config.Description, cons.SetDE},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+
+		raw, err := encoding.EncodeToADCString(f.value)
+		if err != nil {
+			return nil, err
+		}
+		f.set(f.value, string(f.flag)+raw)
+	}
+
+	return info, nil
+}
+
+// enqueue queues line for all users in the NORMAL state. h.mu must be held.
+func (h *Hub) enqueue(line []byte) {
+	for _, u := range h.users.bySID {
+		u.session.enqueue(line)
+	}
+}
+
+// infMessage returns the BINF message of u, which is broadcast to other users.
+func infMessage(u *User) *message.Message {
+	return &message.Message{
+		Type:         message.TypeBroadcast,
+		Command:      message.CommandINF,
+		HeaderFields: message.BroadcastHeaderFields{MySID: u.SID},
+		Content:      u.Info,
+	}
+}
+
+// formatMessage returns the line of mes without the concluding end-of-line
+// character.
+func formatMessage(mes *message.Message) ([]byte, error) {
+	var m writer.MessageWriter
+
+	if err := writer.WriteMessage(&m, mes); err != nil {
+		return nil, err
+	}
+
+	return m.Bytes(), nil
+}
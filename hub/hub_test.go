@@ -0,0 +1,563 @@
+package hub_test
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/seoester/adcl/client"
+	. "github.com/seoester/adcl/hub"
+	"github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testIdentity returns a distinct PID and the matching CID for each n.
+func testIdentity(n byte) (pid, cid *encoding.Base32Value) {
+	id, err := identity.New(encoding.NewBase32Value(bytes.Repeat([]byte{n, 'P'}, 12)))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+	return id.PID, id.CID
+}
+
+// readEventsUntil reads events from c until an event satisfying stop is read,
+// it returns all events read.
+func readEventsUntil(c *client.Client, stop func(client.Event) bool) (events []client.Event) {
+	for {
+		ev, err := c.ReadEvent()
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+		events = append(events, ev)
+		if stop(ev) {
+			return
+		}
+	}
+}
+
+func isNormalState(ev client.Event) bool {
+	stateEv, ok := ev.(client.StateEvent)
+	return ok && stateEv.To == message.StateNormal
+}
+
+// readMessage reads the next event from c, which must be a MessageEvent.
+func readMessage(c *client.Client) *message.Message {
+	ev, err := c.ReadEvent()
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	ExpectWithOffset(1, ev).Should(BeAssignableToTypeOf(client.MessageEvent{}))
+
+	return ev.(client.MessageEvent).Message
+}
+
+// readStatus reads events from c until a StatusEvent is read.
+func readStatus(c *client.Client) *message.STAContent {
+	for {
+		ev, err := c.ReadEvent()
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+		if statusEv, ok := ev.(client.StatusEvent); ok {
+			return statusEv.Status
+		}
+	}
+}
+
+func msg(typ message.Type, fields message.HeaderFields, text string) *message.Message {
+	return &message.Message{
+		Type:         typ,
+		Command:      message.CommandMSG,
+		HeaderFields: fields,
+		Content:      &message.MSGContent{Text: text},
+	}
+}
+
+// passwordAuthenticator requires all users to send the password "secret",
+// encoded in base32 and prefixed with the challenge data.
+type passwordAuthenticator struct{}
+
+func (passwordAuthenticator) Challenge(info *message.INFContent) (*encoding.Base32Value, bool, error) {
+	return encoding.NewBase32Value([]byte("12345")), true, nil
+}
+
+func (passwordAuthenticator) Verify(info *message.INFContent, data, response *encoding.Base32Value) bool {
+	return string(response.Raw()) == string(data.Raw())+"secret"
+}
+
+// operatorAuthenticator is a passwordAuthenticator making all users
+// operators.
+type operatorAuthenticator struct {
+	passwordAuthenticator
+}
+
+func (operatorAuthenticator) ClientType(info *message.INFContent) message.ClientType {
+	return message.ClientTypeOperator | message.ClientTypeHub
+}
+
+func passwordFunc(password string) func(*encoding.Base32Value) (*encoding.Base32Value, error) {
+	return func(data *encoding.Base32Value) (*encoding.Base32Value, error) {
+		return encoding.NewBase32Value(append(data.Raw(), password...)), nil
+	}
+}
+
+var _ = Describe("Hub", func() {
+	var (
+		config    Config
+		h         *Hub
+		addr      string
+		serveDone chan error
+		conns     []net.Conn
+	)
+
+	BeforeEach(func() {
+		config = Config{Name: "Test Hub"}
+		conns = nil
+	})
+
+	JustBeforeEach(func() {
+		h = New(config)
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		addr = l.Addr().String()
+
+		serveDone = make(chan error, 1)
+		go func() {
+			serveDone <- h.Serve(l)
+		}()
+	})
+
+	AfterEach(func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+
+		h.Close()
+		Eventually(serveDone).Should(Receive(Equal(ErrHubClosed)))
+	})
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", addr)
+		ExpectWithOffset(2, err).ShouldNot(HaveOccurred())
+		// Fail instead of blocking forever if an expected message is not
+		// sent by the hub.
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		conns = append(conns, conn)
+
+		return conn
+	}
+
+	start := func(n byte, nick string, modify func(*client.Config)) *client.Client {
+		pid, cid := testIdentity(n)
+		cfg := client.Config{PID: pid, CID: cid}
+		cfg.INF.NI.Set(nick)
+		if modify != nil {
+			modify(&cfg)
+		}
+
+		c := client.New(dial(), cfg)
+		ExpectWithOffset(1, c.Start()).Should(Succeed())
+
+		return c
+	}
+
+	join := func(n byte, nick string, modify func(*client.Config)) *client.Client {
+		c := start(n, nick, modify)
+		readEventsUntil(c, isNormalState)
+
+		return c
+	}
+
+	It("should bring clients into the NORMAL state", func() {
+		c := start(1, "alice", nil)
+
+		events := readEventsUntil(c, isNormalState)
+		Ω(events).Should(HaveLen(4))
+		Ω(events[1].(client.HubInfoEvent).Info.NI.Value).Should(Equal("Test Hub"))
+
+		own := events[2].(client.UserInfoEvent)
+		Ω(own.Own).Should(BeTrue())
+		Ω(own.Info.NI.Value).Should(Equal("alice"))
+		Ω(own.Info.PD.IsSet).Should(BeFalse())
+
+		Ω(c.HubFeatures().Has("BASE")).Should(BeTrue())
+
+		users := h.Users()
+		Ω(users).Should(HaveLen(1))
+		Ω(users[0].Nick).Should(Equal("alice"))
+		Ω(users[0].SID.String()).Should(Equal(c.SID().String()))
+		Ω(users[0].Info.PD.IsSet).Should(BeFalse())
+	})
+
+	It("should exchange INFs between users", func() {
+		alice := join(1, "alice", nil)
+
+		bob := start(2, "bob", nil)
+		events := readEventsUntil(bob, isNormalState)
+
+		var nicks []string
+		for _, ev := range events {
+			if infoEv, ok := ev.(client.UserInfoEvent); ok {
+				nicks = append(nicks, infoEv.Info.NI.Value)
+			}
+		}
+		Ω(nicks).Should(Equal([]string{"alice", "bob"}))
+
+		ev, err := alice.ReadEvent()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ev.(client.UserInfoEvent).Info.NI.Value).Should(Equal("bob"))
+		Ω(ev.(client.UserInfoEvent).Info.PD.IsSet).Should(BeFalse())
+	})
+
+	It("should route B, D and E messages", func() {
+		alice := join(1, "alice", nil)
+		bob := join(2, "bob", nil)
+		carol := join(3, "carol", nil)
+		readEventsUntil(alice, func(ev client.Event) bool {
+			infoEv, ok := ev.(client.UserInfoEvent)
+			return ok && infoEv.Info.NI.Value == "carol"
+		})
+		readEventsUntil(bob, func(ev client.Event) bool {
+			_, ok := ev.(client.UserInfoEvent)
+			return ok
+		})
+
+		Ω(alice.Send(msg(message.TypeDirectmessage, message.DirectHeaderFields{
+			MySID:     alice.SID(),
+			TargetSID: bob.SID(),
+		}, "direct"))).Should(Succeed())
+		Ω(alice.Send(msg(message.TypeEchomessage, message.EchoHeaderFields{
+			MySID:     alice.SID(),
+			TargetSID: carol.SID(),
+		}, "echo"))).Should(Succeed())
+		Ω(alice.Send(msg(message.TypeBroadcast, message.BroadcastHeaderFields{
+			MySID: alice.SID(),
+		}, "broadcast"))).Should(Succeed())
+
+		text := func(mes *message.Message) string {
+			return mes.Content.(*message.MSGContent).Text
+		}
+
+		Ω(text(readMessage(alice))).Should(Equal("echo"))
+		Ω(text(readMessage(alice))).Should(Equal("broadcast"))
+		Ω(text(readMessage(bob))).Should(Equal("direct"))
+		Ω(text(readMessage(bob))).Should(Equal("broadcast"))
+		Ω(text(readMessage(carol))).Should(Equal("echo"))
+		Ω(text(readMessage(carol))).Should(Equal("broadcast"))
+	})
+
+	It("should route F messages to users with matching features", func() {
+		alice := join(1, "alice", nil)
+		bob := join(2, "bob", func(cfg *client.Config) {
+			cfg.INF.SU = []string{"TCP4", "UDP4"}
+		})
+		readEventsUntil(alice, func(ev client.Event) bool {
+			_, ok := ev.(client.UserInfoEvent)
+			return ok
+		})
+
+		Ω(alice.Send(msg(message.TypeFeaturebroadcast, message.FeatureHeaderFields{
+			MySID: alice.SID(),
+			Features: []message.FeatureOp{
+				{OpAction: message.FeatureOpAdd, Feature: "TCP4"},
+			},
+		}, "feature"))).Should(Succeed())
+		Ω(alice.Send(msg(message.TypeBroadcast, message.BroadcastHeaderFields{
+			MySID: alice.SID(),
+		}, "broadcast"))).Should(Succeed())
+
+		Ω(readMessage(alice).Type).Should(Equal(message.TypeBroadcast))
+		Ω(readMessage(bob).Type).Should(Equal(message.TypeFeaturebroadcast))
+		Ω(readMessage(bob).Type).Should(Equal(message.TypeBroadcast))
+	})
+
+	It("should apply and forward INF updates", func() {
+		alice := join(1, "alice", func(cfg *client.Config) {
+			cfg.INF.DE.Set("description")
+		})
+		bob := join(2, "bob", nil)
+		readEventsUntil(alice, func(ev client.Event) bool {
+			_, ok := ev.(client.UserInfoEvent)
+			return ok
+		})
+
+		update := &message.INFContent{}
+		update.NI.Set("alice2")
+		Ω(alice.Send(&message.Message{
+			Type:         message.TypeBroadcast,
+			Command:      message.CommandINF,
+			HeaderFields: message.BroadcastHeaderFields{MySID: alice.SID()},
+			Content:      update,
+		})).Should(Succeed())
+
+		ev, err := bob.ReadEvent()
+		Ω(err).ShouldNot(HaveOccurred())
+		info := ev.(client.UserInfoEvent).Info
+		Ω(info.NI.Value).Should(Equal("alice2"))
+		Ω(info.DE.IsSet).Should(BeFalse())
+
+		u, ok := h.User(alice.SID())
+		Ω(ok).Should(BeTrue())
+		Ω(u.Nick).Should(Equal("alice2"))
+		Ω(u.Info.NI.Value).Should(Equal("alice2"))
+		Ω(u.Info.DE.Value).Should(Equal("description"))
+
+		update.NI.Set("bob")
+		Ω(alice.Send(&message.Message{
+			Type:         message.TypeBroadcast,
+			Command:      message.CommandINF,
+			HeaderFields: message.BroadcastHeaderFields{MySID: alice.SID()},
+			Content:      update,
+		})).Should(Succeed())
+
+		status := readStatus(alice)
+		Ω(status.Code.String()).Should(Equal("122"))
+	})
+
+	It("should ignore client types claimed by clients", func() {
+		alice := join(1, "alice", func(cfg *client.Config) {
+			cfg.INF.SetClientType(message.ClientTypeOperator | message.ClientTypeHubOwner)
+		})
+		bob := join(2, "bob", nil)
+		readEventsUntil(alice, func(ev client.Event) bool {
+			_, ok := ev.(client.UserInfoEvent)
+			return ok
+		})
+
+		u, ok := h.User(alice.SID())
+		Ω(ok).Should(BeTrue())
+		Ω(u.Info.CT.IsSet).Should(BeFalse())
+
+		update := &message.INFContent{}
+		update.DE.Set("op")
+		update.SetClientType(message.ClientTypeHub)
+		Ω(alice.Send(&message.Message{
+			Type:         message.TypeBroadcast,
+			Command:      message.CommandINF,
+			HeaderFields: message.BroadcastHeaderFields{MySID: alice.SID()},
+			Content:      update,
+		})).Should(Succeed())
+
+		ev, err := bob.ReadEvent()
+		Ω(err).ShouldNot(HaveOccurred())
+		info := ev.(client.UserInfoEvent).Info
+		Ω(info.DE.Value).Should(Equal("op"))
+		Ω(info.CT.IsSet).Should(BeFalse())
+
+		u, _ = h.User(alice.SID())
+		Ω(u.Info.CT.IsSet).Should(BeFalse())
+	})
+
+	It("should reject taken nicks", func() {
+		join(1, "alice", nil)
+		c := start(2, "alice", nil)
+
+		status := readStatus(c)
+		Ω(status.Code.String()).Should(Equal("222"))
+
+		_, err := c.ReadEvent()
+		Ω(err).Should(HaveOccurred())
+		Ω(h.Users()).Should(HaveLen(1))
+	})
+
+	It("should validate INFs", func() {
+		c := start(1, "bad nick", nil)
+
+		status := readStatus(c)
+		Ω(status.Code.String()).Should(Equal("221"))
+		Ω(h.Users()).Should(BeEmpty())
+
+		alice := join(2, "alice", nil)
+
+		sendUpdate := func(update *message.INFContent) {
+			ExpectWithOffset(1, alice.Send(&message.Message{
+				Type:         message.TypeBroadcast,
+				Command:      message.CommandINF,
+				HeaderFields: message.BroadcastHeaderFields{MySID: alice.SID()},
+				Content:      update,
+			})).Should(Succeed())
+		}
+
+		update := &message.INFContent{}
+		update.AW.Set(5)
+		sendUpdate(update)
+
+		status = readStatus(alice)
+		Ω(status.Code.String()).Should(Equal("143"))
+		Ω(status.Flags).Should(HaveKeyWithValue("FB", "AW"))
+
+		sendUpdate(&message.INFContent{Flags: map[string]string{"NI": ""}})
+
+		status = readStatus(alice)
+		Ω(status.Code.String()).Should(Equal("143"))
+		Ω(status.Flags).Should(HaveKeyWithValue("FM", "NI"))
+
+		u, ok := h.User(alice.SID())
+		Ω(ok).Should(BeTrue())
+		Ω(u.Info.AW.IsSet).Should(BeFalse())
+		Ω(u.Nick).Should(Equal("alice"))
+	})
+
+	It("should replace zero addresses and reject foreign ones", func() {
+		alice := join(1, "alice", func(cfg *client.Config) {
+			cfg.INF.I4.Set(net.IPv4zero)
+			cfg.INF.I6.Set(net.IPv6zero)
+		})
+
+		u, ok := h.User(alice.SID())
+		Ω(ok).Should(BeTrue())
+		Ω(u.Info.I4.Value.String()).Should(Equal("127.0.0.1"))
+		Ω(u.Info.I6.IsSet).Should(BeFalse())
+
+		update := &message.INFContent{}
+		update.I4.Set(net.IPv4(10, 0, 0, 2))
+		Ω(alice.Send(&message.Message{
+			Type:         message.TypeBroadcast,
+			Command:      message.CommandINF,
+			HeaderFields: message.BroadcastHeaderFields{MySID: alice.SID()},
+			Content:      update,
+		})).Should(Succeed())
+
+		status := readStatus(alice)
+		Ω(status.Code.String()).Should(Equal("146"))
+		Ω(status.Flags).Should(HaveKeyWithValue("I4", "127.0.0.1"))
+
+		c := start(2, "bob", func(cfg *client.Config) {
+			cfg.INF.I4.Set(net.IPv4(10, 0, 0, 1))
+		})
+
+		status = readStatus(c)
+		Ω(status.Code.String()).Should(Equal("246"))
+		Ω(h.Users()).Should(HaveLen(1))
+	})
+
+	It("should reject CIDs not matching the PID", func() {
+		c := start(1, "alice", func(cfg *client.Config) {
+			_, cfg.CID = testIdentity(2)
+		})
+
+		status := readStatus(c)
+		Ω(status.Code.String()).Should(Equal("227"))
+		Ω(h.Users()).Should(BeEmpty())
+	})
+
+	It("should announce departing users", func() {
+		alice := join(1, "alice", nil)
+		join(2, "bob", nil)
+		ev, err := alice.ReadEvent()
+		Ω(err).ShouldNot(HaveOccurred())
+		bobSID := ev.(client.UserInfoEvent).SID
+
+		conns[1].Close()
+
+		ev, err = alice.ReadEvent()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ev).Should(BeAssignableToTypeOf(client.QuitEvent{}))
+		Ω(ev.(client.QuitEvent).SID.String()).Should(Equal(bobSID.String()))
+		Ω(h.Users()).Should(HaveLen(1))
+	})
+
+	It("should disconnect clients violating the protocol", func() {
+		conn := dial()
+		_, err := conn.Write([]byte("BINF AAAA NIalice\n"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		p := parser.New(bufio.NewReader(conn))
+		mes, err := p.ReadMessage()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Command).Should(Equal(message.CommandSTA))
+		Ω(mes.Content.(*message.STAContent).Code.String()).Should(Equal("244"))
+
+		_, err = p.ReadMessage()
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should enforce the maximum message length", func() {
+		conn := dial()
+		line := "HMSG " + strings.Repeat("a", parser.MaxMessageLength) + "\nHSUP ADBASE\n"
+		_, err := conn.Write([]byte(line))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		p := parser.New(bufio.NewReader(conn))
+		mes, err := p.ReadMessage()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content.(*message.STAContent).Code.String()).Should(Equal("140"))
+
+		mes, err = p.ReadMessage()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Command).Should(Equal(message.CommandSUP))
+	})
+
+	It("should disconnect all clients when closed", func() {
+		c := join(1, "alice", nil)
+
+		Ω(h.Close()).Should(Succeed())
+
+		_, err := c.ReadEvent()
+		Ω(err).Should(HaveOccurred())
+		Ω(h.Users()).Should(BeEmpty())
+	})
+
+	Context("with an Authenticator", func() {
+		BeforeEach(func() {
+			config.Authenticator = passwordAuthenticator{}
+		})
+
+		It("should accept valid passwords", func() {
+			c := start(1, "alice", func(cfg *client.Config) {
+				cfg.Password = passwordFunc("secret")
+			})
+
+			events := readEventsUntil(c, isNormalState)
+			Ω(events).Should(ContainElement(client.StateEvent{
+				From: message.StateIdentify,
+				To:   message.StateVerify,
+			}))
+		})
+
+		It("should reject invalid passwords", func() {
+			c := start(1, "alice", func(cfg *client.Config) {
+				cfg.Password = passwordFunc("guess")
+			})
+
+			status := readStatus(c)
+			Ω(status.Code.String()).Should(Equal("223"))
+			Ω(h.Users()).Should(BeEmpty())
+		})
+
+		It("should mark users who sent a valid password as registered", func() {
+			c := join(1, "alice", func(cfg *client.Config) {
+				cfg.Password = passwordFunc("secret")
+				cfg.INF.SetClientType(message.ClientTypeHubOwner)
+			})
+
+			u, ok := h.User(c.SID())
+			Ω(ok).Should(BeTrue())
+			Ω(u.Info.ClientType()).Should(Equal(message.ClientTypeRegistered))
+		})
+	})
+
+	Context("with an Authenticator assigning client types", func() {
+		BeforeEach(func() {
+			config.Authenticator = operatorAuthenticator{}
+		})
+
+		It("should assign the client types except the hub type", func() {
+			c := start(1, "alice", func(cfg *client.Config) {
+				cfg.Password = passwordFunc("secret")
+			})
+
+			var own *message.INFContent
+			for _, ev := range readEventsUntil(c, isNormalState) {
+				if infoEv, ok := ev.(client.UserInfoEvent); ok && infoEv.Own {
+					own = infoEv.Info
+				}
+			}
+			Ω(own).ShouldNot(BeNil())
+			Ω(own.ClientType()).Should(Equal(message.ClientTypeRegistered | message.ClientTypeOperator))
+		})
+	})
+})
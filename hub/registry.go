@@ -0,0 +1,150 @@
+package hub
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Error variables related to Registry.
+var (
+	ErrSIDTaken  = errors.New("SID is already in use")
+	ErrCIDTaken  = errors.New("CID is already in use")
+	ErrNickTaken = errors.New("nick is already in use")
+)
+
+// User is a client which has completed the handshake, i.e. is in the NORMAL
+// state.
+//
+// Users obtained from a Hub are copies, modifying them has no effect. Info
+// and Features are shared with the hub and must not be modified.
+type User struct {
+	SID  *encoding.Base32Value
+	CID  *encoding.Base32Value
+	Nick string
+
+	// Info is the INF of the user, accumulated from all INF messages sent by
+	// the user. PD is never set.
+	Info *message.INFContent
+	// Features are the features announced in the SU field of Info. They are
+	// used to route F messages.
+	Features message.FeatureSet
+
+	session *session
+	// infLine is the formatted BINF message of Info, which is sent to users
+	// entering the NORMAL state.
+	infLine []byte
+}
+
+// Registry indexes users by their SID, CID and nick. SIDs, CIDs and nicks
+// are unique within a Registry.
+//
+// The zero value is an empty registry ready to use. Registry is not safe for
+// concurrent use.
+type Registry struct {
+	bySID  map[string]*User
+	byCID  map[string]*User
+	byNick map[string]*User
+}
+
+// Add adds u to the registry. ErrSIDTaken, ErrCIDTaken or ErrNickTaken is
+// returned if another user with the same SID, CID or nick is registered.
+func (r *Registry) Add(u *User) error {
+	if r.bySID == nil {
+		r.bySID = make(map[string]*User)
+		r.byCID = make(map[string]*User)
+		r.byNick = make(map[string]*User)
+	}
+
+	if _, ok := r.bySID[u.SID.String()]; ok {
+		return ErrSIDTaken
+	}
+	if _, ok := r.byCID[u.CID.String()]; ok {
+		return ErrCIDTaken
+	}
+	if _, ok := r.byNick[u.Nick]; ok {
+		return ErrNickTaken
+	}
+
+	r.bySID[u.SID.String()] = u
+	r.byCID[u.CID.String()] = u
+	r.byNick[u.Nick] = u
+
+	return nil
+}
+
+// Remove removes u from the registry. Nothing happens if u is not
+// registered.
+func (r *Registry) Remove(u *User) {
+	if r.bySID[u.SID.String()] != u {
+		return
+	}
+
+	delete(r.bySID, u.SID.String())
+	delete(r.byCID, u.CID.String())
+	delete(r.byNick, u.Nick)
+}
+
+// Rename changes the nick of the registered user u to nick. ErrNickTaken is
+// returned if another user uses nick.
+func (r *Registry) Rename(u *User, nick string) error {
+	if other, ok := r.byNick[nick]; ok {
+		if other == u {
+			return nil
+		}
+		return ErrNickTaken
+	}
+
+	delete(r.byNick, u.Nick)
+	u.Nick = nick
+	r.byNick[nick] = u
+
+	return nil
+}
+
+// BySID returns the user with the passed in SID.
+func (r *Registry) BySID(sid *encoding.Base32Value) (*User, bool) {
+	if sid == nil {
+		return nil, false
+	}
+
+	u, ok := r.bySID[sid.String()]
+	return u, ok
+}
+
+// ByCID returns the user with the passed in CID.
+func (r *Registry) ByCID(cid *encoding.Base32Value) (*User, bool) {
+	if cid == nil {
+		return nil, false
+	}
+
+	u, ok := r.byCID[cid.String()]
+	return u, ok
+}
+
+// ByNick returns the user with the passed in nick.
+func (r *Registry) ByNick(nick string) (*User, bool) {
+	u, ok := r.byNick[nick]
+	return u, ok
+}
+
+// Len returns the number of registered users.
+func (r *Registry) Len() int {
+	return len(r.bySID)
+}
+
+// Users returns all registered users ordered by SID.
+func (r *Registry) Users() []*User {
+	users := make([]*User, 0, len(r.bySID))
+	for _, u := range r.bySID {
+		users = append(users, u)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].SID.String() < users[j].SID.String()
+	})
+
+	return users
+}
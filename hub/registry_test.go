@@ -0,0 +1,92 @@
+package hub_test
+
+import (
+	. "github.com/seoester/adcl/hub"
+	"github.com/seoester/adcl/protocol/encoding"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func mustBase32(s string) *encoding.Base32Value {
+	v, err := encoding.ParseBase32Value(s)
+	Ω(err).ShouldNot(HaveOccurred())
+	return v
+}
+
+func newUser(sid, cid, nick string) *User {
+	return &User{
+		SID:  mustBase32(sid),
+		CID:  mustBase32(cid),
+		Nick: nick,
+	}
+}
+
+var _ = Describe("Registry", func() {
+	var (
+		r     Registry
+		alice *User
+	)
+
+	BeforeEach(func() {
+		r = Registry{}
+		alice = newUser("AAAA", "AAAAAAAA", "alice")
+		Ω(r.Add(alice)).Should(Succeed())
+	})
+
+	It("should look up users by SID, CID and nick", func() {
+		u, ok := r.BySID(mustBase32("AAAA"))
+		Ω(ok).Should(BeTrue())
+		Ω(u).Should(BeIdenticalTo(alice))
+
+		u, ok = r.ByCID(mustBase32("AAAAAAAA"))
+		Ω(ok).Should(BeTrue())
+		Ω(u).Should(BeIdenticalTo(alice))
+
+		u, ok = r.ByNick("alice")
+		Ω(ok).Should(BeTrue())
+		Ω(u).Should(BeIdenticalTo(alice))
+
+		_, ok = r.BySID(mustBase32("AAAB"))
+		Ω(ok).Should(BeFalse())
+		_, ok = r.BySID(nil)
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("should reject duplicate SIDs, CIDs and nicks", func() {
+		Ω(r.Add(newUser("AAAA", "BBBBBBBB", "bob"))).Should(Equal(ErrSIDTaken))
+		Ω(r.Add(newUser("AAAB", "AAAAAAAA", "bob"))).Should(Equal(ErrCIDTaken))
+		Ω(r.Add(newUser("AAAB", "BBBBBBBB", "alice"))).Should(Equal(ErrNickTaken))
+		Ω(r.Len()).Should(Equal(1))
+	})
+
+	It("should rename users", func() {
+		bob := newUser("AAAB", "BBBBBBBB", "bob")
+		Ω(r.Add(bob)).Should(Succeed())
+
+		Ω(r.Rename(bob, "alice")).Should(Equal(ErrNickTaken))
+		Ω(r.Rename(bob, "bob")).Should(Succeed())
+		Ω(r.Rename(bob, "carol")).Should(Succeed())
+
+		Ω(bob.Nick).Should(Equal("carol"))
+		_, ok := r.ByNick("bob")
+		Ω(ok).Should(BeFalse())
+		u, _ := r.ByNick("carol")
+		Ω(u).Should(BeIdenticalTo(bob))
+	})
+
+	It("should remove users", func() {
+		bob := newUser("AAAB", "BBBBBBBB", "bob")
+		Ω(r.Add(bob)).Should(Succeed())
+		Ω(r.Users()).Should(Equal([]*User{alice, bob}))
+
+		r.Remove(alice)
+		// Users which are not registered are ignored.
+		r.Remove(newUser("AAAB", "CCCCCCCC", "carol"))
+
+		Ω(r.Users()).Should(Equal([]*User{bob}))
+		_, ok := r.ByCID(mustBase32("AAAAAAAA"))
+		Ω(ok).Should(BeFalse())
+		Ω(r.Add(newUser("AAAC", "CCCCCCCC", "alice"))).Should(Succeed())
+	})
+})
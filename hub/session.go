@@ -0,0 +1,625 @@
+package hub
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/protocol/writer"
+)
+
+// Error variables related to client sessions. They are returned by ServeConn
+// if a client is disconnected by the hub.
+var (
+	ErrProtocolViolation = errors.New("client violated the protocol")
+	ErrBASENotSupported  = errors.New("client does not support BASE")
+	ErrMissingINFField   = errors.New("INF lacks a required field")
+	ErrInvalidINFField   = errors.New("INF contains an invalid field")
+	ErrInvalidPassword   = errors.New("client sent an invalid password")
+	ErrInvalidAddress    = errors.New("INF contains an address the client does not connect from")
+)
+
+// closeTimeout is the time granted to write queued messages to a client which
+// is being disconnected.
+const closeTimeout = 5 * time.Second
+
+// session is the connection of a single client. Messages are read and
+// handled by run, messages to the client are queued and written by
+// writeLoop.
+type session struct {
+	hub  *Hub
+	conn net.Conn
+	sid  *encoding.Base32Value
+
+	parser     *parser.Parser
+	connWriter *writer.ConnWriter
+
+	// The following fields are only accessed by run.
+	state    message.State
+	features message.FeatureSet
+	// info and challenge are set during the VERIFY state.
+	info      *message.INFContent
+	challenge *encoding.Base32Value
+	// user is set when the NORMAL state is entered. Its fields are guarded
+	// by hub.mu.
+	user *User
+
+	outMu      sync.Mutex
+	outClosed  bool
+	out        chan []byte
+	writerDone chan struct{}
+}
+
+func newSession(h *Hub, conn net.Conn, sid *encoding.Base32Value) *session {
+	return &session{
+		hub:        h,
+		conn:       conn,
+		sid:        sid,
+		parser:     parser.New(bufio.NewReader(conn)),
+		connWriter: writer.NewConnWriter(bufio.NewWriter(conn), h.config.FlushPolicy),
+		state:      message.StateProtocol,
+		features:   message.NewFeatureSet(),
+		out:        make(chan []byte, h.config.QueueLength),
+		writerDone: make(chan struct{}),
+	}
+}
+
+// run reads and handles messages until the connection fails or the client is
+// disconnected by the hub. nil is returned if the client or the hub closed
+// the connection.
+func (s *session) run() (err error) {
+	go s.writeLoop()
+	defer s.close()
+
+	var mes message.Message
+
+	for {
+		err = s.parser.ReadMessageInto(&mes)
+		if err == parser.ErrMessageTooLong {
+			s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Message too long")
+			continue
+		} else if isConnError(err) {
+			if err == io.EOF || s.hub.isClosed() {
+				return nil
+			}
+			return err
+		} else if err != nil {
+			if s.state != message.StateNormal {
+				return s.fail(message.ErrorCodeProtocolGeneric, "Invalid message", err)
+			}
+			s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Invalid message")
+			continue
+		}
+
+		if err = s.handle(&mes); err != nil {
+			return err
+		}
+	}
+}
+
+// isConnError returns whether err has been caused by the connection rather
+// than the content read from it.
+func isConnError(err error) bool {
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+
+	return err == io.EOF || err == io.ErrUnexpectedEOF || err == io.ErrClosedPipe
+}
+
+// close removes the session from the hub, announces the departure of the
+// user to all other users and closes the connection once all queued messages
+// have been written.
+func (s *session) close() {
+	h := s.hub
+
+	h.mu.Lock()
+	delete(h.sessions, s.sid.String())
+	if s.user != nil {
+		h.users.Remove(s.user)
+
+		line, err := formatMessage(&message.Message{
+			Type:         message.TypeInfomessage,
+			Command:      message.CommandQUI,
+			HeaderFields: message.InfoHeaderFields{},
+			Content:      &message.QUIContent{SID: s.sid},
+		})
+		if err == nil {
+			h.enqueue(line)
+		}
+	}
+	h.mu.Unlock()
+
+	s.outMu.Lock()
+	s.outClosed = true
+	close(s.out)
+	s.outMu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(closeTimeout))
+	<-s.writerDone
+	s.conn.Close()
+}
+
+// writeLoop writes all queued lines to the connection until the queue is
+// closed.
+func (s *session) writeLoop() {
+	defer close(s.writerDone)
+
+	failed := false
+
+	for line := range s.out {
+		if failed {
+			continue
+		}
+
+		if err := s.connWriter.WriteMessageLine(line); err != nil {
+			// Unblock run, it closes the queue.
+			s.conn.Close()
+			failed = true
+		}
+	}
+
+	if !failed {
+		s.connWriter.Close()
+	}
+}
+
+// enqueue queues line to be written to the client. If the queue is full, the
+// client is disconnected.
+func (s *session) enqueue(line []byte) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	if s.outClosed {
+		return
+	}
+
+	select {
+	case s.out <- line:
+	default:
+		// The client does not keep up with reading, run notices the closed
+		// connection and cleans up.
+		s.conn.Close()
+	}
+}
+
+// send queues mes to be written to the client.
+func (s *session) send(mes *message.Message) error {
+	line, err := formatMessage(mes)
+	if err != nil {
+		return err
+	}
+
+	s.enqueue(line)
+
+	return nil
+}
+
+// sendStatus sends an ISTA message to the client.
+func (s *session) sendStatus(severity message.Severity, code message.ErrorCode, description string) {
+	_ = s.send(&message.Message{
+		Type:         message.TypeInfomessage,
+		Command:      message.CommandSTA,
+		HeaderFields: message.InfoHeaderFields{},
+		Content: &message.STAContent{
+			Code:        message.StatusCode{Severity: severity, Error: code},
+			Description: description,
+		},
+	})
+}
+
+// sendError sends an ISTA message reporting sErr to the client.
+func (s *session) sendError(sErr *message.StatusError) {
+	cnt, err := sErr.STAContent()
+	if err != nil {
+		return
+	}
+
+	_ = s.send(&message.Message{
+		Type:         message.TypeInfomessage,
+		Command:      message.CommandSTA,
+		HeaderFields: message.InfoHeaderFields{},
+		Content:      cnt,
+	})
+}
+
+// fail sends a fatal ISTA message to the client and returns err, which
+// causes run to disconnect the client.
+func (s *session) fail(code message.ErrorCode, description string, err error) error {
+	s.sendStatus(message.SeverityFatal, code, description)
+	return err
+}
+
+func (s *session) handle(mes *message.Message) error {
+	switch s.state {
+	case message.StateProtocol:
+		return s.handleProtocol(mes)
+	case message.StateIdentify:
+		return s.handleIdentify(mes)
+	case message.StateVerify:
+		return s.handleVerify(mes)
+	default:
+		return s.handleNormal(mes)
+	}
+}
+
+func (s *session) handleProtocol(mes *message.Message) error {
+	if mes.Type != message.TypeHubmessage || mes.Command != message.CommandSUP {
+		return s.fail(message.ErrorCodeInvalidState, "Expected SUP", ErrProtocolViolation)
+	}
+
+	s.features.Apply(mes.Content.(*message.SUPContent).FeatureOps)
+	if !s.features.Has("BASE") {
+		return s.fail(message.ErrorCodeFeatureMissing, "BASE is required", ErrBASENotSupported)
+	}
+
+	sup := &message.SUPContent{}
+	for _, feature := range append(defaultFeatures, s.hub.config.Features...) {
+		sup.FeatureOps = append(sup.FeatureOps, message.FeatureOp{
+			OpAction: message.FeatureOpAdd,
+			Feature:  feature,
+		})
+	}
+
+	info, err := s.hub.info()
+	if err != nil {
+		return err
+	}
+
+	for _, mes := range []*message.Message{
+		{Type: message.TypeInfomessage, Command: message.CommandSUP, Content: sup},
+		{Type: message.TypeInfomessage, Command: message.CommandSID, Content: &message.SIDContent{SID: s.sid}},
+		{Type: message.TypeInfomessage, Command: message.CommandINF, Content: info},
+	} {
+		mes.HeaderFields = message.InfoHeaderFields{}
+		if err := s.send(mes); err != nil {
+			return err
+		}
+	}
+
+	s.state = message.StateIdentify
+
+	return nil
+}
+
+func (s *session) handleIdentify(mes *message.Message) error {
+	if mes.Type != message.TypeBroadcast || mes.Command != message.CommandINF {
+		return s.fail(message.ErrorCodeInvalidState, "Expected INF", ErrProtocolViolation)
+	}
+	if sid, ok := mes.SourceSID(); !ok || sid.String() != s.sid.String() {
+		return s.fail(message.ErrorCodeProtocolGeneric, "Invalid SID", ErrProtocolViolation)
+	}
+
+	info := mes.Content.(*message.INFContent)
+	// info is retained, the next message must not be parsed into it.
+	mes.Content = nil
+
+	switch err := identity.VerifyINF(info); err {
+	case nil:
+	case identity.ErrMissingPID, identity.ErrMissingCID:
+		return s.fail(message.ErrorCodeINFField, "ID and PD are required", err)
+	default:
+		return s.fail(message.ErrorCodeInvalidPID, "Invalid PID", err)
+	}
+	if err := message.ValidateINF(info, message.INFFlagNI); err != nil {
+		sErr := err.(*message.StatusError)
+		sErr.Code.Severity = message.SeverityFatal
+		s.sendError(sErr)
+
+		if sErr.FM.IsSet {
+			return ErrMissingINFField
+		}
+		return ErrInvalidINFField
+	}
+	if sErr := s.checkAddresses(info); sErr != nil {
+		sErr.Code.Severity = message.SeverityFatal
+		s.sendError(sErr)
+		return ErrInvalidAddress
+	}
+
+	// The PID must never be sent to other clients.
+	identity.StripPID(info)
+	stripClientType(info)
+
+	if auth := s.hub.config.Authenticator; auth != nil {
+		data, required, err := auth.Challenge(info)
+		if err != nil {
+			return s.fail(message.ErrorCodeLoginGeneric, "Login failed", err)
+		}
+
+		if required {
+			s.info = info
+			s.challenge = data
+			s.state = message.StateVerify
+
+			return s.send(&message.Message{
+				Type:         message.TypeInfomessage,
+				Command:      message.CommandGPA,
+				HeaderFields: message.InfoHeaderFields{},
+				Content:      &message.GPAContent{Data: data},
+			})
+		}
+	}
+
+	return s.join(info, 0)
+}
+
+func (s *session) handleVerify(mes *message.Message) error {
+	if mes.Type != message.TypeHubmessage || mes.Command != message.CommandPAS {
+		return s.fail(message.ErrorCodeInvalidState, "Expected PAS", ErrProtocolViolation)
+	}
+
+	password := mes.Content.(*message.PASContent).Password
+	if !s.hub.config.Authenticator.Verify(s.info, s.challenge, password) {
+		return s.fail(message.ErrorCodeInvalidPassword, "Invalid password", ErrInvalidPassword)
+	}
+
+	info := s.info
+	s.info = nil
+	s.challenge = nil
+
+	return s.join(info, s.verifiedClientType(info))
+}
+
+// verifiedClientType returns the client type of a user who has sent a valid
+// password: ClientTypeRegistered together with the types assigned by the
+// Authenticator, if it implements ClientTyper. The hub type is never
+// assigned to users.
+func (s *session) verifiedClientType(info *message.INFContent) message.ClientType {
+	ct := message.ClientTypeRegistered
+	if typer, ok := s.hub.config.Authenticator.(ClientTyper); ok {
+		ct |= typer.ClientType(info)
+	}
+
+	return ct &^ message.ClientTypeHub
+}
+
+// checkAddresses checks the I4 and I6 fields of info against the address the
+// client connects from. A zero address is replaced with the client's address,
+// or removed if the client connects over the other IP version. A recoverable
+// StatusError with the code 46 (invalid IP) and the expected address is
+// returned if a field contains another address. Nothing is checked if the
+// address of the client is unknown, i.e. the connection is not a TCP
+// connection.
+func (s *session) checkAddresses(info *message.INFContent) *message.StatusError {
+	addr, ok := s.conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return nil
+	}
+	remote, v4 := addr.IP, addr.IP.To4()
+
+	sErr := &message.StatusError{
+		Code:        message.StatusCode{Severity: message.SeverityRecoverable, Error: message.ErrorCodeInvalidIP},
+		Description: "IP address does not match " + remote.String(),
+	}
+	if v4 != nil {
+		sErr.I4.Set(v4)
+	} else {
+		sErr.I6.Set(remote)
+	}
+
+	cons := message.INFContentConstructor{Content: info}
+	if info.I4.IsSet {
+		switch {
+		case info.I4.Value.Equal(net.IPv4zero) && v4 != nil:
+			cons.SetI4(v4, string(message.INFFlagI4)+v4.String())
+		case info.I4.Value.Equal(net.IPv4zero):
+			info.I4.Unset()
+		case !info.I4.Value.Equal(v4):
+			return sErr
+		}
+	}
+	if info.I6.IsSet {
+		switch {
+		case info.I6.Value.Equal(net.IPv6zero) && v4 == nil:
+			cons.SetI6(remote, string(message.INFFlagI6)+remote.String())
+		case info.I6.Value.Equal(net.IPv6zero):
+			info.I6.Unset()
+		case v4 != nil || !info.I6.Value.Equal(remote):
+			return sErr
+		}
+	}
+
+	return nil
+}
+
+// stripClientType removes the CT field from an INF sent by a client, client
+// types are assigned by the hub.
+func stripClientType(info *message.INFContent) {
+	info.CT.Unset()
+	delete(info.Flags, string(message.INFFlagCT))
+}
+
+// join registers the user of the session and enters the NORMAL state. ct is
+// the client type assigned to the user, the CT field is omitted if it is 0.
+// The INFs of all other users are sent to the client, then the INF of the new
+// user is sent to all users including the client itself.
+func (s *session) join(info *message.INFContent, ct message.ClientType) error {
+	if ct != 0 {
+		info.SetClientType(ct)
+	}
+
+	u := &User{
+		SID:      s.sid,
+		CID:      info.ID.Value,
+		Nick:     info.NI.Value,
+		Info:     info,
+		Features: info.Features(),
+		session:  s,
+	}
+
+	line, err := formatMessage(infMessage(u))
+	if err != nil {
+		return s.fail(message.ErrorCodeProtocolGeneric, "Invalid INF", err)
+	}
+	u.infLine = line
+
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch err := h.users.Add(u); err {
+	case nil:
+	case ErrNickTaken:
+		return s.fail(message.ErrorCodeNickTaken, "Nick taken", err)
+	case ErrCIDTaken:
+		return s.fail(message.ErrorCodeCIDTaken, "CID taken", err)
+	default:
+		return s.fail(message.ErrorCodeLoginGeneric, "Login failed", err)
+	}
+
+	for _, other := range h.users.Users() {
+		if other != u {
+			s.enqueue(other.infLine)
+		}
+	}
+	h.enqueue(u.infLine)
+
+	s.user = u
+	s.state = message.StateNormal
+
+	return nil
+}
+
+func (s *session) handleNormal(mes *message.Message) error {
+	route := mes.Route()
+
+	switch route.Kind {
+	case message.RouteBroadcast, message.RouteDirect, message.RouteEcho, message.RouteFeature:
+		if route.Source == nil || route.Source.String() != s.sid.String() {
+			s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Invalid SID")
+			return nil
+		}
+	case message.RouteHub:
+		return s.handleHubMessage(mes)
+	default:
+		s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Invalid message type")
+		return nil
+	}
+
+	if mes.Type == message.TypeBroadcast && mes.Command == message.CommandINF {
+		return s.updateINF(mes)
+	}
+
+	line, err := formatMessage(mes)
+	if err != nil {
+		s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Invalid message")
+		return nil
+	}
+
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch route.Kind {
+	case message.RouteBroadcast:
+		h.enqueue(line)
+	case message.RouteDirect, message.RouteEcho:
+		target, ok := h.users.BySID(route.Target)
+		if !ok {
+			s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Unknown SID")
+			return nil
+		}
+
+		target.session.enqueue(line)
+		if route.Kind == message.RouteEcho && target != s.user {
+			s.enqueue(line)
+		}
+	case message.RouteFeature:
+		for _, u := range h.users.bySID {
+			if route.MatchesFeatures(u.Features) {
+				u.session.enqueue(line)
+			}
+		}
+	}
+
+	return nil
+}
+
+// handleHubMessage handles H messages in the NORMAL state. Only SUP is
+// interpreted, all other hub messages are ignored.
+func (s *session) handleHubMessage(mes *message.Message) error {
+	if mes.Command != message.CommandSUP {
+		return nil
+	}
+
+	s.features.Apply(mes.Content.(*message.SUPContent).FeatureOps)
+	if !s.features.Has("BASE") {
+		return s.fail(message.ErrorCodeFeatureMissing, "BASE is required", ErrBASENotSupported)
+	}
+
+	return nil
+}
+
+// updateINF applies the INF update mes to the INF of the user and broadcasts
+// the update.
+func (s *session) updateINF(mes *message.Message) error {
+	update := mes.Content.(*message.INFContent)
+
+	if update.ID.IsSet && update.ID.Value.String() != s.user.CID.String() {
+		s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "CID cannot be changed")
+		return nil
+	}
+
+	identity.StripPID(update)
+	stripClientType(update)
+
+	if err := message.ValidateINF(update); err != nil {
+		s.sendError(err.(*message.StatusError))
+		return nil
+	}
+	if sErr := s.checkAddresses(update); sErr != nil {
+		s.sendError(sErr)
+		return nil
+	}
+
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	u := s.user
+
+	info := &message.INFContent{}
+	info.Merge(u.Info)
+	info.Merge(update)
+	if err := message.ValidateINF(info, message.INFFlagID, message.INFFlagNI); err != nil {
+		s.sendError(err.(*message.StatusError))
+		return nil
+	}
+
+	line, err := formatMessage(mes)
+	if err != nil {
+		s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Invalid INF")
+		return nil
+	}
+	infLine, err := formatMessage(&message.Message{
+		Type:         message.TypeBroadcast,
+		Command:      message.CommandINF,
+		HeaderFields: message.BroadcastHeaderFields{MySID: u.SID},
+		Content:      info,
+	})
+	if err != nil {
+		s.sendStatus(message.SeverityRecoverable, message.ErrorCodeProtocolGeneric, "Invalid INF")
+		return nil
+	}
+
+	if err := h.users.Rename(u, info.NI.Value); err != nil {
+		s.sendStatus(message.SeverityRecoverable, message.ErrorCodeNickTaken, "Nick taken")
+		return nil
+	}
+
+	u.Info = info
+	u.Features = info.Features()
+	u.infLine = infLine
+
+	h.enqueue(line)
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package identity provides the PID (private ID) and CID (client ID) of ADC
+// clients.
+//
+// The PID is a random value known only to the client and the hubs it
+// connects to. The CID is the hash of the PID and identifies the client
+// publicly. CIDs are derived using Tiger, the hash function negotiated by the
+// TIGR feature, which is the only hash function defined for ADC.
+//
+// Hubs must check that the CID sent by a client is the hash of its PID and
+// must not forward the PID to other clients, see VerifyINF and StripPID.
+package identity
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/tth"
+)
+
+// Constants related to Identity.
+const (
+	// Size is the size of PIDs and CIDs in bytes, it is the size of Tiger
+	// hashes.
+	Size int = tth.Size
+)
+
+// Error variables related to Identity.
+var (
+	ErrInvalidPID  = errors.New("invalid PID, it is not a TIGR sized value")
+	ErrCIDMismatch = errors.New("CID is not the hash of the PID")
+)
+
+// Identity is a PID together with the CID derived from it.
+type Identity struct {
+	PID *encoding.Base32Value
+	CID *encoding.Base32Value
+}
+
+// Generate generates a new Identity with a random PID.
+func Generate() (Identity, error) {
+	pid := make([]byte, Size)
+	if _, err := rand.Read(pid); err != nil {
+		return Identity{}, err
+	}
+
+	return New(encoding.NewBase32Value(pid))
+}
+
+// New returns the Identity with the passed in PID. ErrInvalidPID is returned
+// if pid does not have the size of a Tiger hash.
+func New(pid *encoding.Base32Value) (Identity, error) {
+	cid, err := DeriveCID(pid)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{PID: pid, CID: cid}, nil
+}
+
+// DeriveCID returns the CID belonging to pid, i.e. the Tiger hash of pid.
+// ErrInvalidPID is returned if pid does not have the size of a Tiger hash.
+func DeriveCID(pid *encoding.Base32Value) (*encoding.Base32Value, error) {
+	if pid == nil || len(pid.Raw()) != Size {
+		return nil, ErrInvalidPID
+	}
+
+	cid := tth.Tiger(pid.Raw())
+
+	return encoding.NewBase32Value(cid[:]), nil
+}
+
+// Verify returns nil if cid is the CID belonging to pid. Otherwise,
+// ErrInvalidPID or ErrCIDMismatch is returned.
+func Verify(pid, cid *encoding.Base32Value) error {
+	derived, err := DeriveCID(pid)
+	if err != nil {
+		return err
+	}
+
+	if cid == nil || !bytes.Equal(derived.Raw(), cid.Raw()) {
+		return ErrCIDMismatch
+	}
+
+	return nil
+}
+
+// Load reads the Identity stored in the file at path by Save.
+func Load(path string) (Identity, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	pid, err := encoding.ParseBase32Value(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return New(pid)
+}
+
+// Save stores the PID of id in the file at path. The CID is derived again by
+// Load. The file is only readable by the current user, as the PID must be
+// kept secret.
+func (id Identity) Save(path string) error {
+	return ioutil.WriteFile(path, []byte(id.PID.String()+"\n"), 0600)
+}
+
+// LoadOrGenerate loads the Identity stored at path. If the file does not
+// exist, a new Identity is generated and saved at path.
+func LoadOrGenerate(path string) (Identity, error) {
+	id, err := Load(path)
+	if !os.IsNotExist(err) {
+		return id, err
+	}
+
+	id, err = Generate()
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return id, id.Save(path)
+}
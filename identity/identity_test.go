@@ -0,0 +1,133 @@
+package identity_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Identity", func() {
+	It("should generate random PIDs with matching CIDs", func() {
+		id, err := Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(id.PID.Raw()).Should(HaveLen(Size))
+
+		cid := tth.Tiger(id.PID.Raw())
+		Ω(id.CID.Raw()).Should(Equal(cid[:]))
+		Ω(Verify(id.PID, id.CID)).Should(Succeed())
+
+		other, err := Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(other.PID.String()).ShouldNot(Equal(id.PID.String()))
+		Ω(Verify(id.PID, other.CID)).Should(Equal(ErrCIDMismatch))
+	})
+
+	It("should reject PIDs of the wrong size", func() {
+		_, err := New(encoding.NewBase32Value([]byte("short")))
+		Ω(err).Should(Equal(ErrInvalidPID))
+
+		_, err = DeriveCID(nil)
+		Ω(err).Should(Equal(ErrInvalidPID))
+	})
+
+	Describe("persistence", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "identity")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("should load saved identities", func() {
+			path := filepath.Join(dir, "pid")
+
+			id, err := Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(id.Save(path)).Should(Succeed())
+
+			info, err := os.Stat(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0600)))
+
+			loaded, err := Load(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(loaded.PID.String()).Should(Equal(id.PID.String()))
+			Ω(loaded.CID.String()).Should(Equal(id.CID.String()))
+		})
+
+		It("should generate an identity only once", func() {
+			path := filepath.Join(dir, "pid")
+
+			id, err := LoadOrGenerate(path)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			again, err := LoadOrGenerate(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(again.PID.String()).Should(Equal(id.PID.String()))
+		})
+
+		It("should fail on corrupt files", func() {
+			path := filepath.Join(dir, "pid")
+			Ω(ioutil.WriteFile(path, []byte("not base32\n"), 0600)).Should(Succeed())
+
+			_, err := LoadOrGenerate(path)
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("VerifyINF", func() {
+	var id Identity
+
+	parseINF := func(params string) *message.INFContent {
+		info, err := parser.ParseINFContent(parser.NewMessageReader(params))
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+		return &info
+	}
+
+	BeforeEach(func() {
+		var err error
+		id, err = Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should accept matching PD and ID", func() {
+		info := parseINF("ID" + id.CID.String() + " PD" + id.PID.String() + " NIalice")
+		Ω(VerifyINF(info)).Should(Succeed())
+	})
+
+	It("should reject missing or mismatching fields", func() {
+		other, err := Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(VerifyINF(parseINF("ID" + id.CID.String()))).Should(Equal(ErrMissingPID))
+		Ω(VerifyINF(parseINF("PD" + id.PID.String()))).Should(Equal(ErrMissingCID))
+		Ω(VerifyINF(parseINF("ID" + other.CID.String() + " PD" + id.PID.String()))).Should(Equal(ErrCIDMismatch))
+		Ω(VerifyINF(parseINF("ID" + id.CID.String() + " PDAAAA"))).Should(Equal(ErrInvalidPID))
+	})
+
+	It("should strip the PID", func() {
+		info := parseINF("ID" + id.CID.String() + " PD" + id.PID.String())
+		StripPID(info)
+		Ω(info.PD.IsSet).Should(BeFalse())
+		Ω(info.Named()).ShouldNot(HaveKey("PD"))
+
+		info = parseINF("PD NIalice")
+		StripPID(info)
+		Ω(info.Named()).Should(Equal(map[string]string{"NI": "alice"}))
+	})
+})
@@ -0,0 +1,36 @@
+package identity
+
+import (
+	"errors"
+
+	"github.com/seoester/adcl/protocol/maybe"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Error variables related to verifying INFs.
+var (
+	ErrMissingPID = errors.New("INF lacks the PD field")
+	ErrMissingCID = errors.New("INF lacks the ID field")
+)
+
+// VerifyINF verifies the identity sent by a client in the IDENTIFY state:
+// info must contain both PD and ID and the CID (ID) must be the hash of the
+// PID (PD). ErrMissingPID, ErrMissingCID, ErrInvalidPID or ErrCIDMismatch is
+// returned otherwise.
+func VerifyINF(info *message.INFContent) error {
+	if !info.PD.IsSet {
+		return ErrMissingPID
+	}
+	if !info.ID.IsSet {
+		return ErrMissingCID
+	}
+
+	return Verify(info.PD.Value, info.ID.Value)
+}
+
+// StripPID removes the PD field from info, including a PD field with an
+// empty value. Hubs must strip the PID before forwarding an INF.
+func StripPID(info *message.INFContent) {
+	info.PD = maybe.Base32Value{}
+	delete(info.Flags, string(message.INFFlagPD))
+}
@@ -0,0 +1,64 @@
+package bzip2
+
+import (
+	"bufio"
+	"io"
+)
+
+// bitWriter writes bits most significant bit first.
+type bitWriter struct {
+	w   io.Writer
+	buf *bufio.Writer
+	err error
+
+	bits  uint64
+	nBits uint
+}
+
+// writeBits writes the lowest n bits of v, n must not exceed 48.
+func (b *bitWriter) writeBits(v uint64, n uint) {
+	if b.buf == nil {
+		b.buf = bufio.NewWriter(b.w)
+	}
+
+	b.bits = b.bits<<n | v&(1<<n-1)
+	b.nBits += n
+	for b.nBits >= 8 {
+		b.nBits -= 8
+		if b.err == nil {
+			b.err = b.buf.WriteByte(byte(b.bits >> b.nBits))
+		}
+	}
+}
+
+// flush pads the last byte with zeros and flushes the buffer.
+func (b *bitWriter) flush() {
+	if b.nBits > 0 {
+		b.writeBits(0, 8-b.nBits)
+	}
+	if b.err == nil && b.buf != nil {
+		b.err = b.buf.Flush()
+	}
+}
+
+// crcTable is the table of the CRC-32 used by bzip2, which processes bits
+// most significant bit first unlike hash/crc32.
+var crcTable [256]uint32
+
+func init() {
+	for i := range crcTable {
+		c := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04c11db7
+			} else {
+				c <<= 1
+			}
+		}
+		crcTable[i] = c
+	}
+}
+
+func crcUpdate(crc uint32, b byte) uint32 {
+	return crc<<8 ^ crcTable[byte(crc>>24)^b]
+}
@@ -0,0 +1,13 @@
+package bzip2_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBzip2(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bzip2 Suite")
+}
@@ -0,0 +1,476 @@
+// Package bzip2 implements a bzip2 compressor. The standard library only
+// provides a decompressor (compress/bzip2), which is used to read compressed
+// data.
+//
+// The compressor favours simplicity over compression ratio: every block is
+// encoded with a single Huffman table.
+package bzip2
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrClosed is returned when writing to a closed Writer.
+var ErrClosed = errors.New("bzip2: writer is closed")
+
+// Constants related to the bzip2 format.
+const (
+	// blockSize is the maximum size of a block after the initial run-length
+	// encoding, it corresponds to the block size level 9. A few bytes are
+	// reserved for flushing a pending run.
+	blockSize = 900000 - 19
+
+	blockMagic  uint64 = 0x314159265359
+	streamMagic uint64 = 0x177245385090
+
+	// groupSize is the number of symbols encoded with the same Huffman
+	// table.
+	groupSize = 50
+	// maxCodeLength is the maximum length of the Huffman codes produced.
+	maxCodeLength = 17
+)
+
+// Writer compresses data written to it and writes the bzip2 stream to the
+// underlying writer. Close must be called to write the end of the stream.
+type Writer struct {
+	w   *bitWriter
+	err error
+
+	wroteHeader bool
+	closed      bool
+
+	// block contains the run-length encoded data of the current block, crc
+	// is the checksum of the data before encoding.
+	block []byte
+	crc   uint32
+	// combinedCRC is the checksum of the stream.
+	combinedCRC uint32
+
+	// runByte and runLength describe the run of identical bytes which has not
+	// been added to block yet.
+	runByte   byte
+	runLength int
+}
+
+// NewWriter returns a new Writer writing the compressed stream to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:     &bitWriter{w: w},
+		block: make([]byte, 0, blockSize),
+		crc:   0xffffffff,
+	}
+}
+
+// Write compresses p.
+func (z *Writer) Write(p []byte) (n int, err error) {
+	if z.closed {
+		return 0, ErrClosed
+	}
+	if z.err != nil {
+		return 0, z.err
+	}
+
+	for i, b := range p {
+		if z.runLength > 0 && b == z.runByte && z.runLength < 255 {
+			z.crc = crcUpdate(z.crc, b)
+			z.runLength++
+			continue
+		}
+
+		z.flushRun()
+		if len(z.block) >= blockSize {
+			if err := z.writeBlock(); err != nil {
+				return i, err
+			}
+		}
+
+		z.crc = crcUpdate(z.crc, b)
+		z.runByte, z.runLength = b, 1
+	}
+
+	return len(p), nil
+}
+
+// flushRun appends the pending run to the block. Runs of four to 255 bytes
+// are encoded as four bytes followed by the number of remaining bytes.
+func (z *Writer) flushRun() {
+	switch {
+	case z.runLength == 0:
+	case z.runLength < 4:
+		for i := 0; i < z.runLength; i++ {
+			z.block = append(z.block, z.runByte)
+		}
+	default:
+		z.block = append(z.block, z.runByte, z.runByte, z.runByte, z.runByte, byte(z.runLength-4))
+	}
+
+	z.runLength = 0
+}
+
+// Close writes the remaining data and the end of the stream. It does not
+// close the underlying writer.
+func (z *Writer) Close() error {
+	if z.closed {
+		return ErrClosed
+	}
+	z.closed = true
+	if z.err != nil {
+		return z.err
+	}
+
+	z.flushRun()
+	if len(z.block) > 0 {
+		if err := z.writeBlock(); err != nil {
+			return err
+		}
+	}
+
+	z.writeHeader()
+	z.w.writeBits(streamMagic, 48)
+	z.w.writeBits(uint64(z.combinedCRC), 32)
+	z.w.flush()
+
+	z.err = z.w.err
+	return z.err
+}
+
+func (z *Writer) writeHeader() {
+	if !z.wroteHeader {
+		z.wroteHeader = true
+		z.w.writeBits(uint64('B')<<24|uint64('Z')<<16|uint64('h')<<8|uint64('9'), 32)
+	}
+}
+
+// writeBlock compresses and writes the current block and starts a new one.
+func (z *Writer) writeBlock() error {
+	z.writeHeader()
+
+	crc := ^z.crc
+	z.combinedCRC = (z.combinedCRC<<1 | z.combinedCRC>>31) ^ crc
+
+	bwt, origPtr := transform(z.block)
+	symbols, alphaSize, inUse := moveToFront(bwt)
+
+	w := z.w
+	w.writeBits(blockMagic, 48)
+	w.writeBits(uint64(crc), 32)
+	w.writeBits(0, 1) // not randomised
+	w.writeBits(uint64(origPtr), 24)
+
+	// Symbol map: a bit for each range of 16 bytes in use, followed by a
+	// bit for each byte of the ranges in use.
+	var ranges uint64
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 16; j++ {
+			if inUse[i*16+j] {
+				ranges |= 1 << uint(15-i)
+				break
+			}
+		}
+	}
+	w.writeBits(ranges, 16)
+	for i := 0; i < 16; i++ {
+		if ranges&(1<<uint(15-i)) == 0 {
+			continue
+		}
+		var bits uint64
+		for j := 0; j < 16; j++ {
+			if inUse[i*16+j] {
+				bits |= 1 << uint(15-j)
+			}
+		}
+		w.writeBits(bits, 16)
+	}
+
+	freqs := make([]int, alphaSize)
+	for _, s := range symbols {
+		freqs[s]++
+	}
+	lengths := codeLengths(freqs)
+	codes := assignCodes(lengths)
+
+	// The format requires at least two tables, both are the same and all
+	// selectors choose the first one.
+	const numTables = 2
+	numSelectors := (len(symbols) + groupSize - 1) / groupSize
+	w.writeBits(numTables, 3)
+	w.writeBits(uint64(numSelectors), 15)
+	for i := 0; i < numSelectors; i++ {
+		w.writeBits(0, 1)
+	}
+
+	for t := 0; t < numTables; t++ {
+		cur := lengths[0]
+		w.writeBits(uint64(cur), 5)
+		for _, l := range lengths {
+			for cur < l {
+				w.writeBits(2, 2)
+				cur++
+			}
+			for cur > l {
+				w.writeBits(3, 2)
+				cur--
+			}
+			w.writeBits(0, 1)
+		}
+	}
+
+	for _, s := range symbols {
+		w.writeBits(uint64(codes[s]), uint(lengths[s]))
+	}
+
+	z.block = z.block[:0]
+	z.crc = 0xffffffff
+
+	z.err = w.err
+	return z.err
+}
+
+// transform computes the Burrows-Wheeler transform of block. origPtr is the
+// position of the original block among its sorted rotations.
+func transform(block []byte) (bwt []byte, origPtr int) {
+	n := len(block)
+	p := sortRotations(block)
+
+	bwt = make([]byte, n)
+	for i, start := range p {
+		if start == 0 {
+			origPtr = i
+			bwt[i] = block[n-1]
+		} else {
+			bwt[i] = block[start-1]
+		}
+	}
+
+	return bwt, origPtr
+}
+
+// sortRotations returns the start positions of the rotations of block in
+// sorted order. Rotations are sorted by prefix doubling, sorting by the first
+// 2^k bytes in round k.
+func sortRotations(block []byte) []int {
+	n := len(block)
+	p := make([]int, n)
+	class := make([]int, n)
+
+	count := make([]int, 256)
+	for _, b := range block {
+		count[b]++
+	}
+	for i := 1; i < 256; i++ {
+		count[i] += count[i-1]
+	}
+	for i := n - 1; i >= 0; i-- {
+		count[block[i]]--
+		p[count[block[i]]] = i
+	}
+
+	classes := 1
+	for i := 1; i < n; i++ {
+		if block[p[i]] != block[p[i-1]] {
+			classes++
+		}
+		class[p[i]] = classes - 1
+	}
+
+	pn := make([]int, n)
+	cn := make([]int, n)
+	for h := 1; h < n && classes < n; h <<= 1 {
+		// p is sorted by the first h bytes, sorting the rotations starting
+		// h bytes earlier stably by their first h bytes sorts them by 2h
+		// bytes.
+		for i, start := range p {
+			pn[i] = start - h
+			if pn[i] < 0 {
+				pn[i] += n
+			}
+		}
+
+		count = make([]int, classes)
+		for _, c := range class {
+			count[c]++
+		}
+		for i := 1; i < classes; i++ {
+			count[i] += count[i-1]
+		}
+		for i := n - 1; i >= 0; i-- {
+			c := class[pn[i]]
+			count[c]--
+			p[count[c]] = pn[i]
+		}
+
+		cn[p[0]] = 0
+		classes = 1
+		for i := 1; i < n; i++ {
+			cur, prev := p[i], p[i-1]
+			if class[cur] != class[prev] || class[(cur+h)%n] != class[(prev+h)%n] {
+				classes++
+			}
+			cn[cur] = classes - 1
+		}
+		class, cn = cn, class
+	}
+
+	return p
+}
+
+// Symbols of the move-to-front encoding encoding runs of zeros.
+const (
+	runA = 0
+	runB = 1
+)
+
+// moveToFront applies the move-to-front transform to bwt and encodes runs of
+// zeros in bijective base 2 using runA and runB. The other values v are
+// encoded as v+1, the last symbol is the end of block symbol alphaSize-1.
+func moveToFront(bwt []byte) (symbols []uint16, alphaSize int, inUse [256]bool) {
+	for _, b := range bwt {
+		inUse[b] = true
+	}
+
+	var list []byte
+	for i, used := range inUse {
+		if used {
+			list = append(list, byte(i))
+		}
+	}
+	alphaSize = len(list) + 2
+
+	symbols = make([]uint16, 0, len(bwt)+1)
+	zeros := 0
+	flushZeros := func() {
+		if zeros == 0 {
+			return
+		}
+		zeros--
+		for {
+			symbols = append(symbols, uint16(runA+zeros&1))
+			if zeros < 2 {
+				break
+			}
+			zeros = (zeros - 2) / 2
+		}
+		zeros = 0
+	}
+
+	for _, b := range bwt {
+		j := 0
+		for list[j] != b {
+			j++
+		}
+		if j == 0 {
+			zeros++
+			continue
+		}
+
+		flushZeros()
+		copy(list[1:j+1], list[:j])
+		list[0] = b
+		symbols = append(symbols, uint16(j+1))
+	}
+	flushZeros()
+	symbols = append(symbols, uint16(alphaSize-1))
+
+	return symbols, alphaSize, inUse
+}
+
+// codeLengths computes the Huffman code lengths for freqs. Every symbol is
+// assigned a code, codes are limited to maxCodeLength bits by flattening the
+// frequencies until the limit is satisfied.
+func codeLengths(freqs []int) []int {
+	weights := make([]int, len(freqs))
+	for i, f := range freqs {
+		weights[i] = f
+		if weights[i] == 0 {
+			weights[i] = 1
+		}
+	}
+
+	for {
+		lengths := huffmanLengths(weights)
+
+		max := 0
+		for _, l := range lengths {
+			if l > max {
+				max = l
+			}
+		}
+		if max <= maxCodeLength {
+			return lengths
+		}
+
+		for i := range weights {
+			weights[i] = weights[i]/2 + 1
+		}
+	}
+}
+
+// huffmanLengths computes the code lengths of an optimal prefix code for
+// weights, which must contain at least two values.
+func huffmanLengths(weights []int) []int {
+	type node struct {
+		weight int
+		parent int
+	}
+
+	nodes := make([]node, len(weights), 2*len(weights)-1)
+	for i, w := range weights {
+		nodes[i] = node{weight: w, parent: -1}
+	}
+
+	// active contains the indexes of the nodes without parent. The
+	// alphabet has at most 258 symbols, so a linear search for the two
+	// lightest nodes is sufficient.
+	active := make([]int, len(weights))
+	for i := range active {
+		active[i] = i
+	}
+	lightest := func() int {
+		min := 0
+		for i := range active {
+			if nodes[active[i]].weight < nodes[active[min]].weight {
+				min = i
+			}
+		}
+		n := active[min]
+		active = append(active[:min], active[min+1:]...)
+		return n
+	}
+
+	for len(active) > 1 {
+		a, b := lightest(), lightest()
+		parent := len(nodes)
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+		nodes[a].parent, nodes[b].parent = parent, parent
+		active = append(active, parent)
+	}
+
+	lengths := make([]int, len(weights))
+	for i := range lengths {
+		for n := i; nodes[n].parent >= 0; n = nodes[n].parent {
+			lengths[i]++
+		}
+	}
+
+	return lengths
+}
+
+// assignCodes assigns canonical Huffman codes: codes are assigned in order of
+// increasing length, symbols of the same length in increasing order.
+func assignCodes(lengths []int) []uint32 {
+	codes := make([]uint32, len(lengths))
+
+	var code uint32
+	for l := 1; l <= maxCodeLength; l++ {
+		for s, sl := range lengths {
+			if sl == l {
+				codes[s] = code
+				code++
+			}
+		}
+		code <<= 1
+	}
+
+	return codes
+}
@@ -0,0 +1,86 @@
+package bzip2_test
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+
+	. "github.com/seoester/adcl/internal/bzip2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func roundTrip(data []byte, chunk int) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for i := 0; i < len(data); i += chunk {
+		end := i + chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := w.Write(data[i:end])
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	}
+	ExpectWithOffset(1, w.Close()).Should(Succeed())
+
+	out, err := ioutil.ReadAll(bzip2.NewReader(&buf))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return out
+}
+
+var _ = Describe("Writer", func() {
+	It("should produce streams readable by compress/bzip2", func() {
+		inputs := []string{
+			"",
+			"a",
+			"ab",
+			"banana",
+			"aaaa",
+			strings.Repeat("a", 1000),
+			strings.Repeat("abc", 1000),
+			strings.Repeat("x", 3) + strings.Repeat("y", 4) + strings.Repeat("z", 255) + strings.Repeat("w", 256),
+			strings.Repeat(`<File Name="song.mp3" Size="1234" TTH="LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"/>`, 500),
+		}
+
+		for _, in := range inputs {
+			Ω(roundTrip([]byte(in), 7)).Should(Equal([]byte(in)), in)
+		}
+	})
+
+	It("should compress redundant data", func() {
+		data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 1000))
+
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		_, err := w.Write(data)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(w.Close()).Should(Succeed())
+		Ω(buf.Len()).Should(BeNumerically("<", len(data)/20))
+	})
+
+	It("should split large inputs into blocks", func() {
+		rnd := rand.New(rand.NewSource(1))
+		data := make([]byte, 2*1024*1024)
+		for i := range data {
+			// Skewed random data with long runs.
+			if i > 0 && rnd.Intn(4) == 0 {
+				data[i] = data[i-1]
+			} else {
+				data[i] = byte(rnd.Intn(64))
+			}
+		}
+
+		Ω(roundTrip(data, 64*1024)).Should(Equal(data))
+	})
+
+	It("should not accept writes after Close", func() {
+		w := NewWriter(ioutil.Discard)
+		Ω(w.Close()).Should(Succeed())
+		_, err := w.Write([]byte("a"))
+		Ω(err).Should(Equal(ErrClosed))
+		Ω(w.Close()).Should(Equal(ErrClosed))
+	})
+})
@@ -11,10 +11,17 @@ func EncodeToBase32String(src []byte) string {
 }
 
 func DecodeBase32String(s string) ([]byte, error) {
+	// encoding/base32 silently skips end-of-line characters and ignores
+	// incomplete trailing bytes, both are invalid in ADC though.
+	if err := validateBase32String(s); err != nil {
+		return nil, err
+	}
+
 	return noPaddingEncoding.DecodeString(s)
 }
 
 type Base32Value struct {
+	rawSet bool
 	raw    []byte
 	strSet bool
 	str    string
@@ -22,23 +29,43 @@ type Base32Value struct {
 
 func NewBase32Value(raw []byte) *Base32Value {
 	return &Base32Value{
-		raw: raw,
+		rawSet: true,
+		raw:    raw,
 	}
 }
 
+// ParseBase32Value validates str and returns a Base32Value representing it.
+// The string is only decoded once Raw is called.
 func ParseBase32Value(str string) (*Base32Value, error) {
-	raw, err := DecodeBase32String(str)
-	if err != nil {
+	if err := validateBase32String(str); err != nil {
 		return nil, err
 	}
 
 	return &Base32Value{
-		raw:    raw,
 		strSet: true,
 		str:    str,
 	}, nil
 }
 
+// validateBase32String returns an error if s is not a valid unpadded base32
+// string. It does not allocate.
+func validateBase32String(s string) error {
+	for i := 0; i < len(s); i++ {
+		if !(IsUpperAlpha(s[i]) || (s[i] >= '2' && s[i] <= '7')) {
+			return base32.CorruptInputError(i)
+		}
+	}
+
+	// Each 8 characters encode 5 bytes. The remaining characters must encode
+	// at least one full byte.
+	switch len(s) % 8 {
+	case 1, 3, 6:
+		return base32.CorruptInputError(len(s) - len(s)%8)
+	}
+
+	return nil
+}
+
 func (b *Base32Value) String() string {
 	if !b.strSet {
 		b.str = EncodeToBase32String(b.raw)
@@ -49,5 +76,11 @@ func (b *Base32Value) String() string {
 }
 
 func (b *Base32Value) Raw() []byte {
+	if !b.rawSet {
+		// The string has already been validated by ParseBase32Value.
+		b.raw, _ = DecodeBase32String(b.str)
+		b.rawSet = true
+	}
+
 	return b.raw
 }
@@ -19,12 +19,6 @@ var encoder = strings.NewReplacer(
 	"\\", "\\\\",
 )
 
-var decoder = strings.NewReplacer(
-	"\\s", " ",
-	"\\n", "\n",
-	"\\\\", "\\",
-)
-
 // EncodeToADCString encodes a string to the ADC string parameter format. An
 // error is returned if the passed in string is not a valid UTF-8 string.
 func EncodeToADCString(s string) (string, error) {
@@ -32,6 +26,11 @@ func EncodeToADCString(s string) (string, error) {
 		return "", ErrInvalidString
 	}
 
+	if strings.IndexAny(s, " \n\\") == -1 {
+		// Fast path: Nothing to escape, avoid the replacer's allocations.
+		return s, nil
+	}
+
 	return encoder.Replace(s), nil
 }
 
@@ -43,7 +42,34 @@ func DecodeADCString(s string) (string, error) {
 		return "", ErrInvalidString
 	}
 
-	return decoder.Replace(s), nil
+	if strings.IndexByte(s, '\\') == -1 {
+		// Fast path: Nothing to unescape.
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if c == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 's':
+				c = ' '
+				i++
+			case 'n':
+				c = '\n'
+				i++
+			case '\\':
+				i++
+			}
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String(), nil
 }
 
 // Constants which are used in encodings and checks.
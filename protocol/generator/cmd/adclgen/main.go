@@ -0,0 +1,59 @@
+// Command adclgen generates the message.???Content types, the
+// parser.Parse???Content and the writer.Write???Content functions for all
+// messages specified in the specification files passed by -spec.
+//
+// It is meant to be run by go generate from the message package directory:
+//
+//     //go:generate go run ../generator/cmd/adclgen -spec ../generator/spec/base.yaml -message . -parser ../parser -writer ../writer
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/generator"
+)
+
+// stringsFlag is a flag which may be specified multiple times.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func main() {
+	var g generator.Generator
+	var specFiles stringsFlag
+
+	flag.Var(&specFiles, "spec", "specification file (.yaml, .yml or .json), may be specified multiple times")
+	flag.StringVar(&g.MessageDir, "message", "message", "directory of the message package")
+	flag.StringVar(&g.ParserDir, "parser", "parser", "directory of the parser package")
+	flag.StringVar(&g.WriterDir, "writer", "writer", "directory of the writer package")
+	flag.Parse()
+
+	if len(specFiles) == 0 {
+		fmt.Fprintln(os.Stderr, "adclgen: at least one -spec file is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	g.Messages, err = generator.LoadSpecFiles(specFiles...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "adclgen:", err)
+		os.Exit(1)
+	}
+
+	err = g.Generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "adclgen:", err)
+		os.Exit(1)
+	}
+}
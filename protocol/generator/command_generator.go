@@ -0,0 +1,275 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// CommandGenerator generates the code dispatching on the command of a
+// message: the message.Command constants and ParseCommand, parser.ParseContent
+// (as well as ParseContentInto and ReleaseContent) and writer.WriteContent.
+type CommandGenerator struct {
+	messages []*Message
+}
+
+func NewCommandGenerator(messages []*Message) *CommandGenerator {
+	return &CommandGenerator{
+		messages: messages,
+	}
+}
+
+func (c *CommandGenerator) commandConst(message *Message) string {
+	return "Command" + message.Command
+}
+
+// GenerateCommands generates the Command type, a constant for each known
+// command and ParseCommand.
+func (c *CommandGenerator) GenerateCommands() *jen.File {
+	file := newFile(messagePackage, "message")
+
+	file.Type().Id("Command").String()
+
+	file.Const().DefsFunc(func(group *jen.Group) {
+		for _, message := range c.messages {
+			group.Id(c.commandConst(message)).Id("Command").Op("=").Lit(message.Command)
+		}
+	})
+
+	file.Comment(`// ParseCommand returns a Command typed version of a string. The second return
+// value indicates whether the command is known, i.e. has a Command constant
+// in this package.
+//
+// If the string is a known command, the associated Command constant is
+// returned. If the string is not known, but syntactically a valid command,
+// the string is returned as a Command type. If the string is not a valid
+// command, ErrInvalidCommandName is returned.
+//
+// Using the constants allows (slightly) faster equality checking.`)
+	file.Func().Id("ParseCommand").
+		Params(jen.Id("s").String()).
+		Params(jen.Id("Command"), jen.Bool(), jen.Error()).
+		Block(
+			jen.Switch(jen.Id("Command").Call(jen.Id("s"))).BlockFunc(func(group *jen.Group) {
+				for _, message := range c.messages {
+					group.Case(jen.Id(c.commandConst(message))).Block(
+						jen.Return(jen.Id(c.commandConst(message)), jen.True(), jen.Nil()),
+					)
+				}
+
+				group.Default().Block(
+					jen.If(jen.Op("!").Parens(
+						jen.Len(jen.Id("s")).Op("==").Lit(3).Op("&&").Line().
+							Qual(encodingPackage, "IsUpperAlpha").Call(jen.Id("s").Index(jen.Lit(0))).Op("&&").Line().
+							Qual(encodingPackage, "IsUpperAlphaNum").Call(jen.Id("s").Index(jen.Lit(1))).Op("&&").Line().
+							Qual(encodingPackage, "IsUpperAlphaNum").Call(jen.Id("s").Index(jen.Lit(2))),
+					)).Block(
+						jen.Return(jen.Id("Command").Call(jen.Lit("")), jen.False(), jen.Id("ErrInvalidCommandName")),
+					),
+					jen.Line(),
+					jen.Return(jen.Id("Command").Call(jen.Id("s")), jen.False(), jen.Nil()),
+				)
+			}),
+		)
+
+	return file
+}
+
+// GenerateParseContent generates parser.ParseContent, which calls the
+// Parse???Content function associated with the command.
+func (c *CommandGenerator) GenerateParseContent() *jen.File {
+	file := newFile(parserPackage, "parser")
+
+	parseCase := func(function string) []jen.Code {
+		return []jen.Code{
+			jen.List(jen.Id("cnt"), jen.Err()).Op(":=").Id(function).Call(jen.Id("m")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Return(jen.Op("&").Id("cnt"), jen.Err()),
+		}
+	}
+
+	parseIntoCase := func(typeName, poolVar string) []jen.Code {
+		return []jen.Code{
+			jen.List(jen.Id("c"), jen.Id("ok")).Op(":=").Id("cnt").Assert(jen.Op("*").Qual(messagePackage, typeName)),
+			jen.If(jen.Op("!").Id("ok")).Block(
+				jen.Id("ReleaseContent").Call(jen.Id("cnt")),
+				jen.Id("c").Op("=").Id(poolVar).Dot("Get").Call().Assert(jen.Op("*").Qual(messagePackage, typeName)),
+			),
+			jen.If(
+				jen.Err().Op(":=").Id("Parse"+typeName+"Into").Call(jen.Id("m"), jen.Id("c")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Id("ReleaseContent").Call(jen.Id("c")),
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Return(jen.Id("c"), jen.Nil()),
+		}
+	}
+
+	file.Comment(`// ParseContent parses the parameters of a message with command cmd. Known
+// commands are parsed by their specific Parse...Content function, commands
+// registered using RegisterContentParser by the registered function and all
+// other commands are parsed by ParseGenericContent.`)
+	file.Func().Id("ParseContent").
+		Params(
+			jen.Id("m").Op("*").Id("MessageReader"),
+			jen.Id("cmd").Qual(messagePackage, "Command"),
+		).
+		Params(
+			jen.Id("cnt").Qual(messagePackage, "ParamAccessor"),
+			jen.Err().Error(),
+		).
+		Block(
+			jen.Switch(jen.Id("cmd")).BlockFunc(func(group *jen.Group) {
+				for _, message := range c.messages {
+					group.Case(jen.Qual(messagePackage, c.commandConst(message))).
+						Block(parseCase("Parse" + message.Command + "Content")...)
+				}
+
+				group.Default().Block(append(
+					[]jen.Code{
+						jen.If(
+							jen.List(jen.Id("parse"), jen.Id("ok")).Op(":=").Id("registeredContentParser").Call(jen.Id("cmd")),
+							jen.Id("ok"),
+						).Block(
+							jen.Return(jen.Id("parse").Call(jen.Id("m"))),
+						),
+						jen.Line(),
+					},
+					parseCase("ParseGenericContent")...,
+				)...)
+			}),
+		)
+
+	file.Line()
+
+	file.Comment(`// ParseContentInto is like ParseContent, but parses into cnt if it is of the
+// content type of cmd. Otherwise, cnt is released (see ReleaseContent) and a
+// content is obtained from a pool. Contents obtained from ParseContentInto
+// should be passed to ReleaseContent once they are not used anymore.
+//
+// On error, the content is released and nil is returned.`)
+	file.Func().Id("ParseContentInto").
+		Params(
+			jen.Id("m").Op("*").Id("MessageReader"),
+			jen.Id("cmd").Qual(messagePackage, "Command"),
+			jen.Id("cnt").Qual(messagePackage, "ParamAccessor"),
+		).
+		Params(
+			jen.Qual(messagePackage, "ParamAccessor"),
+			jen.Error(),
+		).
+		Block(
+			jen.Switch(jen.Id("cmd")).BlockFunc(func(group *jen.Group) {
+				for _, message := range c.messages {
+					group.Case(jen.Qual(messagePackage, c.commandConst(message))).
+						Block(parseIntoCase(message.Command+"Content", c.poolVar(message.Command+"Content"))...)
+				}
+
+				group.Default().Block(append(
+					[]jen.Code{
+						jen.If(
+							jen.List(jen.Id("parse"), jen.Id("ok")).Op(":=").Id("registeredContentParser").Call(jen.Id("cmd")),
+							jen.Id("ok"),
+						).Block(
+							jen.Id("ReleaseContent").Call(jen.Id("cnt")),
+							jen.Return(jen.Id("parse").Call(jen.Id("m"))),
+						),
+						jen.Line(),
+					},
+					parseIntoCase("GenericContent", c.poolVar("GenericContent"))...,
+				)...)
+			}),
+		)
+
+	file.Line()
+
+	file.Comment(`// ReleaseContent puts cnt into the pool of its content type, from which
+// ParseContentInto obtains contents. cnt must not be used after calling
+// ReleaseContent. Contents of other types are ignored.`)
+	file.Func().Id("ReleaseContent").
+		Params(jen.Id("cnt").Qual(messagePackage, "ParamAccessor")).
+		Block(
+			jen.Switch(jen.Id("c").Op(":=").Id("cnt").Assert(jen.Type())).BlockFunc(func(group *jen.Group) {
+				for _, typeName := range c.pooledTypes() {
+					group.Case(jen.Op("*").Qual(messagePackage, typeName)).Block(
+						jen.Id(c.poolVar(typeName)).Dot("Put").Call(jen.Id("c")),
+					)
+				}
+			}),
+		)
+
+	file.Line()
+
+	file.Var().DefsFunc(func(group *jen.Group) {
+		for _, typeName := range c.pooledTypes() {
+			group.Id(c.poolVar(typeName)).Op("=").Qual("sync", "Pool").Values(jen.Dict{
+				jen.Id("New"): jen.Func().Params().Interface().Block(
+					jen.Return(jen.New(jen.Qual(messagePackage, typeName))),
+				),
+			})
+		}
+	})
+
+	return file
+}
+
+// pooledTypes returns the names of all content types, for which
+// ParseContentInto maintains a pool.
+func (c *CommandGenerator) pooledTypes() []string {
+	typeNames := make([]string, 0, len(c.messages)+1)
+	for _, message := range c.messages {
+		typeNames = append(typeNames, message.Command+"Content")
+	}
+
+	return append(typeNames, "GenericContent")
+}
+
+// poolVar returns the name of the variable holding the pool of the content
+// type typeName.
+func (c *CommandGenerator) poolVar(typeName string) string {
+	prefix := strings.TrimSuffix(typeName, "Content")
+	if prefix == strings.ToUpper(prefix) {
+		// Command, e.g. STA
+		prefix = strings.ToLower(prefix)
+	} else {
+		prefix = strings.ToLower(prefix[:1]) + prefix[1:]
+	}
+
+	return prefix + "ContentPool"
+}
+
+// GenerateWriteContent generates writer.WriteContent, which calls the
+// Write???Content function associated with the content type.
+func (c *CommandGenerator) GenerateWriteContent() *jen.File {
+	file := newFile(writerPackage, "writer")
+
+	file.Comment(`// WriteContent writes the parameters of cnt. Known content types are written
+// by their specific Write...Content function, all other types are written by
+// WriteGenericContent.`)
+	file.Func().Id("WriteContent").
+		Params(
+			jen.Id("m").Op("*").Id("MessageWriter"),
+			jen.Id("cnt").Qual(messagePackage, "ParamAccessor"),
+		).
+		Error().
+		Block(
+			jen.Switch(jen.Id("c").Op(":=").Id("cnt").Assert(jen.Type())).BlockFunc(func(group *jen.Group) {
+				group.Case(jen.Nil()).Block(jen.Return(jen.Nil()))
+
+				for _, message := range c.messages {
+					group.Case(jen.Op("*").Qual(messagePackage, message.Command+"Content")).Block(
+						jen.Return(jen.Id("Write"+message.Command+"Content").Call(jen.Id("m"), jen.Id("c"))),
+					)
+				}
+
+				group.Default().Block(
+					jen.Return(jen.Id("WriteGenericContent").Call(jen.Id("m"), jen.Id("cnt"))),
+				)
+			}),
+		)
+
+	return file
+}
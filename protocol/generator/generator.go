@@ -0,0 +1,291 @@
+package generator
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pkg/errors"
+)
+
+// HeaderComment is the comment marking all files written by the generator.
+const HeaderComment = "Code generated by adcl/protocol/generator. DO NOT EDIT."
+
+// Generator generates the ???Content struct types, the Parse???Content and
+// the Write???Content functions for all Messages as well as the dispatching
+// code (Command constants, ParseContent and WriteContent) and writes them to
+// the message, parser and writer package directories.
+type Generator struct {
+	Messages []*Message
+
+	// MessageDir is the directory of the message package.
+	MessageDir string
+	// ParserDir is the directory of the parser package.
+	ParserDir string
+	// WriterDir is the directory of the writer package.
+	WriterDir string
+}
+
+// Generate validates the messages, generates and saves all files.
+func (g *Generator) Generate() error {
+	err := Validate(g.Messages)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range g.Messages {
+		name := strings.ToLower(message.Command) + ".go"
+
+		file, err := NewStructGenerator(message).Generate()
+		if err != nil {
+			return err
+		}
+		err = saveFile(file, filepath.Join(g.MessageDir, "content_"+name))
+		if err != nil {
+			return err
+		}
+
+		file, err = NewParseGenerator(message).Generate()
+		if err != nil {
+			return err
+		}
+		err = saveFile(file, filepath.Join(g.ParserDir, "parse_"+name))
+		if err != nil {
+			return err
+		}
+
+		file, err = NewWriteGenerator(message).Generate()
+		if err != nil {
+			return err
+		}
+		err = saveFile(file, filepath.Join(g.WriterDir, "write_"+name))
+		if err != nil {
+			return err
+		}
+	}
+
+	commandGenerator := NewCommandGenerator(g.Messages)
+
+	err = saveFile(commandGenerator.GenerateCommands(), filepath.Join(g.MessageDir, "command.go"))
+	if err != nil {
+		return err
+	}
+
+	err = saveFile(commandGenerator.GenerateParseContent(), filepath.Join(g.ParserDir, "parse_content.go"))
+	if err != nil {
+		return err
+	}
+
+	err = saveFile(commandGenerator.GenerateWriteContent(), filepath.Join(g.WriterDir, "write_content.go"))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func saveFile(file *jen.File, filename string) error {
+	buf := bytes.NewBuffer(nil)
+
+	err := file.Render(buf)
+	if err != nil {
+		return errors.Wrapf(err, "rendering %s failed", filename)
+	}
+
+	src, err := groupImports(buf.Bytes())
+	if err != nil {
+		return errors.Wrapf(err, "formatting %s failed", filename)
+	}
+
+	return ioutil.WriteFile(filename, src, 0644)
+}
+
+// groupImports separates the imports of the standard library from all other
+// imports in the import block of src, as goimports does. jen sorts all
+// imports by path only.
+func groupImports(src []byte) ([]byte, error) {
+	lines := strings.Split(string(src), "\n")
+
+	start := -1
+	for i, line := range lines {
+		if line == "import (" {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return src, nil
+	}
+
+	end := start + 1
+	for lines[end] != ")" {
+		end++
+	}
+
+	var std, other []string
+	for _, line := range lines[start+1 : end] {
+		path := strings.TrimSpace(line)
+		if i := strings.IndexByte(path, '"'); i >= 0 {
+			path = path[i+1:]
+		}
+
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, line)
+		} else {
+			std = append(std, line)
+		}
+	}
+
+	imports := std
+	if len(std) > 0 && len(other) > 0 {
+		imports = append(imports, "")
+	}
+	imports = append(imports, other...)
+
+	grouped := make([]string, 0, len(lines)+1)
+	grouped = append(grouped, lines[:start+1]...)
+	grouped = append(grouped, imports...)
+	grouped = append(grouped, lines[end:]...)
+
+	return format.Source([]byte(strings.Join(grouped, "\n")))
+}
+
+// newFile returns a new jen.File for the package at path, which is marked as
+// being generated.
+func newFile(path, name string) *jen.File {
+	file := jen.NewFilePathName(path, name)
+	file.HeaderComment(HeaderComment)
+	file.ImportNames(map[string]string{
+		encodingPackage: "encoding",
+		maybePackage:    "maybe",
+		messagePackage:  "message",
+		parserPackage:   "parser",
+		writerPackage:   "writer",
+	})
+
+	return file
+}
+
+type paramInfo struct {
+	Param     *Param
+	Mapper    *Mapper
+	Type      *TypeSpec
+	FieldInfo *FieldInfo
+}
+
+// messageInfo contains a Message together with all information derived from
+// it, which is required by the generators.
+type messageInfo struct {
+	Message *Message
+
+	TypeName            string
+	TypeLetter          string
+	FlagTypeName        string
+	ConstructorTypeName string
+
+	PositionalParams []paramInfo
+	NamedParams      []paramInfo
+}
+
+func prepareMessage(message *Message) (*messageInfo, error) {
+	err := Validate([]*Message{message})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &messageInfo{
+		Message:             message,
+		TypeName:            message.Command + "Content",
+		TypeLetter:          strings.ToLower(message.Command[0:1]),
+		FlagTypeName:        message.Command + "Flag",
+		ConstructorTypeName: message.Command + "ContentConstructor",
+	}
+
+	info.PositionalParams, err = prepareParams(message, message.PositionalParams)
+	if err != nil {
+		return nil, err
+	}
+
+	info.NamedParams, err = prepareParams(message, message.NamedParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func prepareParams(message *Message, params []*Param) ([]paramInfo, error) {
+	paramInfos := make([]paramInfo, 0, len(params))
+
+	for _, param := range params {
+		typeSpec, err := TypeSpecFromName(param.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "type resolution failed for type name %s specified by "+
+				"param %s of message %s", param.Type, param.Name, message.Command)
+		}
+		mapper, err := ResolveMapperFromParam(param)
+		if err != nil {
+			return nil, errors.Wrapf(err, "mapper resolution failed for param %s of message %s "+
+				"with type %s", param.Name, message.Command, param.Type)
+		}
+
+		ctx := Context{
+			Message: message,
+			Param:   param,
+			Mapper:  mapper,
+			Type:    typeSpec,
+		}
+
+		paramInfos = append(paramInfos, paramInfo{
+			Param:     param,
+			Mapper:    mapper,
+			Type:      typeSpec,
+			FieldInfo: mapper.ComposeFieldInfo(&ctx),
+		})
+	}
+
+	return paramInfos, nil
+}
+
+func (m *messageInfo) createContext(param paramInfo) Context {
+	return Context{
+		Message: m.Message,
+		Param:   param.Param,
+		Mapper:  param.Mapper,
+		Type:    param.Type,
+	}
+}
+
+// hasDynamicPositional returns true if the last positional param has a
+// dynamic multiplicity, i.e. consumes all remaining positional parameters.
+func (m *messageInfo) hasDynamicPositional() bool {
+	return len(m.PositionalParams) > 0 &&
+		m.PositionalParams[len(m.PositionalParams)-1].FieldInfo.Multiplicity == MultiplicityDynamic
+}
+
+// docComment formats text as a line comment, wrapping it so that lines do
+// not exceed 79 characters. The result is rendered verbatim by jen.Comment.
+func docComment(text string) string {
+	const width = 79 - len("// ")
+
+	var lines []string
+	var line string
+
+	for _, word := range strings.Fields(text) {
+		if len(line) > 0 && len(line)+1+len(word) > width {
+			lines = append(lines, "// "+line)
+			line = ""
+		}
+
+		if len(line) > 0 {
+			line += " "
+		}
+		line += word
+	}
+	lines = append(lines, "// "+line)
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,116 @@
+package generator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/generator"
+)
+
+var _ = Describe("Generator", func() {
+	var tmpDir string
+	var g generator.Generator
+
+	BeforeEach(func() {
+		messages, err := generator.LoadSpecFile("spec/base.yaml")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		tmpDir, err = ioutil.TempDir("", "adclgen")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		g = generator.Generator{
+			Messages:   messages,
+			MessageDir: filepath.Join(tmpDir, "message"),
+			ParserDir:  filepath.Join(tmpDir, "parser"),
+			WriterDir:  filepath.Join(tmpDir, "writer"),
+		}
+
+		for _, dir := range []string{g.MessageDir, g.ParserDir, g.WriterDir} {
+			Ω(os.Mkdir(dir, 0755)).Should(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Ω(os.RemoveAll(tmpDir)).Should(Succeed())
+	})
+
+	It("generates the checked-in files (run go generate in protocol/message if this fails)", func() {
+		Ω(g.Generate()).Should(Succeed())
+
+		for _, pkg := range []string{"message", "parser", "writer"} {
+			files, err := ioutil.ReadDir(filepath.Join(tmpDir, pkg))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(files).ShouldNot(BeEmpty())
+
+			for _, file := range files {
+				generated, err := ioutil.ReadFile(filepath.Join(tmpDir, pkg, file.Name()))
+				Ω(err).ShouldNot(HaveOccurred())
+
+				checkedIn, err := ioutil.ReadFile(filepath.Join("..", pkg, file.Name()))
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(string(checkedIn)).Should(Equal(string(generated)), "%s/%s is outdated", pkg, file.Name())
+			}
+		}
+	})
+
+	It("generates a file per message and package", func() {
+		g.Messages = g.Messages[:1]
+
+		Ω(g.Generate()).Should(Succeed())
+
+		Ω(filepath.Join(g.MessageDir, "content_sta.go")).Should(BeAnExistingFile())
+		Ω(filepath.Join(g.ParserDir, "parse_sta.go")).Should(BeAnExistingFile())
+		Ω(filepath.Join(g.WriterDir, "write_sta.go")).Should(BeAnExistingFile())
+		Ω(filepath.Join(g.MessageDir, "content_sup.go")).ShouldNot(BeAnExistingFile())
+	})
+
+	It("rejects params with an unknown type", func() {
+		g.Messages = []*generator.Message{
+			{
+				Command: "XYZ",
+				Name:    "Unknown",
+				PositionalParams: []*generator.Param{
+					{
+						Mode:     generator.ParamModePositional,
+						Name:     "Value",
+						Type:     "complex",
+						Required: true,
+					},
+				},
+			},
+		}
+
+		err := g.Generate()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring(`XYZ: positional Value: type "complex"`))
+	})
+
+	It("rejects positional params after a param with dynamic multiplicity", func() {
+		g.Messages = []*generator.Message{
+			{
+				Command: "XYZ",
+				Name:    "Unknown",
+				PositionalParams: []*generator.Param{
+					{
+						Mode: generator.ParamModePositional,
+						Name: "FeatureOps",
+						Type: "featureops",
+					},
+					{
+						Mode:     generator.ParamModePositional,
+						Name:     "Value",
+						Type:     "string",
+						Required: true,
+					},
+				},
+			},
+		}
+
+		Ω(g.Generate()).ShouldNot(Succeed())
+	})
+})
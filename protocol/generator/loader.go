@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ErrUnknownSpecFormat is returned when the format of a specification file
+// cannot be determined from its file name.
+var ErrUnknownSpecFormat = errors.New("unknown specification format, expected a .yaml, .yml or .json file")
+
+// SpecFormat is the encoding of a specification file.
+type SpecFormat int
+
+const (
+	SpecFormatYAML SpecFormat = iota
+	SpecFormatJSON
+)
+
+// SpecFormatFromFilename returns the SpecFormat associated with the extension
+// of filename.
+func SpecFormatFromFilename(filename string) (SpecFormat, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return SpecFormatYAML, nil
+	case ".json":
+		return SpecFormatJSON, nil
+	default:
+		return 0, ErrUnknownSpecFormat
+	}
+}
+
+// Spec is the content of a specification file. Example (YAML):
+//
+//     messages:
+//       - command: SID
+//         name: Session ID
+//         positional:
+//           - name: SID
+//             type: base32
+//             required: true
+//             reference: BASE
+//       - command: INF
+//         name: Info
+//         named:
+//           - name: SU
+//             type: stringlist
+//             comment: Comma-separated list of feature FOURCC’s.
+//         flags:
+//           - names: [LC]
+//             reference: EXT § 3.13 LC - Locale specification (EXT v1.0.8)
+//
+// Params listed under positional are positional params, params listed under
+// named are named params.
+type Spec struct {
+	Messages []*Message `json:"messages" yaml:"messages"`
+}
+
+// LoadSpec reads a specification in format from r and validates it. Unknown
+// keys are rejected.
+func LoadSpec(r io.Reader, format SpecFormat) ([]*Message, error) {
+	var spec Spec
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case SpecFormatYAML:
+		err = yaml.UnmarshalStrict(data, &spec)
+	case SpecFormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&spec)
+	default:
+		err = ErrUnknownSpecFormat
+	}
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "decoding specification failed")
+	}
+
+	for _, message := range spec.Messages {
+		if message == nil {
+			return nil, errors.New("decoding specification failed: empty message")
+		}
+
+		for _, param := range message.PositionalParams {
+			if param == nil {
+				return nil, pkgerrors.Errorf("decoding specification failed: empty positional param in message %s", message.Command)
+			}
+			param.Mode = ParamModePositional
+		}
+		for _, param := range message.NamedParams {
+			if param == nil {
+				return nil, pkgerrors.Errorf("decoding specification failed: empty named param in message %s", message.Command)
+			}
+			param.Mode = ParamModeNamed
+		}
+		for _, flag := range message.Flags {
+			if flag == nil {
+				return nil, pkgerrors.Errorf("decoding specification failed: empty flag in message %s", message.Command)
+			}
+		}
+	}
+
+	err = Validate(spec.Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec.Messages, nil
+}
+
+// LoadSpecFile reads and validates the specification file filename. The
+// format is determined by the file extension.
+func LoadSpecFile(filename string) ([]*Message, error) {
+	format, err := SpecFormatFromFilename(filename)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, filename)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	messages, err := LoadSpec(f, format)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, filename)
+	}
+
+	return messages, nil
+}
+
+// LoadSpecFiles reads and validates all specification files and returns the
+// messages of all of them. Commands must not be specified in more than one
+// file.
+func LoadSpecFiles(filenames ...string) ([]*Message, error) {
+	var messages []*Message
+
+	for _, filename := range filenames {
+		fileMessages, err := LoadSpecFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, fileMessages...)
+	}
+
+	err := Validate(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,247 @@
+package generator_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+
+	"github.com/seoester/adcl/protocol/generator"
+)
+
+const yamlSpec = `
+messages:
+  - command: XAB
+    name: Vendor extension
+    positional:
+      - name: Token
+        type: string
+        required: true
+        reference: EXT § 9.9 XAB
+    named:
+      - name: Flags
+        flag: FL
+        type: stringlist
+      - name: Terms
+        type: searchterms
+        multiplicity: dynamic
+    flags:
+      - names: [AB, CD]
+        reference: Vendor documentation
+`
+
+const jsonSpec = `{
+	"messages": [
+		{
+			"command": "XAB",
+			"name": "Vendor extension",
+			"positional": [
+				{"name": "Token", "type": "string", "required": true}
+			],
+			"named": [
+				{"name": "SI", "type": "int", "comment": "Size in bytes."}
+			]
+		}
+	]
+}`
+
+func validationErrors(err error) generator.ValidationErrors {
+	errs, ok := err.(generator.ValidationErrors)
+	Ω(ok).Should(BeTrue(), "error is not of type ValidationErrors: %v", err)
+	return errs
+}
+
+var _ = Describe("LoadSpec", func() {
+	It("loads YAML specifications", func() {
+		messages, err := generator.LoadSpec(strings.NewReader(yamlSpec), generator.SpecFormatYAML)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(messages).Should(HaveLen(1))
+		Ω(messages[0].Command).Should(Equal("XAB"))
+		Ω(messages[0].Name).Should(Equal("Vendor extension"))
+
+		Ω(messages[0].PositionalParams).Should(HaveLen(1))
+		Ω(*messages[0].PositionalParams[0]).Should(Equal(generator.Param{
+			Mode:      generator.ParamModePositional,
+			Name:      "Token",
+			Type:      "string",
+			Required:  true,
+			Reference: "EXT § 9.9 XAB",
+		}))
+
+		Ω(messages[0].NamedParams).Should(HaveLen(2))
+		Ω(messages[0].NamedParams[0].Mode).Should(Equal(generator.ParamModeNamed))
+		Ω(messages[0].NamedParams[0].FlagName).Should(Equal("FL"))
+		Ω(messages[0].NamedParams[1].Multiplicity).Should(Equal("dynamic"))
+
+		Ω(messages[0].Flags).Should(HaveLen(1))
+		Ω(messages[0].Flags[0].CommentLine()).Should(Equal("AB, CD; Vendor documentation"))
+	})
+
+	It("loads JSON specifications", func() {
+		messages, err := generator.LoadSpec(strings.NewReader(jsonSpec), generator.SpecFormatJSON)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(messages).Should(HaveLen(1))
+		Ω(messages[0].PositionalParams[0].Mode).Should(Equal(generator.ParamModePositional))
+		Ω(messages[0].NamedParams[0].Mode).Should(Equal(generator.ParamModeNamed))
+		Ω(messages[0].NamedParams[0].CommentLines()).Should(Equal([]string{"Size in bytes."}))
+	})
+
+	It("rejects unknown keys", func() {
+		_, err := generator.LoadSpec(strings.NewReader(`
+messages:
+  - command: XAB
+    name: Vendor extension
+    optional: true
+`), generator.SpecFormatYAML)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("optional"))
+
+		_, err = generator.LoadSpec(
+			strings.NewReader(`{"messages": [{"command": "XAB", "name": "X", "mode": 1}]}`),
+			generator.SpecFormatJSON,
+		)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("mode"))
+	})
+
+	It("validates the specification", func() {
+		_, err := generator.LoadSpec(strings.NewReader(`
+messages:
+  - command: xab
+    name: Vendor extension
+    named:
+      - name: SI
+        type: integer
+`), generator.SpecFormatYAML)
+		errs := validationErrors(err)
+
+		Ω(errs).Should(HaveLen(2))
+		Ω(errs[0].Path).Should(Equal("xab: command"))
+		Ω(errs[0].Err).Should(Equal(generator.ErrInvalidCommand))
+		Ω(errs[1].Path).Should(Equal(`xab: named SI: type "integer"`))
+		Ω(errors.Cause(errs[1])).Should(Equal(generator.ErrUnknownTypeName))
+	})
+})
+
+var _ = Describe("LoadSpecFiles", func() {
+	It("rejects commands specified in multiple files", func() {
+		_, err := generator.LoadSpecFiles("spec/base.yaml", "spec/base.yaml")
+		errs := validationErrors(err)
+
+		Ω(errs).ShouldNot(BeEmpty())
+		Ω(errs[0].Err).Should(Equal(generator.ErrDuplicateCommand))
+	})
+
+	It("rejects unknown file extensions", func() {
+		_, err := generator.LoadSpecFile("spec/base.txt")
+		Ω(errors.Cause(err)).Should(Equal(generator.ErrUnknownSpecFormat))
+	})
+})
+
+var _ = Describe("Validate", func() {
+	var message *generator.Message
+
+	BeforeEach(func() {
+		message = &generator.Message{
+			Command: "XAB",
+			Name:    "Vendor extension",
+		}
+	})
+
+	validate := func() generator.ValidationErrors {
+		err := generator.Validate([]*generator.Message{message})
+		if err == nil {
+			return nil
+		}
+		return validationErrors(err)
+	}
+
+	It("accepts valid messages", func() {
+		message.NamedParams = []*generator.Param{
+			{Mode: generator.ParamModeNamed, Name: "SI", Type: "int", Required: true},
+		}
+
+		Ω(validate()).Should(BeEmpty())
+	})
+
+	It("rejects a missing name", func() {
+		message.Name = ""
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(1))
+		Ω(errs[0].Error()).Should(Equal("XAB: name: " + generator.ErrMissingName.Error()))
+	})
+
+	It("rejects invalid and duplicate flag names", func() {
+		message.NamedParams = []*generator.Param{
+			{Mode: generator.ParamModeNamed, Name: "Size", Type: "int"},
+			{Mode: generator.ParamModeNamed, Name: "SI", Type: "int"},
+			{Mode: generator.ParamModeNamed, Name: "Other", FlagName: "SI", Type: "int"},
+		}
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(2))
+		Ω(errs[0].Err).Should(Equal(generator.ErrInvalidFlagName))
+		Ω(errs[1].Err).Should(Equal(generator.ErrDuplicateFlagName))
+	})
+
+	It("rejects duplicate field names", func() {
+		message.PositionalParams = []*generator.Param{
+			{Mode: generator.ParamModePositional, Name: "SI", Type: "int", Required: true},
+		}
+		message.NamedParams = []*generator.Param{
+			{Mode: generator.ParamModeNamed, Name: "SI", Type: "int"},
+		}
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(1))
+		Ω(errs[0].Err).Should(Equal(generator.ErrDuplicateFieldName))
+	})
+
+	It("rejects params with modes not supported by the mapper", func() {
+		message.PositionalParams = []*generator.Param{
+			{Mode: generator.ParamModePositional, Name: "Features", Type: "stringlist"},
+		}
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(1))
+		Ω(errs[0].Err).Should(Equal(generator.ErrModeNotSupported))
+	})
+
+	It("rejects optional positional params", func() {
+		message.PositionalParams = []*generator.Param{
+			{Mode: generator.ParamModePositional, Name: "Token", Type: "string"},
+		}
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(1))
+		Ω(errs[0].Err).Should(Equal(generator.ErrOptionalPositional))
+	})
+
+	It("rejects mismatching multiplicities", func() {
+		message.PositionalParams = []*generator.Param{
+			{Mode: generator.ParamModePositional, Name: "Token", Type: "string", Required: true, Multiplicity: "dynamic"},
+			{Mode: generator.ParamModePositional, Name: "Ops", Type: "featureops", Multiplicity: "many"},
+		}
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(2))
+		Ω(errs[0].Err).Should(Equal(generator.ErrMultiplicityMismatch))
+		Ω(errs[1].Err).Should(Equal(generator.ErrInvalidMultiplicity))
+	})
+
+	It("rejects positional params following a param with dynamic multiplicity", func() {
+		message.PositionalParams = []*generator.Param{
+			{Mode: generator.ParamModePositional, Name: "Ops", Type: "featureops"},
+			{Mode: generator.ParamModePositional, Name: "Token", Type: "string", Required: true},
+		}
+
+		errs := validate()
+		Ω(errs).Should(HaveLen(1))
+		Ω(errs[0].Err).Should(Equal(generator.ErrDynamicNotLast))
+	})
+})
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dave/jennifer/jen"
 )
@@ -25,10 +26,19 @@ func (m Multiplicity) String() string {
 }
 
 type FieldInfo struct {
-	FieldName    jen.Code
+	// FieldName is the name of the exported field holding the interpreted
+	// value.
+	FieldName string
+	// FieldType is the type of the exported field.
 	FieldType    jen.Code
 	FieldIsMaybe bool
-	StrFieldName jen.Code
+	// ValueType is the type of a single value, i.e. the type passed to the
+	// setter of the constructor. For maybe fields, this is the wrapped type,
+	// for fields with a []string Str field, the element type.
+	ValueType jen.Code
+	// StrFieldName is the name of the unexported field holding the raw
+	// parameter value(s).
+	StrFieldName string
 	// StrIsSingular is true if the type of the Str field is string, not
 	// []string. This should always be the case when the field has a static
 	// multiplicity of 1.
@@ -38,6 +48,11 @@ type FieldInfo struct {
 	// StaticMultiplicity is the static multiplicity of the field.
 	// This is only set if Multiplicity is equal to MultiplicityStatic.
 	StaticMultiplicity int
+	// IsSetFunc returns code which evaluates to true if the field is set.
+	// It is only used for fields which are neither maybe types nor have a
+	// []string Str field, but are optional nonetheless. field contains code
+	// to access the field.
+	IsSetFunc func(field jen.Code) jen.Code
 }
 
 // DynamicMultiplicity returns code which evaluates to the multiplicity of the field.
@@ -46,7 +61,7 @@ func (p *FieldInfo) DynamicMultiplicity(ctx *RenderingContext) jen.Code {
 	case MultiplicityStatic:
 		return jen.Lit(p.StaticMultiplicity)
 	case MultiplicityDynamic:
-		return jen.Len(jen.Add(ctx.ContentVar).Dot("").Add(ctx.FieldInfo.StrFieldName))
+		return jen.Len(ctx.StrField())
 	default:
 		panic(
 			fmt.Sprintf("Unknown multiplicity type Multiplicity(%d)", p.Multiplicity),
@@ -54,37 +69,127 @@ func (p *FieldInfo) DynamicMultiplicity(ctx *RenderingContext) jen.Code {
 	}
 }
 
+// SetterName returns the name of the constructor method setting the field.
+// Fields with a []string Str field have an Add... method, which appends a
+// single value.
+func (p *FieldInfo) SetterName() string {
+	if p.StrIsSingular {
+		return "Set" + p.FieldName
+	}
+
+	return "Add" + strings.TrimSuffix(p.FieldName, "s")
+}
+
+// ValueName returns the name of variables and arguments holding a single
+// value of the field. It is the field name in lower camel case, for fields
+// with a []string Str field the trailing s is removed.
+func (p *FieldInfo) ValueName() string {
+	name := p.FieldName
+	if !p.StrIsSingular {
+		name = strings.TrimSuffix(name, "s")
+	}
+	name = toLowerCamelCase(name)
+
+	switch name {
+	case "c", "m", "cnt", "cons", "err", "raw", "io", "net", "strings", "message", "encoding":
+		return name + "Value"
+	default:
+		return name
+	}
+}
+
 type Context struct {
-	Param  *Param
-	Mapper *Mapper
-	Type   *TypeSpec
+	Message *Message
+	Param   *Param
+	Mapper  *Mapper
+	Type    *TypeSpec
 }
 
 type RenderingContext struct {
 	Context
 
-	// ContentVar contains code to access the content struct the field is
-	// contained in.
-	ContentVar jen.Code
 	// FieldInfo contains a FieldInfo instance created prior by the
 	// ComposeFieldInfoFunc of the Mapper.
 	FieldInfo *FieldInfo
+	// ContentVar contains code to access the content struct the field is
+	// contained in.
+	ContentVar jen.Code
+	// ConstructorVar contains code to access the constructor of the content
+	// struct. It is only set when rendering parse functions.
+	ConstructorVar jen.Code
+	// ParamVar contains code to access the Positional or Named parameter
+	// currently processed. It is only set when rendering parse functions.
+	ParamVar jen.Code
+	// WriterVar contains code to access the MessageWriter. It is only set
+	// when rendering write functions.
+	WriterVar jen.Code
 	// ErrorVar contains the code to access an existing error variable. If
 	// none exists, this field is nil.
 	ErrorVar jen.Code
-	// ErrorCheckFunc is a function generating code to check an error
-	// variable. Code to access the error variable has to be passed in.
-	ErrorCheckFunc func(errorVar jen.Code) jen.Code
 }
 
-func (r *RenderingContext) ErrorCheck(errorVar jen.Code) jen.Code {
-	if r.ErrorCheckFunc == nil {
-		panic(
-			fmt.Sprintf("No ErrorCheckFunc defined"),
-		)
+// Field returns code accessing the field in the content struct.
+func (r *RenderingContext) Field() *jen.Statement {
+	return jen.Add(r.ContentVar).Dot(r.FieldInfo.FieldName)
+}
+
+// StrField returns code accessing the Str field in the content struct.
+func (r *RenderingContext) StrField() *jen.Statement {
+	return jen.Add(r.ContentVar).Dot(r.FieldInfo.StrFieldName)
+}
+
+// FieldValue returns code accessing the value of the field, i.e. the Value
+// field for maybe fields.
+func (r *RenderingContext) FieldValue() *jen.Statement {
+	if r.FieldInfo.FieldIsMaybe {
+		return r.Field().Dot("Value")
 	}
 
-	return r.ErrorCheckFunc(errorVar)
+	return r.Field()
+}
+
+// IsSetCond returns code which evaluates to true if the field is set. If the
+// field is always set (i.e. it is required), nil is returned.
+func (r *RenderingContext) IsSetCond() jen.Code {
+	switch {
+	case r.FieldInfo.FieldIsMaybe:
+		return r.Field().Dot("IsSet")
+	case r.FieldInfo.IsSetFunc != nil:
+		return r.FieldInfo.IsSetFunc(r.Field())
+	case !r.FieldInfo.StrIsSingular:
+		return jen.Len(r.Field()).Op(">").Lit(0)
+	default:
+		return nil
+	}
+}
+
+// ValueVar returns the name of the local variable holding a parsed value.
+func (r *RenderingContext) ValueVar() string {
+	return r.FieldInfo.ValueName()
+}
+
+// FlagConst returns code accessing the Flag constant with name.
+func (r *RenderingContext) FlagConst(name string) *jen.Statement {
+	return jen.Qual(messagePackage, r.Message.Command+"Flag"+name)
+}
+
+// SetterCall returns code calling the setter of the constructor with value
+// and the raw parameter.
+func (r *RenderingContext) SetterCall(value jen.Code) jen.Code {
+	return jen.Add(r.ConstructorVar).Dot(r.FieldInfo.SetterName()).
+		Call(value, jen.Add(r.ParamVar).Dot("Raw"))
+}
+
+// ErrorCheck returns code returning from the function if ErrorVar is not
+// nil.
+func (r *RenderingContext) ErrorCheck() jen.Code {
+	if r.ErrorVar == nil {
+		panic("No ErrorVar defined")
+	}
+
+	return jen.If(jen.Add(r.ErrorVar).Op("!=").Nil()).Block(
+		jen.Return(),
+	)
 }
 
 type TypeSpec struct {
@@ -93,28 +198,46 @@ type TypeSpec struct {
 	DefaultMapper *Mapper
 }
 
+// Mapper is responsible for generating code handling a logical parameter:
+// the struct field(s), parsing and writing.
 type Mapper struct {
 	Name string
 
 	ComposeFieldInfoFunc func(ctx *Context) *FieldInfo
 
-	Parser  ParserSpec
-	Builder BuilderSpec
+	Parser ParserSpec
+	Writer WriterSpec
 }
 
 func (m Mapper) ComposeFieldInfo(ctx *Context) *FieldInfo {
 	return m.ComposeFieldInfoFunc(ctx)
 }
 
+// ParamNames returns the names of the named parameters handled by the
+// mapper for the parameter in ctx.
+func (m Mapper) ParamNames(ctx *Context) []string {
+	return m.Parser.Named.ParamNames(ctx)
+}
+
 type ParserSpec struct {
 	Positional PositionalParserSpec
 	Named      NamedParserSpec
 }
 
+// ModeParserSpec returns the parser spec for mode.
+func (p ParserSpec) ModeParserSpec(mode ParamMode) ModeParserSpecBase {
+	if mode == ParamModeNamed {
+		return p.Named.ModeParserSpecBase
+	}
+
+	return p.Positional.ModeParserSpecBase
+}
+
 type ModeParserSpecBase struct {
-	Available           bool
-	InitialiseFieldFunc func(ctx *RenderingContext) jen.Code
-	FinaliseFieldFunc   func(ctx *RenderingContext) jen.Code
+	Available bool
+	// ParseFunc returns statements interpreting the parameter accessible
+	// through ctx.ParamVar and passing the value to the constructor.
+	ParseFunc func(ctx *RenderingContext) []jen.Code
 }
 
 func (m *ModeParserSpecBase) checkAvailable(ctx *Context) {
@@ -129,112 +252,80 @@ func (m *ModeParserSpecBase) checkAvailable(ctx *Context) {
 	}
 }
 
-func (m ModeParserSpecBase) InitialiseField(ctx *RenderingContext) jen.Code {
+func (m ModeParserSpecBase) Parse(ctx *RenderingContext) []jen.Code {
 	m.checkAvailable(&ctx.Context)
 
-	if m.InitialiseFieldFunc == nil {
-		return nil
-	}
-
-	return m.InitialiseFieldFunc(ctx)
-}
-
-func (m ModeParserSpecBase) FinaliseField(ctx *RenderingContext) jen.Code {
-	m.checkAvailable(&ctx.Context)
-
-	if m.FinaliseFieldFunc == nil {
-		return nil
+	if m.ParseFunc == nil {
+		panic(
+			fmt.Sprintf(
+				"The mapper %s has an incomplete spec for parsing in mode %s: ParseFunc missing",
+				ctx.Mapper.Name,
+				ctx.Param.Mode,
+			),
+		)
 	}
 
-	return m.FinaliseFieldFunc(ctx)
+	return m.ParseFunc(ctx)
 }
 
 type PositionalParserSpec struct {
 	ModeParserSpecBase
+}
 
-	DynamicProcessCondFunc        func(ctx *RenderingContext, value jen.Code) jen.Code
-	DynamicProcessCondPrepareFunc func(ctx *RenderingContext, value jen.Code) jen.Code
-	DynamicProcessCondCleanupFunc func(ctx *RenderingContext, value jen.Code) jen.Code
+type NamedParserSpec struct {
+	ModeParserSpecBase
 
-	ProcessFieldValueFunc func(ctx *RenderingContext, value jen.Code) jen.Code
+	// ParamNamesFunc returns the names of the named parameters handled.
+	// Usually, this is a single name.
+	ParamNamesFunc func(ctx *Context) []string
 }
 
-func (p PositionalParserSpec) DynamicProcessCond(ctx *RenderingContext, value jen.Code) jen.Code {
-	p.checkAvailable(&ctx.Context)
+func (n NamedParserSpec) ParamNames(ctx *Context) []string {
+	n.checkAvailable(ctx)
 
-	if p.DynamicProcessCondFunc == nil {
+	if n.ParamNamesFunc == nil {
 		panic(
 			fmt.Sprintf(
-				"The mapper %s has no spec available for parsing with dynamic multiplicity",
+				"The mapper %s has an incomplete spec for parsing in named mode: ParamNamesFunc missing",
 				ctx.Mapper.Name,
 			),
 		)
 	}
 
-	return p.DynamicProcessCondFunc(ctx, value)
+	return n.ParamNamesFunc(ctx)
 }
 
-func (p PositionalParserSpec) DynamicProcessCondPrepare(ctx *RenderingContext, value jen.Code) jen.Code {
-	p.checkAvailable(&ctx.Context)
-
-	if p.DynamicProcessCondPrepareFunc == nil {
-		return nil
-	}
-
-	return p.DynamicProcessCondPrepareFunc(ctx, value)
+type WriterSpec struct {
+	Positional ModeWriterSpec
+	Named      ModeWriterSpec
 }
 
-func (p PositionalParserSpec) DynamicProcessCondCleanup(ctx *RenderingContext, value jen.Code) jen.Code {
-	p.checkAvailable(&ctx.Context)
-
-	if p.DynamicProcessCondCleanupFunc == nil {
-		return nil
+// ModeWriterSpec returns the writer spec for mode.
+func (w WriterSpec) ModeWriterSpec(mode ParamMode) ModeWriterSpec {
+	if mode == ParamModeNamed {
+		return w.Named
 	}
 
-	return p.DynamicProcessCondCleanupFunc(ctx, value)
+	return w.Positional
 }
 
-func (p PositionalParserSpec) ProcessFieldValue(ctx *RenderingContext, value jen.Code) jen.Code {
-	p.checkAvailable(&ctx.Context)
-
-	if p.ProcessFieldValueFunc == nil {
-		return nil
-	}
-
-	return p.ProcessFieldValueFunc(ctx, value)
-}
-
-type NamedParserSpec struct {
-	ModeParserSpecBase
-
-	ParamNameFunc func(ctx *Context) string
-
-	ProcessFieldValueFunc func(ctx *RenderingContext, value jen.Code) jen.Code
+type ModeWriterSpec struct {
+	Available bool
+	// WriteFunc returns statements writing the field to ctx.WriterVar. The
+	// statements are only executed if the field is set.
+	WriteFunc func(ctx *RenderingContext) []jen.Code
 }
 
-func (n NamedParserSpec) ParamName(ctx *Context) string {
-	n.checkAvailable(ctx)
-
-	if n.ParamNameFunc == nil {
+func (m ModeWriterSpec) Write(ctx *RenderingContext) []jen.Code {
+	if !m.Available || m.WriteFunc == nil {
 		panic(
 			fmt.Sprintf(
-				"The mapper %s has an incomplete spec for parsing in named mode: ParamNameFunc missing",
+				"The mapper %s has no spec available for writing in mode %s",
 				ctx.Mapper.Name,
+				ctx.Param.Mode,
 			),
 		)
 	}
 
-	return n.ParamNameFunc(ctx)
-}
-
-func (n NamedParserSpec) ProcessFieldValue(ctx *RenderingContext, value jen.Code) jen.Code {
-	n.checkAvailable(&ctx.Context)
-
-	if n.ProcessFieldValueFunc == nil {
-		return nil
-	}
-
-	return n.ProcessFieldValueFunc(ctx, value)
+	return m.WriteFunc(ctx)
 }
-
-type BuilderSpec struct{}
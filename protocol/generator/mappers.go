@@ -11,6 +11,9 @@ import (
 const (
 	encodingPackage = "github.com/seoester/adcl/protocol/encoding"
 	maybePackage    = "github.com/seoester/adcl/protocol/maybe"
+	messagePackage  = "github.com/seoester/adcl/protocol/message"
+	parserPackage   = "github.com/seoester/adcl/protocol/parser"
+	writerPackage   = "github.com/seoester/adcl/protocol/writer"
 )
 
 // BasicMapper is a mapper performing straight-forward interpretation for
@@ -26,44 +29,266 @@ var BasicMapper = &Mapper{
 	Name: "basic",
 	ComposeFieldInfoFunc: func(ctx *Context) *FieldInfo {
 		return &FieldInfo{
-			FieldName:          jen.Id(ctx.Param.Name),
+			FieldName:          ctx.Param.Name,
 			FieldType:          basicGolangTypeFromParam(ctx.Param),
 			FieldIsMaybe:       !ctx.Param.Required,
-			StrFieldName:       jen.Id(toLowerCamelCase(ctx.Param.Name) + "Str"),
+			ValueType:          basicGolangValueTypeFromParam(ctx.Param),
+			StrFieldName:       toLowerCamelCase(ctx.Param.Name) + "Str",
 			StrIsSingular:      true,
 			Multiplicity:       MultiplicityStatic,
 			StaticMultiplicity: 1,
 		}
 	},
 	Parser: ParserSpec{
-		PositionalParserSpec{
+		Positional: PositionalParserSpec{
 			ModeParserSpecBase: ModeParserSpecBase{
 				Available: true,
+				ParseFunc: basicParse,
 			},
-			ProcessFieldValueFunc: func(ctx *RenderingContext, value jen.Code) jen.Code {
-				if ctx.FieldInfo.FieldIsMaybe {
-					return jen.Add(ctx.ContentVar).Dot("").Add(ctx.FieldInfo.FieldName).
-						Dot("").Id("Set").Call(jen.Add(value))
-				} else {
-					return jen.Add(ctx.ContentVar).Dot("").Add(ctx.FieldInfo.FieldName).
-						Op("=").Add(value).Call()
+		},
+		Named: NamedParserSpec{
+			ModeParserSpecBase: ModeParserSpecBase{
+				Available: true,
+				ParseFunc: basicParse,
+			},
+			ParamNamesFunc: func(ctx *Context) []string {
+				return []string{flagNameFromParam(ctx.Param)}
+			},
+		},
+	},
+	Writer: WriterSpec{
+		Positional: ModeWriterSpec{
+			Available: true,
+			WriteFunc: func(ctx *RenderingContext) []jen.Code {
+				return []jen.Code{
+					jen.Add(ctx.ErrorVar).Op("=").Add(ctx.WriterVar).
+						Dot("WritePositional" + basicKindFromParam(ctx.Param)).
+						Call(ctx.FieldValue()),
 				}
 			},
 		},
-		NamedParserSpec{
+		Named: ModeWriterSpec{
+			Available: true,
+			WriteFunc: func(ctx *RenderingContext) []jen.Code {
+				return []jen.Code{
+					jen.Add(ctx.ErrorVar).Op("=").Add(ctx.WriterVar).
+						Dot("WriteNamed"+basicKindFromParam(ctx.Param)).
+						Call(
+							jen.String().Call(ctx.FlagConst(flagNameFromParam(ctx.Param))),
+							ctx.FieldValue(),
+						),
+				}
+			},
+		},
+	},
+}
+
+func basicParse(ctx *RenderingContext) []jen.Code {
+	value := ctx.ValueVar()
+
+	return []jen.Code{
+		jen.Var().Id(value).Add(ctx.FieldInfo.ValueType),
+		jen.List(jen.Id(value), ctx.ErrorVar).Op("=").Add(ctx.ParamVar).
+			Dot("Value" + basicKindFromParam(ctx.Param)).Call(),
+		ctx.ErrorCheck(),
+		ctx.SetterCall(jen.Id(value)),
+	}
+}
+
+// StatusCodeMapper is a mapper for the status code of STA messages. It
+// supports positional parameters of type statuscode only.
+var StatusCodeMapper = &Mapper{
+	Name: "statuscode",
+	ComposeFieldInfoFunc: func(ctx *Context) *FieldInfo {
+		return &FieldInfo{
+			FieldName:          ctx.Param.Name,
+			FieldType:          jen.Qual(messagePackage, "StatusCode"),
+			ValueType:          jen.Qual(messagePackage, "StatusCode"),
+			StrFieldName:       toLowerCamelCase(ctx.Param.Name) + "Str",
+			StrIsSingular:      true,
+			Multiplicity:       MultiplicityStatic,
+			StaticMultiplicity: 1,
+		}
+	},
+	Parser: ParserSpec{
+		Positional: PositionalParserSpec{
 			ModeParserSpecBase: ModeParserSpecBase{
 				Available: true,
+				ParseFunc: func(ctx *RenderingContext) []jen.Code {
+					value := ctx.ValueVar()
+
+					return []jen.Code{
+						jen.Var().Id(value).Add(ctx.FieldInfo.ValueType),
+						jen.List(jen.Id(value), ctx.ErrorVar).Op("=").
+							Qual(messagePackage, "ParseStatusCode").
+							Call(jen.Add(ctx.ParamVar).Dot("RawValue").Call()),
+						ctx.ErrorCheck(),
+						ctx.SetterCall(jen.Id(value)),
+					}
+				},
 			},
-			ParamNameFunc: func(ctx *Context) string {
-				return flagNameFromParam(ctx.Param)
+		},
+	},
+	Writer: WriterSpec{
+		Positional: ModeWriterSpec{
+			Available: true,
+			WriteFunc: func(ctx *RenderingContext) []jen.Code {
+				return []jen.Code{
+					jen.Add(ctx.ErrorVar).Op("=").Id("writeStatusCode").
+						Call(ctx.WriterVar, ctx.FieldValue()),
+				}
+			},
+		},
+	},
+}
+
+// StringListMapper is a mapper for comma-separated lists of strings, such as
+// the SU field of INF. It supports named parameters of type stringlist only.
+// The field is considered set if the list is not empty.
+var StringListMapper = &Mapper{
+	Name: "stringlist",
+	ComposeFieldInfoFunc: func(ctx *Context) *FieldInfo {
+		return &FieldInfo{
+			FieldName:          ctx.Param.Name,
+			FieldType:          jen.Index().String(),
+			ValueType:          jen.Index().String(),
+			StrFieldName:       toLowerCamelCase(ctx.Param.Name) + "Str",
+			StrIsSingular:      true,
+			Multiplicity:       MultiplicityStatic,
+			StaticMultiplicity: 1,
+			IsSetFunc: func(field jen.Code) jen.Code {
+				return jen.Len(field).Op(">").Lit(0)
 			},
-			ProcessFieldValueFunc: func(ctx *RenderingContext, value jen.Code) jen.Code {
-				if ctx.FieldInfo.FieldIsMaybe {
-					return jen.Add(ctx.ContentVar).Dot("").Add(ctx.FieldInfo.FieldName).
-						Dot("").Id("Set").Call(jen.Add(value))
-				} else {
-					return jen.Add(ctx.ContentVar).Dot("").Add(ctx.FieldInfo.FieldName).
-						Op("=").Add(value).Call()
+		}
+	},
+	Parser: ParserSpec{
+		Named: NamedParserSpec{
+			ModeParserSpecBase: ModeParserSpecBase{
+				Available: true,
+				ParseFunc: func(ctx *RenderingContext) []jen.Code {
+					value := ctx.ValueVar()
+
+					return []jen.Code{
+						jen.Var().Id(value).String(),
+						jen.List(jen.Id(value), ctx.ErrorVar).Op("=").Add(ctx.ParamVar).
+							Dot("ValueString").Call(),
+						ctx.ErrorCheck(),
+						ctx.SetterCall(
+							jen.Qual("strings", "Split").Call(jen.Id(value), jen.Lit(",")),
+						),
+					}
+				},
+			},
+			ParamNamesFunc: func(ctx *Context) []string {
+				return []string{flagNameFromParam(ctx.Param)}
+			},
+		},
+	},
+	Writer: WriterSpec{
+		Named: ModeWriterSpec{
+			Available: true,
+			WriteFunc: func(ctx *RenderingContext) []jen.Code {
+				return []jen.Code{
+					jen.Add(ctx.ErrorVar).Op("=").Add(ctx.WriterVar).
+						Dot("WriteNamedString").
+						Call(
+							jen.String().Call(ctx.FlagConst(flagNameFromParam(ctx.Param))),
+							jen.Qual("strings", "Join").Call(ctx.FieldValue(), jen.Lit(",")),
+						),
+				}
+			},
+		},
+	},
+}
+
+// FeatureOpsMapper is a mapper for the feature operations (ADxxxx, RMxxxx)
+// of SUP messages. It supports positional parameters of type featureops
+// only. The parameter consumes all remaining positional parameters.
+var FeatureOpsMapper = &Mapper{
+	Name: "featureops",
+	ComposeFieldInfoFunc: func(ctx *Context) *FieldInfo {
+		return &FieldInfo{
+			FieldName:     ctx.Param.Name,
+			FieldType:     jen.Index().Qual(messagePackage, "FeatureOp"),
+			ValueType:     jen.Qual(messagePackage, "FeatureOp"),
+			StrFieldName:  toLowerCamelCase(ctx.Param.Name) + "Strs",
+			StrIsSingular: false,
+			Multiplicity:  MultiplicityDynamic,
+		}
+	},
+	Parser: ParserSpec{
+		Positional: PositionalParserSpec{
+			ModeParserSpecBase: ModeParserSpecBase{
+				Available: true,
+				ParseFunc: func(ctx *RenderingContext) []jen.Code {
+					return []jen.Code{
+						jen.Var().Id(ctx.ValueVar()).Add(ctx.FieldInfo.ValueType),
+						jen.List(jen.Id(ctx.ValueVar()), ctx.ErrorVar).Op("=").
+							Qual(parserPackage, "ParseSUPFeatureOp").
+							Call(jen.Add(ctx.ParamVar).Dot("RawValue").Call()),
+						ctx.ErrorCheck(),
+						ctx.SetterCall(jen.Id(ctx.ValueVar())),
+					}
+				},
+			},
+		},
+	},
+	Writer: WriterSpec{
+		Positional: ModeWriterSpec{
+			Available: true,
+			WriteFunc: func(ctx *RenderingContext) []jen.Code {
+				return []jen.Code{
+					jen.Add(ctx.ErrorVar).Op("=").Id("writeSUPFeatureOps").
+						Call(ctx.WriterVar, ctx.FieldValue()),
+				}
+			},
+		},
+	},
+}
+
+// SearchTermsMapper is a mapper for the search terms of SCH messages, which
+// are transmitted as the named parameters AN, NO and EX. Each of them may
+// occur multiple times. It supports named parameters of type searchterms
+// only.
+var SearchTermsMapper = &Mapper{
+	Name: "searchterms",
+	ComposeFieldInfoFunc: func(ctx *Context) *FieldInfo {
+		return &FieldInfo{
+			FieldName:     ctx.Param.Name,
+			FieldType:     jen.Index().Qual(messagePackage, "SearchTerm"),
+			ValueType:     jen.Qual(messagePackage, "SearchTerm"),
+			StrFieldName:  toLowerCamelCase(ctx.Param.Name) + "Strs",
+			StrIsSingular: false,
+			Multiplicity:  MultiplicityDynamic,
+		}
+	},
+	Parser: ParserSpec{
+		Named: NamedParserSpec{
+			ModeParserSpecBase: ModeParserSpecBase{
+				Available: true,
+				ParseFunc: func(ctx *RenderingContext) []jen.Code {
+					return []jen.Code{
+						jen.Var().Id(ctx.ValueVar()).Add(ctx.FieldInfo.ValueType),
+						jen.List(jen.Id(ctx.ValueVar()), ctx.ErrorVar).Op("=").
+							Qual(parserPackage, "ParseSearchTerm").
+							Call(ctx.ParamVar),
+						ctx.ErrorCheck(),
+						ctx.SetterCall(jen.Id(ctx.ValueVar())),
+					}
+				},
+			},
+			ParamNamesFunc: func(ctx *Context) []string {
+				return []string{"AN", "NO", "EX"}
+			},
+		},
+	},
+	Writer: WriterSpec{
+		Named: ModeWriterSpec{
+			Available: true,
+			WriteFunc: func(ctx *RenderingContext) []jen.Code {
+				return []jen.Code{
+					jen.Add(ctx.ErrorVar).Op("=").Id("writeSearchTerms").
+						Call(ctx.WriterVar, ctx.FieldValue()),
 				}
 			},
 		},
@@ -78,40 +303,61 @@ func flagNameFromParam(param *Param) string {
 	}
 }
 
+// basicKindFromParam returns the suffix of the Value... methods of
+// parser.Positional and parser.Named as well as of the Write... methods of
+// writer.MessageWriter for the type of param.
+func basicKindFromParam(param *Param) string {
+	switch param.Type {
+	case "int":
+		return "Int"
+	case "float":
+		return "Float64"
+	case "string":
+		return "String"
+	case "base32":
+		return "Base32Value"
+	case "ip":
+		return "IP"
+	default:
+		panic(fmt.Sprintf("Parameter type %s not known to basic mapper", param.Type))
+	}
+}
+
+func basicGolangValueTypeFromParam(param *Param) jen.Code {
+	switch param.Type {
+	case "int":
+		return jen.Int()
+	case "float":
+		return jen.Float64()
+	case "string":
+		return jen.String()
+	case "base32":
+		return jen.Op("*").Qual(encodingPackage, "Base32Value")
+	case "ip":
+		return jen.Qual("net", "IP")
+	default:
+		panic(fmt.Sprintf("Parameter type %s not known to basic mapper", param.Type))
+	}
+}
+
 func basicGolangTypeFromParam(param *Param) jen.Code {
+	if param.Required {
+		return basicGolangValueTypeFromParam(param)
+	}
+
 	switch param.Type {
 	case "int":
-		if param.Required {
-			return jen.Int()
-		} else {
-			return jen.Qual(maybePackage, "Int")
-		}
+		return jen.Qual(maybePackage, "Int")
 	case "float":
-		if param.Required {
-			return jen.Float64()
-		} else {
-			return jen.Qual(maybePackage, "Float64")
-		}
+		return jen.Qual(maybePackage, "Float64")
 	case "string":
-		if param.Required {
-			return jen.String()
-		} else {
-			return jen.Qual(maybePackage, "String")
-		}
+		return jen.Qual(maybePackage, "String")
 	case "base32":
-		if param.Required {
-			return jen.Op("*").Qual(encodingPackage, "Base32Value")
-		} else {
-			return jen.Qual(maybePackage, "Base32Value")
-		}
+		return jen.Qual(maybePackage, "Base32Value")
 	case "ip":
-		if param.Required {
-			return jen.Qual("net", "IP")
-		} else {
-			return jen.Qual(maybePackage, "IP")
-		}
+		return jen.Qual(maybePackage, "IP")
 	default:
-		panic(fmt.Sprintf("Parameter type %s not known to basic mapper"))
+		panic(fmt.Sprintf("Parameter type %s not known to basic mapper", param.Type))
 	}
 }
 
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"strings"
 )
 
 type ParamMode int
@@ -22,23 +23,81 @@ func (p ParamMode) String() string {
 	}
 }
 
+// Message describes the parameters of an ADC command. A ???Content struct
+// type as well as parse and write functions are generated for each Message.
 type Message struct {
-	Command          string
-	PositionalParams []*Param
-	NamedParams      []*Param
-	Flags            []*Flag
+	// Command is the three-letter name of the command, e.g. INF.
+	Command string `json:"command" yaml:"command"`
+	// Name is the human-readable name of the command, e.g. Info.
+	Name             string   `json:"name" yaml:"name"`
+	PositionalParams []*Param `json:"positional,omitempty" yaml:"positional,omitempty"`
+	NamedParams      []*Param `json:"named,omitempty" yaml:"named,omitempty"`
+	// Flags lists known additional flags, which are not interpreted but
+	// mentioned in the documentation of the generated struct type.
+	Flags []*Flag `json:"flags,omitempty" yaml:"flags,omitempty"`
 }
 
 type Param struct {
-	Mode     ParamMode
-	Name     string
-	FlagName string
-	Type     string
-	Mapper   string
-	Required bool
-	Comment  string
+	// Mode is whether the param is positional or named. It is not part of
+	// specification files, but derived from the list the param is contained
+	// in.
+	Mode ParamMode `json:"-" yaml:"-"`
+	// Name is the name of the field in the generated struct type.
+	Name string `json:"name" yaml:"name"`
+	// FlagName is the name of the named parameter. If empty, Name is used.
+	FlagName string `json:"flag,omitempty" yaml:"flag,omitempty"`
+	Type     string `json:"type" yaml:"type"`
+	// Mapper overrides the default mapper of Type.
+	Mapper   string `json:"mapper,omitempty" yaml:"mapper,omitempty"`
+	Required bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	// Multiplicity optionally states the expected multiplicity (static or
+	// dynamic) of the param. If set, it has to match the multiplicity
+	// determined by the mapper.
+	Multiplicity string `json:"multiplicity,omitempty" yaml:"multiplicity,omitempty"`
+	// Reference names the specification the param is specified in, e.g.
+	// BASE.
+	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Comment   string `json:"comment,omitempty" yaml:"comment,omitempty"`
 }
 
+// CommentLines returns the lines of the documentation of the param, i.e. the
+// lines of Comment followed by the reference.
+func (p *Param) CommentLines() []string {
+	var lines []string
+
+	if comment := strings.TrimSpace(p.Comment); len(comment) > 0 {
+		lines = strings.Split(comment, "\n")
+	}
+	if len(p.Reference) > 0 {
+		lines = append(lines, "Specified in "+p.Reference+".")
+	}
+
+	return lines
+}
+
+// Flag is an additional flag known to be used with a message, which is not
+// interpreted.
 type Flag struct {
-	Comment string
+	// Names contains the names of the named parameters.
+	Names []string `json:"names,omitempty" yaml:"names,omitempty"`
+	// Reference names the specification the flags are specified in.
+	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Comment   string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// CommentLine returns the documentation of the flag as single line.
+func (f *Flag) CommentLine() string {
+	var parts []string
+
+	if len(f.Names) > 0 {
+		parts = append(parts, strings.Join(f.Names, ", "))
+	}
+	if len(f.Reference) > 0 {
+		parts = append(parts, f.Reference)
+	}
+	if len(f.Comment) > 0 {
+		parts = append(parts, strings.TrimSpace(f.Comment))
+	}
+
+	return strings.Join(parts, "; ")
 }
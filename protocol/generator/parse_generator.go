@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// ParseGenerator generates the parser.Parse???Content function of a Message.
+type ParseGenerator struct {
+	message *Message
+
+	info *messageInfo
+}
+
+func NewParseGenerator(message *Message) *ParseGenerator {
+	return &ParseGenerator{
+		message: message,
+	}
+}
+
+func (p *ParseGenerator) Generate() (*jen.File, error) {
+	var err error
+
+	p.info, err = prepareMessage(p.message)
+	if err != nil {
+		return nil, err
+	}
+
+	file := newFile(parserPackage, "parser")
+
+	file.Func().Id("Parse"+p.info.TypeName).
+		Params(jen.Id("m").Op("*").Id("MessageReader")).
+		Params(
+			jen.Id("cnt").Qual(messagePackage, p.info.TypeName),
+			jen.Err().Error(),
+		).
+		Block(
+			jen.Err().Op("=").Id("Parse"+p.info.TypeName+"Into").Call(jen.Id("m"), jen.Op("&").Id("cnt")),
+			jen.Return(),
+		)
+
+	file.Line()
+
+	file.Comment(docComment(fmt.Sprintf(
+		"Parse%sInto is like Parse%s, but parses into cnt, which is reset beforehand. Memory allocated by cnt is reused.",
+		p.info.TypeName, p.info.TypeName,
+	)))
+	file.Func().Id("Parse"+p.info.TypeName+"Into").
+		Params(
+			jen.Id("m").Op("*").Id("MessageReader"),
+			jen.Id("cnt").Op("*").Qual(messagePackage, p.info.TypeName),
+		).
+		Params(jen.Err().Error()).
+		BlockFunc(p.generateBody)
+
+	return file, nil
+}
+
+func (p *ParseGenerator) createRenderingContext(param paramInfo, paramVar string) RenderingContext {
+	return RenderingContext{
+		Context:        p.info.createContext(param),
+		FieldInfo:      param.FieldInfo,
+		ContentVar:     jen.Id("cnt"),
+		ConstructorVar: jen.Id("cons"),
+		ParamVar:       jen.Id(paramVar),
+		ErrorVar:       jen.Err(),
+	}
+}
+
+func (p *ParseGenerator) generateBody(group *jen.Group) {
+	dynamicPositional := p.info.hasDynamicPositional()
+
+	group.Id("cnt").Dot("Reset").Call()
+
+	group.Line()
+
+	group.Id("cons").Op(":=").Qual(messagePackage, p.info.ConstructorTypeName).Values(
+		jen.Dict{jen.Id("Content"): jen.Id("cnt")},
+	)
+
+	group.Line()
+
+	if len(p.info.PositionalParams) > 0 {
+		group.Var().Id("positionalParam").Id("Positional")
+	}
+	if !dynamicPositional {
+		group.Var().Id("namedParam").Id("Named")
+	}
+
+	var required []string
+	for _, param := range p.info.NamedParams {
+		if param.Param.Required {
+			required = append(required, "has"+param.FieldInfo.FieldName)
+		}
+	}
+
+	if len(required) > 0 {
+		group.Line()
+		for _, name := range required {
+			group.Var().Id(name).Bool()
+		}
+	}
+
+	for _, param := range p.info.PositionalParams {
+		group.Line()
+
+		ctx := p.createRenderingContext(param, "positionalParam")
+		stmts := param.Mapper.Parser.Positional.Parse(&ctx)
+
+		if param.FieldInfo.Multiplicity == MultiplicityDynamic {
+			group.For().BlockFunc(func(group *jen.Group) {
+				group.List(jen.Id("positionalParam"), jen.Err()).Op("=").
+					Id("m").Dot("ReadPositional").Call()
+				group.If(jen.Err().Op("==").Qual("io", "EOF")).Block(
+					jen.Err().Op("=").Nil(),
+					jen.Break(),
+				).Else().If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(),
+				)
+
+				group.Line()
+
+				for _, stmt := range stmts {
+					group.Add(stmt)
+				}
+			})
+			continue
+		}
+
+		group.List(jen.Id("positionalParam"), jen.Err()).Op("=").
+			Id("m").Dot("ReadPositional").Call()
+		group.If(jen.Err().Op("==").Qual("io", "EOF")).Block(
+			jen.Err().Op("=").Id("ErrIncompleteMessage"),
+			jen.Return(),
+		).Else().If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(),
+		)
+		for _, stmt := range stmts {
+			group.Add(stmt)
+		}
+	}
+
+	if !dynamicPositional {
+		group.Line()
+		group.For().BlockFunc(p.generateNamedLoop)
+	}
+
+	if len(required) > 0 {
+		group.Line()
+
+		var cond jen.Statement
+		for i, name := range required {
+			if i > 0 {
+				cond.Op("||")
+			}
+			cond.Op("!").Id(name)
+		}
+
+		group.If(&cond).Block(
+			jen.Err().Op("=").Id("ErrIncompleteMessage"),
+			jen.Return(),
+		)
+	}
+
+	group.Line()
+	group.Return()
+}
+
+func (p *ParseGenerator) generateNamedLoop(group *jen.Group) {
+	group.List(jen.Id("namedParam"), jen.Err()).Op("=").Id("m").Dot("ReadNamed").Call()
+	group.If(jen.Err().Op("==").Qual("io", "EOF")).Block(
+		jen.Err().Op("=").Nil(),
+		jen.Break(),
+	).Else().If(jen.Err().Op("!=").Nil()).Block(
+		jen.Return(),
+	)
+
+	group.Line()
+
+	setFlag := jen.Id("cons").Dot("SetFlag").Call(
+		jen.Id("namedParam").Dot("Name").Call(),
+		jen.Id("namedParam").Dot("RawValue").Call(),
+	)
+
+	if len(p.info.NamedParams) == 0 {
+		group.Add(setFlag)
+		return
+	}
+
+	group.If(jen.Len(jen.Id("namedParam").Dot("RawValue").Call()).Op("==").Lit(0)).Block(
+		jen.Comment("Empty values are not interpreted, they denote the removal of"),
+		jen.Comment("a parameter (INF) or are meaningless."),
+		jen.Id("cons").Dot("SetFlag").Call(jen.Id("namedParam").Dot("Name").Call(), jen.Lit("")),
+		jen.Continue(),
+	)
+
+	group.Line()
+
+	group.Switch(
+		jen.Qual(messagePackage, p.info.FlagTypeName).Call(jen.Id("namedParam").Dot("Name").Call()),
+	).BlockFunc(func(group *jen.Group) {
+		for _, param := range p.info.NamedParams {
+			ctx := p.createRenderingContext(param, "namedParam")
+
+			var names []jen.Code
+			for _, name := range param.Mapper.ParamNames(&ctx.Context) {
+				names = append(names, ctx.FlagConst(name))
+			}
+
+			stmts := param.Mapper.Parser.Named.Parse(&ctx)
+			if param.Param.Required {
+				stmts = append(stmts, jen.Id("has"+param.FieldInfo.FieldName).Op("=").True())
+			}
+
+			group.Case(names...).Block(stmts...)
+		}
+
+		group.Default().Block(setFlag)
+	})
+}
@@ -1,30 +1,18 @@
 package generator
 
 import (
-	"bytes"
 	"fmt"
-	"strings"
 
 	"github.com/dave/jennifer/jen"
-	"github.com/pkg/errors"
 )
 
-type paramInfo struct {
-	Param     *Param
-	Mapper    *Mapper
-	Type      *TypeSpec
-	FieldInfo *FieldInfo
-}
-
+// StructGenerator generates the message.???Content struct type of a Message
+// together with its Flag constants, its ParamAccessor implementation and its
+// constructor.
 type StructGenerator struct {
 	message *Message
 
-	typeName     string
-	typeLetter   string
-	flagTypeName string
-
-	positionalParams []paramInfo
-	namedParams      []paramInfo
+	info *messageInfo
 }
 
 func NewStructGenerator(message *Message) *StructGenerator {
@@ -33,150 +21,124 @@ func NewStructGenerator(message *Message) *StructGenerator {
 	}
 }
 
-func (s *StructGenerator) Generate() error {
-	err := s.prepare()
-	if err != nil {
-		return err
-	}
-
-	file := s.generateFile()
-
-	buf := bytes.NewBuffer(nil)
-	file.Render(buf)
-
-	fmt.Println(buf.String())
-
-	return nil
-}
-
-func (s *StructGenerator) prepare() error {
+func (s *StructGenerator) Generate() (*jen.File, error) {
 	var err error
 
-	s.positionalParams, err = s.prepareParams(s.message.PositionalParams)
-	if err != nil {
-		return err
-	}
-
-	s.namedParams, err = s.prepareParams(s.message.NamedParams)
+	s.info, err = prepareMessage(s.message)
 	if err != nil {
-		return err
-	}
-
-	s.typeName = s.message.Command + "Content"
-	s.typeLetter = strings.ToLower(s.typeName[0:1])
-	s.flagTypeName = s.message.Command + "Flag"
-
-	return nil
-}
-
-func (s *StructGenerator) prepareParams(params []*Param) ([]paramInfo, error) {
-	paramInfos := make([]paramInfo, 0, len(params))
-
-	for _, param := range params {
-		typeSpec, err := TypeSpecFromName(param.Type)
-		if err != nil {
-			return nil, errors.Wrapf(err, "type resolution failed for type name %s specified by "+
-				"param %s of message %s", param.Name, s.message.Command)
-		}
-		mapper, err := ResolveMapperFromParam(param)
-		if err != nil {
-			return nil, errors.Wrapf(err, "mapper resolution failed for param %s of message %s "+
-				"with type %s", param.Name, s.message.Command)
-		}
-
-		ctx := Context{
-			Param:  param,
-			Mapper: mapper,
-			Type:   typeSpec,
-		}
-
-		paramInfos = append(paramInfos, paramInfo{
-			Param:     param,
-			Mapper:    mapper,
-			Type:      typeSpec,
-			FieldInfo: mapper.ComposeFieldInfo(&ctx),
-		})
+		return nil, err
 	}
 
-	return paramInfos, nil
+	return s.generateFile(), nil
 }
 
 func (s *StructGenerator) generateFile() *jen.File {
-	file := jen.NewFile("message")
-
-	file.Comment("Code generated by adcl/protocol/generator. DO NOT EDIT.")
+	file := newFile(messagePackage, "message")
 
-	file.Line()
+	receiver := func() *jen.Statement {
+		return jen.Params(jen.Id(s.info.TypeLetter).Op("*").Id(s.info.TypeName))
+	}
 
-	file.Type().Id(s.flagTypeName).String()
+	if len(s.info.NamedParams) > 0 {
+		file.Type().Id(s.info.FlagTypeName).String()
 
-	if len(s.namedParams) > 0 {
-		file.Const().
-			DefsFunc(s.generateFlagConstants)
+		file.Const().DefsFunc(s.generateFlagConstants)
 	}
 
-	file.Var().Id("_").Id("ParamAccessor").Op("=").Op("&").Id(s.typeName).Values()
+	file.Var().Id("_").Id("ParamAccessor").Op("=").Op("&").Id(s.info.TypeName).Values()
 
-	file.Type().Id(s.typeName).StructFunc(s.generateStructFields)
+	file.Comment(docComment(fmt.Sprintf(
+		"%s represents the parameters of %s (%s) messages.",
+		s.info.TypeName, s.message.Name, s.message.Command,
+	)))
+	file.Type().Id(s.info.TypeName).StructFunc(s.generateStructFields)
 
-	file.Func().Params(jen.Id(s.typeLetter).Op("*").Id(s.typeName)).
+	file.Func().Add(receiver()).
 		Id("Positional").Params().Index().String().
 		BlockFunc(s.generatePositional)
 
 	file.Line()
 
-	file.Func().Params(jen.Id(s.typeLetter).Op("*").Id(s.typeName)).
+	file.Func().Add(receiver()).
 		Id("PosLen").Params().Int().
 		BlockFunc(s.generatePosLen)
 
 	file.Line()
 
-	file.Func().Params(jen.Id(s.typeLetter).Op("*").Id(s.typeName)).
-		Id("PosAt").Params(jen.Id("i").Int()).String().
+	indexParam := jen.Id("_")
+	if len(s.info.PositionalParams) > 0 {
+		indexParam = jen.Id(s.indexVar())
+	}
+
+	file.Func().Add(receiver()).
+		Id("PosAt").Params(indexParam.Int()).String().
 		BlockFunc(s.generatePosAt)
 
 	file.Line()
 
-	file.Func().Params(jen.Id(s.typeLetter).Op("*").Id(s.typeName)).
+	file.Func().Add(receiver()).
 		Id("Named").Params().Map(jen.String()).String().
 		BlockFunc(s.generateNamed)
 
 	file.Line()
 
-	file.Func().Params(jen.Id(s.typeLetter).Op("*").Id(s.typeName)).
+	file.Func().Add(receiver()).
 		Id("NamedGet").Params(jen.Id("key").String()).Params(jen.String(), jen.Bool()).
 		BlockFunc(s.generateNamedGet)
 
+	file.Line()
+
+	file.Comment(docComment(fmt.Sprintf(
+		"Reset sets all fields of %s to their zero values, but retains the memory allocated for slices and maps, so that %s can be reused.",
+		s.info.TypeLetter, s.info.TypeLetter,
+	)))
+	file.Func().Add(receiver()).
+		Id("Reset").Params().
+		BlockFunc(s.generateReset)
+
+	s.generateConstructor(file)
+
 	return file
 }
 
-func (s *StructGenerator) generateFlagConstants(group *jen.Group) {
-	for ind, param := range s.namedParams {
-		ctx := s.createContext(param)
+// indexVar returns the name of the index parameter of PosAt, which must not
+// collide with the receiver.
+func (s *StructGenerator) indexVar() string {
+	if s.info.TypeLetter == "i" {
+		return "n"
+	}
 
-		name := param.Mapper.Parser.Named.ParamName(&ctx)
+	return "i"
+}
 
-		if ind == 0 {
-			group.Id(s.flagTypeName + name).Id(s.flagTypeName).Op("=").Lit(name)
-		} else {
-			group.Id(s.flagTypeName + name).Op("=").Lit(name)
+func (s *StructGenerator) field(name string) *jen.Statement {
+	return jen.Id(s.info.TypeLetter).Dot(name)
+}
+
+func (s *StructGenerator) generateFlagConstants(group *jen.Group) {
+	for _, param := range s.info.NamedParams {
+		ctx := s.info.createContext(param)
+
+		for _, name := range param.Mapper.ParamNames(&ctx) {
+			group.Id(s.info.FlagTypeName + name).Id(s.info.FlagTypeName).Op("=").Lit(name)
 		}
 	}
 }
 
 func (s *StructGenerator) generateStructFields(group *jen.Group) {
-	s.generateParamsStructFields(group, s.positionalParams)
-	s.generateParamsStructFields(group, s.namedParams)
+	s.generateParamsStructFields(group, s.info.PositionalParams)
+	s.generateParamsStructFields(group, s.info.NamedParams)
 
 	group.Id("Flags").Map(jen.String()).String()
 
 	group.Line()
 
 	if len(s.message.Flags) == 0 {
-		group.Comment("No known additional flags.")
+		group.Comment("No known additional flags")
 	} else {
+		group.Comment("Known additional flags")
 		for _, flag := range s.message.Flags {
-			group.Comment(flag.Comment)
+			group.Comment(flag.CommentLine())
 		}
 	}
 }
@@ -185,53 +147,62 @@ func (s *StructGenerator) generateParamsStructFields(group *jen.Group, params []
 	for _, param := range params {
 		info := param.FieldInfo
 
-		// TODO: Format and insert comment
+		if lines := param.Param.CommentLines(); len(lines) > 0 {
+			group.Comment(info.FieldName + " is")
+			for _, line := range lines {
+				group.Comment(line)
+			}
+		}
 
-		group.Add(info.FieldName).Add(info.FieldType)
+		group.Id(info.FieldName).Add(info.FieldType)
 
 		strFieldType := jen.String()
 		if !info.StrIsSingular {
-			strFieldType = jen.Index().Add(strFieldType)
+			strFieldType = jen.Index().String()
 		}
-		group.Add(info.StrFieldName).Add(strFieldType)
+		group.Id(info.StrFieldName).Add(strFieldType)
 
 		group.Line()
 	}
 }
 
-func (s *StructGenerator) generatePositional(group *jen.Group) {
+func (s *StructGenerator) numStaticPositional() int {
 	var numStatic int
 
-	for _, param := range s.positionalParams {
+	for _, param := range s.info.PositionalParams {
 		if param.FieldInfo.Multiplicity == MultiplicityStatic {
 			numStatic++
 		}
 	}
 
-	if numStatic == len(s.positionalParams) {
+	return numStatic
+}
+
+func (s *StructGenerator) generatePositional(group *jen.Group) {
+	params := s.info.PositionalParams
+	numStatic := s.numStaticPositional()
+
+	if numStatic == len(params) {
 		// All params have static multiplicity, build slice literal.
 
 		group.Return(
 			jen.Index().String().ValuesFunc(func(group *jen.Group) {
-				for _, param := range s.positionalParams {
+				for _, param := range params {
 					if param.FieldInfo.StrIsSingular {
-						group.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName)
+						group.Add(s.field(param.FieldInfo.StrFieldName))
 					} else {
 						for i := 0; i < param.FieldInfo.StaticMultiplicity; i++ {
-							group.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName).
-								Index(jen.Lit(i))
+							group.Add(s.field(param.FieldInfo.StrFieldName).Index(jen.Lit(i)))
 						}
 					}
 				}
 			}),
 		)
-	} else if numStatic == 0 && len(s.positionalParams) == 1 {
+	} else if numStatic == 0 && len(params) == 1 {
 		// There is only a single, dynamic multiplicity param, return its str
 		// field.
 
-		group.Return(
-			jen.Id(s.typeLetter).Dot("").Add(s.positionalParams[0].FieldInfo.StrFieldName),
-		)
+		group.Return(s.field(params[0].FieldInfo.StrFieldName))
 	} else {
 		// Params have mixed multiplicity, build slice of positionals
 		// manually.
@@ -239,27 +210,26 @@ func (s *StructGenerator) generatePositional(group *jen.Group) {
 		group.Id("positionals").Op(":=").Make(
 			jen.Index().String(),
 			jen.Lit(0),
-			jen.Id(s.typeLetter).Dot("PosLen").Call(),
+			jen.Id(s.info.TypeLetter).Dot("PosLen").Call(),
 		)
 
-		for _, param := range s.positionalParams {
+		for _, param := range params {
 			if param.FieldInfo.StrIsSingular {
 				group.Id("positionals").Op("=").Append(
 					jen.Id("positionals"),
-					jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName),
+					s.field(param.FieldInfo.StrFieldName),
 				)
 			} else if param.FieldInfo.Multiplicity == MultiplicityStatic {
 				group.Id("positionals").Op("=").AppendFunc(func(group *jen.Group) {
 					group.Id("positionals")
 					for i := 0; i < param.FieldInfo.StaticMultiplicity; i++ {
-						group.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName).
-							Index(jen.Lit(i))
+						group.Add(s.field(param.FieldInfo.StrFieldName).Index(jen.Lit(i)))
 					}
 				})
 			} else {
 				group.Id("positionals").Op("=").Append(
 					jen.Id("positionals"),
-					jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName).Op("..."),
+					s.field(param.FieldInfo.StrFieldName).Op("..."),
 				)
 			}
 		}
@@ -268,11 +238,19 @@ func (s *StructGenerator) generatePositional(group *jen.Group) {
 	}
 }
 
+func (s *StructGenerator) createRenderingContext(param paramInfo) RenderingContext {
+	return RenderingContext{
+		Context:    s.info.createContext(param),
+		FieldInfo:  param.FieldInfo,
+		ContentVar: jen.Id(s.info.TypeLetter),
+	}
+}
+
 func (s *StructGenerator) generatePosLen(group *jen.Group) {
 	var staticSum int
 	var stmt jen.Statement
 
-	for _, param := range s.positionalParams {
+	for _, param := range s.info.PositionalParams {
 		ctx := s.createRenderingContext(param)
 
 		if param.FieldInfo.Multiplicity == MultiplicityStatic {
@@ -292,40 +270,31 @@ func (s *StructGenerator) generatePosLen(group *jen.Group) {
 }
 
 func (s *StructGenerator) generatePosAt(group *jen.Group) {
-	var numStatic int
+	params := s.info.PositionalParams
+	numStatic := s.numStaticPositional()
+	index := jen.Id(s.indexVar())
 
-	if len(s.positionalParams) == 0 {
+	if len(params) == 0 {
 		group.Panic(jen.Lit("index out of range"))
 		return
 	}
 
-	for _, param := range s.positionalParams {
-		if param.FieldInfo.Multiplicity == MultiplicityStatic {
-			numStatic++
-		}
-	}
-
-	if numStatic == len(s.positionalParams) {
+	if numStatic == len(params) {
 		// All params have static multiplicity, build switch statement.
 
 		var runningIndex int
 
-		group.Switch(jen.Id("i")).BlockFunc(func(group *jen.Group) {
-			for _, param := range s.positionalParams {
+		group.Switch(index).BlockFunc(func(group *jen.Group) {
+			for _, param := range params {
 				if param.FieldInfo.StrIsSingular {
 					group.Case(jen.Lit(runningIndex)).Block(
-						jen.Return(
-							jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName),
-						),
+						jen.Return(s.field(param.FieldInfo.StrFieldName)),
 					)
 					runningIndex++
 				} else {
 					for i := 0; i < param.FieldInfo.StaticMultiplicity; i++ {
 						group.Case(jen.Lit(runningIndex)).Block(
-							jen.Return(
-								jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName).
-									Index(jen.Lit(i)),
-							),
+							jen.Return(s.field(param.FieldInfo.StrFieldName).Index(jen.Lit(i))),
 						)
 						runningIndex++
 					}
@@ -336,14 +305,11 @@ func (s *StructGenerator) generatePosAt(group *jen.Group) {
 				jen.Panic(jen.Lit("index out of range")),
 			)
 		})
-	} else if numStatic == 0 && len(s.positionalParams) == 1 {
+	} else if numStatic == 0 && len(params) == 1 {
 		// There is only a single, dynamic multiplicity param, return the ith
 		// element of its str field.
 
-		group.Return(
-			jen.Id(s.typeLetter).Dot("").Add(s.positionalParams[0].FieldInfo.StrFieldName).
-				Index(jen.Id("i")),
-		)
+		group.Return(s.field(params[0].FieldInfo.StrFieldName).Index(index))
 	} else {
 		// Params have mixed multiplicity, build conditional switch statement
 		// manually.
@@ -354,7 +320,7 @@ func (s *StructGenerator) generatePosAt(group *jen.Group) {
 		runningLenStmt := func(op string, dynamics ...jen.Code) *jen.Statement {
 			var stmt jen.Statement
 
-			if runningStaticIndex > 0 || len(runningDynamicLens) == 0 {
+			if runningStaticIndex > 0 || len(runningDynamicLens)+len(dynamics) == 0 {
 				stmt.Lit(runningStaticIndex).Op(op)
 			}
 
@@ -373,25 +339,20 @@ func (s *StructGenerator) generatePosAt(group *jen.Group) {
 		}
 
 		group.Switch().BlockFunc(func(group *jen.Group) {
-			for _, param := range s.positionalParams {
+			for _, param := range params {
 				if param.FieldInfo.StrIsSingular {
 					group.Case(
-						jen.Id("i").Op("==").Add(runningLenStmt("+")),
+						jen.Add(index).Op("==").Add(runningLenStmt("+")),
 					).Block(
-						jen.Return(
-							jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName),
-						),
+						jen.Return(s.field(param.FieldInfo.StrFieldName)),
 					)
 					runningStaticIndex++
 				} else if param.FieldInfo.Multiplicity == MultiplicityStatic {
 					for i := 0; i < param.FieldInfo.StaticMultiplicity; i++ {
 						group.Case(
-							jen.Id("i").Op("==").Add(runningLenStmt("+")),
+							jen.Add(index).Op("==").Add(runningLenStmt("+")),
 						).Block(
-							jen.Return(
-								jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName).
-									Index(jen.Lit(i).Op("-").Add(runningLenStmt("-"))),
-							),
+							jen.Return(s.field(param.FieldInfo.StrFieldName).Index(jen.Lit(i))),
 						)
 						runningStaticIndex++
 					}
@@ -400,12 +361,11 @@ func (s *StructGenerator) generatePosAt(group *jen.Group) {
 					dynamicLen := param.FieldInfo.DynamicMultiplicity(&ctx)
 
 					group.Case(
-						jen.Id("i").Op("<").Add(runningLenStmt("+", dynamicLen)),
+						jen.Add(index).Op("<").Add(runningLenStmt("+", dynamicLen)),
 					).Block(
 						jen.Return(
-							jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName).
-								// TODO: Omit -0 from [i-0]
-								Index(jen.Lit("i").Op("-").Add(runningLenStmt("-"))),
+							s.field(param.FieldInfo.StrFieldName).
+								Index(jen.Add(index).Op("-").Add(runningLenStmt("-"))),
 						),
 					)
 					runningDynamicLens = append(runningDynamicLens, dynamicLen)
@@ -420,11 +380,9 @@ func (s *StructGenerator) generatePosAt(group *jen.Group) {
 }
 
 func (s *StructGenerator) generateNamed(group *jen.Group) {
-	if len(s.namedParams) == 0 {
+	if len(s.info.NamedParams) == 0 {
 		// There are no named parameters, return the Flags map.
-		group.Return(
-			jen.Id(s.typeLetter).Dot("Flags"),
-		)
+		group.Return(s.field("Flags"))
 
 		return
 	}
@@ -434,63 +392,39 @@ func (s *StructGenerator) generateNamed(group *jen.Group) {
 	group.Line()
 
 	group.For(
-		jen.List(jen.Id("key"), jen.Id("val")).
-			Op(":=").
-			Range().Id(s.typeLetter).Dot("Flags"),
+		jen.List(jen.Id("key"), jen.Id("val")).Op(":=").Range().Add(s.field("Flags")),
 	).Block(
 		jen.Id("params").Index(jen.Id("key")).Op("=").Id("val"),
 	)
 
 	group.Line()
 
-	for _, param := range s.namedParams {
-		strStmt := jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName)
-		if !param.FieldInfo.StrIsSingular {
-			strStmt.Index(jen.Lit(0))
-		}
+	for _, param := range s.info.NamedParams {
+		ctx := s.createRenderingContext(param)
+		strField := s.field(param.FieldInfo.StrFieldName)
 
-		setStmt := jen.Id("params").Index(
-			jen.Add(strStmt).
-				Index(
-					jen.Empty(), jen.Lit(2),
-				),
-		).
-			Op("=").
-			Add(strStmt).
-			Index(
-				jen.Lit(2), jen.Empty(),
+		if !param.FieldInfo.StrIsSingular {
+			// Iterate in reverse order, so that the first parameter of
+			// each name takes precedence.
+			group.For(
+				jen.Id("i").Op(":=").Len(strField).Op("-").Lit(1),
+				jen.Id("i").Op(">=").Lit(0),
+				jen.Id("i").Op("--"),
+			).Block(
+				jen.Id("params").Index(jen.Id("namedName").Call(jen.Add(strField).Index(jen.Id("i")))).
+					Op("=").Id("namedValue").Call(jen.Add(strField).Index(jen.Id("i"))),
 			)
+			group.Line()
+			continue
+		}
 
-		if param.FieldInfo.FieldIsMaybe || !param.FieldInfo.StrIsSingular {
-			var condStmts []jen.Code
-
-			if param.FieldInfo.FieldIsMaybe {
-				condStmts = append(
-					condStmts,
-					jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.FieldName).
-						Dot("IsSet"),
-				)
-			}
-			if !param.FieldInfo.StrIsSingular {
-				condStmts = append(
-					condStmts,
-					jen.Len(
-						jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName),
-					).
-						Op(">").Lit(0),
-				)
-			}
+		setStmt := jen.Id("params").Index(jen.String().Call(ctx.FlagConst(flagNameFromParam(param.Param)))).
+			Op("=").Id("namedValue").Call(strField)
 
-			group.If(
-				s.opJoin("&&", condStmts...)...,
-			).
-				Block(
-					setStmt,
-				)
+		if cond := ctx.IsSetCond(); cond != nil {
+			group.If(cond).Block(setStmt)
 		} else {
-			group.Add(
-				setStmt,
-			)
+			group.Add(setStmt)
 		}
 	}
 
@@ -500,117 +434,128 @@ func (s *StructGenerator) generateNamed(group *jen.Group) {
 }
 
 func (s *StructGenerator) generateNamedGet(group *jen.Group) {
-	// TODO: Should the switch block be wrapped in the follwing if?
-	// group.If(jen.Len(jen.Id("key")).Op("==").Lit(2)).Block()
-
-	if len(s.namedParams) > 0 {
-		group.Switch(jen.Id(s.flagTypeName).Parens(jen.Id("key"))).
+	if len(s.info.NamedParams) > 0 {
+		group.Switch(jen.Id(s.info.FlagTypeName).Parens(jen.Id("key"))).
 			BlockFunc(func(group *jen.Group) {
-				for _, param := range s.namedParams {
-					ctx := s.createContext(param)
-					name := param.Mapper.Parser.Named.ParamName(&ctx)
+				for _, param := range s.info.NamedParams {
+					ctx := s.createRenderingContext(param)
+					strField := s.field(param.FieldInfo.StrFieldName)
+
+					var names []jen.Code
+					for _, name := range param.Mapper.ParamNames(&ctx.Context) {
+						names = append(names, ctx.FlagConst(name))
+					}
 
-					returnStrStmt := jen.Id(s.typeLetter).Dot("").
-						Add(param.FieldInfo.StrFieldName)
 					if !param.FieldInfo.StrIsSingular {
-						returnStrStmt.Index(jen.Lit(0))
+						group.Case(names...).Block(
+							jen.For(jen.List(jen.Id("_"), jen.Id("str")).Op(":=").Range().Add(strField)).Block(
+								jen.If(jen.Id("namedName").Call(jen.Id("str")).Op("==").Id("key")).Block(
+									jen.Return(jen.Id("namedValue").Call(jen.Id("str")), jen.True()),
+								),
+							),
+						)
+						continue
 					}
-					returnStrStmt.Index(
-						jen.Lit(2), jen.Empty(),
-					)
 
-					if param.FieldInfo.FieldIsMaybe || !param.FieldInfo.StrIsSingular {
-						var condStmts []jen.Code
+					returnStmt := jen.Return(jen.Id("namedValue").Call(strField), jen.True())
 
-						if param.FieldInfo.FieldIsMaybe {
-							condStmts = append(
-								condStmts,
-								jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.FieldName).
-									Dot("IsSet"),
-							)
-						}
-						if !param.FieldInfo.StrIsSingular {
-							condStmts = append(
-								condStmts,
-								jen.Len(
-									jen.Id(s.typeLetter).Dot("").Add(param.FieldInfo.StrFieldName),
-								).
-									Op(">").Lit(0),
-							)
-						}
-
-						group.Case(jen.Id(s.flagTypeName + name)).
-							If(
-								s.opJoin("&&", condStmts...)...,
-							).
-							Block(
-								jen.Return(jen.List(
-									returnStrStmt,
-									jen.Lit(true),
-								)),
-							).Else().
-							Block(
-								jen.Return(jen.List(
-									jen.Lit(""),
-									jen.Lit(false),
-								)),
-							)
+					if cond := ctx.IsSetCond(); cond != nil {
+						group.Case(names...).Block(
+							jen.If(cond).Block(returnStmt),
+						)
 					} else {
-						group.Case(jen.Id(s.flagTypeName + name)).
-							Return(jen.List(
-								returnStrStmt,
-								jen.Lit(true),
-							))
+						group.Case(names...).Block(returnStmt)
 					}
-
 				}
-
 			})
 
 		group.Line()
 	}
 
-	group.
-		List(
-			jen.Id("key"), jen.Id("val"),
-		).
-		Op(":=").
-		Id(s.typeLetter).Dot("Flags").Index(jen.Id("key"))
-
-	group.Return(jen.List(
-		jen.Id("key"), jen.Id("val"),
-	))
+	group.List(jen.Id("val"), jen.Id("ok")).Op(":=").Add(s.field("Flags")).Index(jen.Id("key"))
+	group.Return(jen.Id("val"), jen.Id("ok"))
 }
 
-func (s *StructGenerator) opJoin(op string, codes ...jen.Code) jen.Statement {
-	var stmt jen.Statement
+func (s *StructGenerator) generateReset(group *jen.Group) {
+	group.For(jen.Id("key").Op(":=").Range().Add(s.field("Flags"))).Block(
+		jen.Delete(s.field("Flags"), jen.Id("key")),
+	)
 
-	for _, code := range codes {
-		stmt.Add(code).Op(op)
-	}
+	group.Line()
 
-	if len(stmt) > 0 {
-		// Remove last Op(op)
-		stmt = stmt[:len(stmt)-1]
-	}
+	group.Op("*").Id(s.info.TypeLetter).Op("=").Id(s.info.TypeName).Values(jen.DictFunc(func(dict jen.Dict) {
+		for _, param := range append(s.info.PositionalParams, s.info.NamedParams...) {
+			info := param.FieldInfo
+			if info.StrIsSingular {
+				continue
+			}
 
-	// TODO: Return Empty() when stmt is empty?
+			dict[jen.Id(info.FieldName)] = s.field(info.FieldName).Index(jen.Empty(), jen.Lit(0))
+			dict[jen.Id(info.StrFieldName)] = s.field(info.StrFieldName).Index(jen.Empty(), jen.Lit(0))
+		}
 
-	return stmt
+		dict[jen.Id("Flags")] = s.field("Flags")
+	}))
 }
 
-func (s *StructGenerator) createRenderingContext(param paramInfo) RenderingContext {
-	return RenderingContext{
-		Context:    s.createContext(param),
-		ContentVar: jen.Id(s.typeLetter),
-		FieldInfo:  param.FieldInfo,
+func (s *StructGenerator) generateConstructor(file *jen.File) {
+	typeName := s.info.ConstructorTypeName
+
+	file.Comment(docComment(fmt.Sprintf(
+		"%s sets the fields of a %s together with the raw parameter values they have been read "+
+			"from. It is meant to be used by parsers, which cannot access the raw values directly.",
+		typeName, s.info.TypeName,
+	)))
+	file.Type().Id(typeName).Struct(
+		jen.Id("Content").Op("*").Id(s.info.TypeName),
+	)
+
+	receiver := jen.Params(jen.Id("c").Id(typeName))
+	content := func() *jen.Statement {
+		return jen.Id("c").Dot("Content")
 	}
-}
 
-func (s *StructGenerator) createContext(param paramInfo) Context {
-	return Context{
-		Param:  param.Param,
-		Mapper: param.Mapper,
-		Type:   param.Type,
+	for _, params := range [][]paramInfo{s.info.PositionalParams, s.info.NamedParams} {
+		for _, param := range params {
+			info := param.FieldInfo
+			value := info.ValueName()
+
+			var stmts []jen.Code
+
+			switch {
+			case !info.StrIsSingular:
+				stmts = append(stmts,
+					content().Dot(info.FieldName).Op("=").
+						Append(content().Dot(info.FieldName), jen.Id(value)),
+					content().Dot(info.StrFieldName).Op("=").
+						Append(content().Dot(info.StrFieldName), jen.Id("raw")),
+				)
+			case info.FieldIsMaybe:
+				stmts = append(stmts,
+					content().Dot(info.FieldName).Dot("Set").Call(jen.Id(value)),
+					content().Dot(info.StrFieldName).Op("=").Id("raw"),
+				)
+			default:
+				stmts = append(stmts,
+					content().Dot(info.FieldName).Op("=").Id(value),
+					content().Dot(info.StrFieldName).Op("=").Id("raw"),
+				)
+			}
+
+			file.Line()
+			file.Func().Add(receiver).Id(info.SetterName()).
+				Params(jen.Id(value).Add(info.ValueType), jen.Id("raw").String()).
+				Block(stmts...)
+		}
 	}
+
+	file.Line()
+	file.Func().Add(receiver).Id("SetFlag").
+		Params(jen.Id("name"), jen.Id("raw").String()).
+		Block(
+			jen.If(content().Dot("Flags").Op("==").Nil()).Block(
+				content().Dot("Flags").Op("=").Make(jen.Map(jen.String()).String()),
+			),
+			content().Dot("Flags").Index(jen.Id("name")).Op("=").Id("raw"),
+		)
 }
@@ -34,6 +34,30 @@ var ipTypeSpec = &TypeSpec{
 	DefaultMapper: BasicMapper,
 }
 
+var statusCodeTypeSpec = &TypeSpec{
+	Name:          "statuscode",
+	Mappers:       []*Mapper{StatusCodeMapper},
+	DefaultMapper: StatusCodeMapper,
+}
+
+var stringListTypeSpec = &TypeSpec{
+	Name:          "stringlist",
+	Mappers:       []*Mapper{StringListMapper},
+	DefaultMapper: StringListMapper,
+}
+
+var featureOpsTypeSpec = &TypeSpec{
+	Name:          "featureops",
+	Mappers:       []*Mapper{FeatureOpsMapper},
+	DefaultMapper: FeatureOpsMapper,
+}
+
+var searchTermsTypeSpec = &TypeSpec{
+	Name:          "searchterms",
+	Mappers:       []*Mapper{SearchTermsMapper},
+	DefaultMapper: SearchTermsMapper,
+}
+
 // Error variables related to type specifications and type and mapper
 // resolution.
 var (
@@ -47,13 +71,21 @@ func TypeSpecFromName(name string) (*TypeSpec, error) {
 	case "int":
 		return intTypeSpec, nil
 	case "float":
-		return intTypeSpec, nil
+		return floatTypeSpec, nil
 	case "string":
 		return stringTypeSpec, nil
 	case "base32":
 		return base32TypeSpec, nil
 	case "ip":
 		return ipTypeSpec, nil
+	case "statuscode":
+		return statusCodeTypeSpec, nil
+	case "stringlist":
+		return stringListTypeSpec, nil
+	case "featureops":
+		return featureOpsTypeSpec, nil
+	case "searchterms":
+		return searchTermsTypeSpec, nil
 	default:
 		return nil, ErrUnknownTypeName
 	}
@@ -0,0 +1,230 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error variables related to the validation of messages.
+var (
+	ErrInvalidCommand        = errors.New("command invalid, it must consist of an upper case letter followed by two upper case letters or digits")
+	ErrDuplicateCommand      = errors.New("command specified more than once")
+	ErrMissingName           = errors.New("name missing")
+	ErrInvalidFieldName      = errors.New("param name invalid, it must be an exported Go identifier")
+	ErrDuplicateFieldName    = errors.New("param name used more than once in message")
+	ErrInvalidFlagName       = errors.New("flag name invalid, it must consist of an upper case letter followed by an upper case letter or digit")
+	ErrDuplicateFlagName     = errors.New("flag name used more than once in message")
+	ErrModeNotSupported      = errors.New("mapper does not support the mode of the param")
+	ErrInvalidMultiplicity   = errors.New("multiplicity invalid, it must be static or dynamic")
+	ErrMultiplicityMismatch  = errors.New("multiplicity does not match the multiplicity of the mapper")
+	ErrOptionalPositional    = errors.New("positional params with static multiplicity must be required")
+	ErrDynamicNotLast        = errors.New("positional param with dynamic multiplicity must be the last positional param")
+	ErrFlagNameForPositional = errors.New("flag name specified for positional param")
+)
+
+// ValidationError is a single problem found while validating messages.
+type ValidationError struct {
+	// Path locates the problematic element, e.g. "INF: named SU: type".
+	Path string
+	Err  error
+}
+
+func (v *ValidationError) Error() string {
+	return v.Path + ": " + v.Err.Error()
+}
+
+// Cause returns the underlying error, see github.com/pkg/errors.
+func (v *ValidationError) Cause() error {
+	return v.Err
+}
+
+// ValidationErrors contains all problems found while validating messages.
+type ValidationErrors []*ValidationError
+
+func (v ValidationErrors) Error() string {
+	lines := make([]string, 0, len(v))
+	for _, err := range v {
+		lines = append(lines, err.Error())
+	}
+
+	return fmt.Sprintf("%d validation error(s):\n%s", len(v), strings.Join(lines, "\n"))
+}
+
+// Validate checks that messages can be processed by the generator. It
+// returns nil or ValidationErrors listing all problems found.
+func Validate(messages []*Message) error {
+	var v validator
+
+	commands := make(map[string]bool)
+
+	for i, message := range messages {
+		path := fmt.Sprintf("message %d", i)
+		if len(message.Command) > 0 {
+			path = message.Command
+		}
+
+		if !isValidCommand(message.Command) {
+			v.add(path+": command", ErrInvalidCommand)
+		} else if commands[message.Command] {
+			v.add(path+": command", ErrDuplicateCommand)
+		}
+		commands[message.Command] = true
+
+		if len(strings.TrimSpace(message.Name)) == 0 {
+			v.add(path+": name", ErrMissingName)
+		}
+
+		v.validateParams(path, message)
+
+		for j, flag := range message.Flags {
+			for _, name := range flag.Names {
+				if !isValidFlagName(name) {
+					v.add(fmt.Sprintf("%s: flags %d: %q", path, j, name), ErrInvalidFlagName)
+				}
+			}
+		}
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(path string, err error) {
+	v.errs = append(v.errs, &ValidationError{Path: path, Err: err})
+}
+
+func (v *validator) validateParams(path string, message *Message) {
+	fieldNames := make(map[string]bool)
+	flagNames := make(map[string]bool)
+
+	lists := []struct {
+		mode   ParamMode
+		params []*Param
+	}{
+		{ParamModePositional, message.PositionalParams},
+		{ParamModeNamed, message.NamedParams},
+	}
+
+	for _, list := range lists {
+		for i, param := range list.params {
+			paramPath := fmt.Sprintf("%s: %s %d", path, list.mode, i)
+			if len(param.Name) > 0 {
+				paramPath = fmt.Sprintf("%s: %s %s", path, list.mode, param.Name)
+			}
+
+			if param.Mode != list.mode {
+				v.add(paramPath+": mode", fmt.Errorf("param is listed as %s param, but has mode %s", list.mode, param.Mode))
+			}
+
+			if len(param.Name) == 0 {
+				v.add(paramPath+": name", ErrMissingName)
+			} else if !isExportedIdentifier(param.Name) {
+				v.add(paramPath+": name", ErrInvalidFieldName)
+			} else if fieldNames[param.Name] {
+				v.add(paramPath+": name", ErrDuplicateFieldName)
+			}
+			fieldNames[param.Name] = true
+
+			if list.mode == ParamModePositional && len(param.FlagName) > 0 {
+				v.add(paramPath+": flag", ErrFlagNameForPositional)
+			}
+
+			multiplicity := param.Multiplicity
+			if multiplicity != "" &&
+				multiplicity != MultiplicityStatic.String() &&
+				multiplicity != MultiplicityDynamic.String() {
+				v.add(paramPath+": multiplicity", ErrInvalidMultiplicity)
+				multiplicity = ""
+			}
+
+			typeSpec, err := TypeSpecFromName(param.Type)
+			if err != nil {
+				v.add(fmt.Sprintf("%s: type %q", paramPath, param.Type), err)
+				continue
+			}
+
+			mapper, err := ResolveMapperFromParam(param)
+			if err != nil {
+				v.add(fmt.Sprintf("%s: mapper %q", paramPath, param.Mapper), err)
+				continue
+			}
+
+			if !mapper.Parser.ModeParserSpec(list.mode).Available ||
+				!mapper.Writer.ModeWriterSpec(list.mode).Available {
+				v.add(fmt.Sprintf("%s: mapper %s", paramPath, mapper.Name), ErrModeNotSupported)
+				continue
+			}
+
+			ctx := Context{
+				Message: message,
+				Param:   param,
+				Mapper:  mapper,
+				Type:    typeSpec,
+			}
+			info := mapper.ComposeFieldInfo(&ctx)
+
+			if multiplicity != "" && multiplicity != info.Multiplicity.String() {
+				v.add(paramPath+": multiplicity", ErrMultiplicityMismatch)
+			}
+
+			if list.mode == ParamModePositional {
+				if info.Multiplicity == MultiplicityDynamic && i != len(list.params)-1 {
+					v.add(paramPath, ErrDynamicNotLast)
+				}
+				if info.Multiplicity == MultiplicityStatic && !param.Required {
+					v.add(paramPath+": required", ErrOptionalPositional)
+				}
+
+				continue
+			}
+
+			for _, name := range mapper.ParamNames(&ctx) {
+				if !isValidFlagName(name) {
+					v.add(fmt.Sprintf("%s: flag %q", paramPath, name), ErrInvalidFlagName)
+				} else if flagNames[name] {
+					v.add(fmt.Sprintf("%s: flag %q", paramPath, name), ErrDuplicateFlagName)
+				}
+				flagNames[name] = true
+			}
+		}
+	}
+}
+
+func isUpper(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+func isUpperOrDigit(b byte) bool {
+	return isUpper(b) || (b >= '0' && b <= '9')
+}
+
+func isValidCommand(s string) bool {
+	return len(s) == 3 && isUpper(s[0]) && isUpperOrDigit(s[1]) && isUpperOrDigit(s[2])
+}
+
+func isValidFlagName(s string) bool {
+	return len(s) == 2 && isUpper(s[0]) && isUpperOrDigit(s[1])
+}
+
+func isExportedIdentifier(s string) bool {
+	if len(s) == 0 || !isUpper(s[0]) {
+		return false
+	}
+
+	for i := 1; i < len(s); i++ {
+		b := s[i]
+		if !(isUpperOrDigit(b) || (b >= 'a' && b <= 'z') || b == '_') {
+			return false
+		}
+	}
+
+	return true
+}
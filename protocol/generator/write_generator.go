@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// WriteGenerator generates the writer.Write???Content function of a Message.
+type WriteGenerator struct {
+	message *Message
+
+	info *messageInfo
+}
+
+func NewWriteGenerator(message *Message) *WriteGenerator {
+	return &WriteGenerator{
+		message: message,
+	}
+}
+
+func (w *WriteGenerator) Generate() (*jen.File, error) {
+	var err error
+
+	w.info, err = prepareMessage(w.message)
+	if err != nil {
+		return nil, err
+	}
+
+	file := newFile(writerPackage, "writer")
+
+	file.Func().Id("Write"+w.info.TypeName).
+		Params(
+			jen.Id("m").Op("*").Id("MessageWriter"),
+			jen.Id("cnt").Op("*").Qual(messagePackage, w.info.TypeName),
+		).
+		Params(jen.Err().Error()).
+		BlockFunc(w.generateBody)
+
+	return file, nil
+}
+
+func (w *WriteGenerator) generateBody(group *jen.Group) {
+	errorCheck := jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return())
+
+	for _, params := range [][]paramInfo{w.info.PositionalParams, w.info.NamedParams} {
+		for _, param := range params {
+			ctx := RenderingContext{
+				Context:    w.info.createContext(param),
+				FieldInfo:  param.FieldInfo,
+				ContentVar: jen.Id("cnt"),
+				WriterVar:  jen.Id("m"),
+				ErrorVar:   jen.Err(),
+			}
+
+			stmts := param.Mapper.Writer.ModeWriterSpec(param.Param.Mode).Write(&ctx)
+			stmts = append(stmts, errorCheck)
+
+			if cond := ctx.IsSetCond(); cond != nil {
+				group.If(cond).Block(stmts...)
+			} else {
+				for _, stmt := range stmts {
+					group.Add(stmt)
+				}
+			}
+
+			group.Line()
+		}
+	}
+
+	group.Err().Op("=").Id("writeFlags").Call(jen.Id("m"), jen.Id("cnt").Dot("Flags"))
+	group.Add(errorCheck)
+
+	group.Line()
+	group.Return()
+}
@@ -11,3 +11,90 @@ type FeatureOp struct {
 	OpAction FeatureOpAction
 	Feature  string
 }
+
+// FeatureSet is a set of feature names, e.g. the features a client advertises
+// in the SU field of its INF or the features negotiated using SUP.
+type FeatureSet map[string]struct{}
+
+// NewFeatureSet creates a new FeatureSet containing features.
+func NewFeatureSet(features ...string) FeatureSet {
+	f := make(FeatureSet, len(features))
+	for _, feature := range features {
+		f[feature] = struct{}{}
+	}
+
+	return f
+}
+
+// Has returns true if feature is contained in f.
+func (f FeatureSet) Has(feature string) bool {
+	_, ok := f[feature]
+	return ok
+}
+
+// Add adds feature to f.
+func (f FeatureSet) Add(feature string) {
+	f[feature] = struct{}{}
+}
+
+// Remove removes feature from f.
+func (f FeatureSet) Remove(feature string) {
+	delete(f, feature)
+}
+
+// Apply adds or removes the features of ops, as done for the feature
+// operations of a SUP message.
+func (f FeatureSet) Apply(ops []FeatureOp) {
+	for _, op := range ops {
+		switch op.OpAction {
+		case FeatureOpAdd:
+			f.Add(op.Feature)
+		case FeatureOpRemove:
+			f.Remove(op.Feature)
+		}
+	}
+}
+
+// Features returns all features of f in no particular order.
+func (f FeatureSet) Features() []string {
+	features := make([]string, 0, len(f))
+	for feature := range f {
+		features = append(features, feature)
+	}
+
+	return features
+}
+
+// MatchFeatures returns true if a client supporting features is selected by
+// the feature filter of an F message. In a filter, FeatureOpAdd (+) denotes a
+// feature the client must support and FeatureOpRemove (-) a feature the client
+// must not support. An empty filter matches all clients.
+func MatchFeatures(filter []FeatureOp, features FeatureSet) bool {
+	for _, op := range filter {
+		switch op.OpAction {
+		case FeatureOpAdd:
+			if !features.Has(op.Feature) {
+				return false
+			}
+		case FeatureOpRemove:
+			if features.Has(op.Feature) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchesFeatures returns true if a client supporting features is a recipient
+// of messages with route r, with regard to the feature filter. Only routes of
+// kind RouteFeature are filtered, for all other kinds true is returned.
+func (r Route) MatchesFeatures(features FeatureSet) bool {
+	if r.Kind != RouteFeature {
+		return true
+	}
+
+	return MatchFeatures(r.Features, features)
+}
@@ -0,0 +1,38 @@
+package message_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+var _ = Describe("MatchFeatures() - Evaluating feature filters", func() {
+	It("should select clients by the feature filter of F messages", func() {
+		mes := parseLine("FSCH AAAB +TCP4-NAT0 ANfoo")
+		inf := parseLine("BINF AAAC SUTCP4,UDP4")
+
+		features := message.NewFeatureSet(inf.Content.(*message.INFContent).SU...)
+		Ω(mes.Route().MatchesFeatures(features)).Should(BeTrue())
+
+		features.Add("NAT0")
+		Ω(mes.Route().MatchesFeatures(features)).Should(BeFalse())
+
+		filter, _ := mes.Features()
+		Ω(message.MatchFeatures(filter, message.NewFeatureSet("UDP4"))).Should(BeFalse())
+		Ω(message.MatchFeatures(nil, message.NewFeatureSet())).Should(BeTrue())
+	})
+
+	It("should not filter other routes", func() {
+		mes := parseLine("BSCH AAAB ANfoo")
+		Ω(mes.Route().MatchesFeatures(message.NewFeatureSet())).Should(BeTrue())
+	})
+
+	It("should apply SUP feature operations", func() {
+		mes := parseLine("HSUP ADBASE ADTIGR RMTIGR ADZLIF")
+
+		features := message.NewFeatureSet()
+		features.Apply(mes.Content.(*message.SUPContent).FeatureOps)
+		Ω(features).Should(Equal(message.NewFeatureSet("BASE", "ZLIF")))
+	})
+})
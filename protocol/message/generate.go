@@ -0,0 +1,6 @@
+package message
+
+// The ???Content types of this package, the Parse???Content functions of the
+// parser package and the Write???Content functions of the writer package are
+// generated from the message specification in generator/spec.
+//go:generate go run ../generator/cmd/adclgen -spec ../generator/spec/base.yaml -message . -parser ../parser -writer ../writer
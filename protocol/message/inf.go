@@ -0,0 +1,310 @@
+package message
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/maybe"
+)
+
+// ClientType is the bitmask sent in the CT field of INFs.
+type ClientType int
+
+// Client types defined by BASE, see INFContent.CT.
+const (
+	ClientTypeBot        ClientType = 1
+	ClientTypeRegistered ClientType = 2
+	ClientTypeOperator   ClientType = 4
+	ClientTypeSuperUser  ClientType = 8
+	ClientTypeHubOwner   ClientType = 16
+	ClientTypeHub        ClientType = 32
+
+	// clientTypeAll is the union of all client types defined by BASE.
+	clientTypeAll ClientType = 63
+)
+
+// Has returns true if all bits of t2 are set in t.
+func (t ClientType) Has(t2 ClientType) bool {
+	return t&t2 == t2
+}
+
+// ClientType returns the CT field of i, it is 0 if the field is not set.
+func (i *INFContent) ClientType() ClientType {
+	return ClientType(i.CT.Value)
+}
+
+// SetClientType sets the CT field of i to t.
+func (i *INFContent) SetClientType(t ClientType) {
+	i.CT.Set(int(t))
+	i.ctStr = string(INFFlagCT) + strconv.Itoa(int(t))
+}
+
+// Features returns the features of the SU field of i as FeatureSet.
+func (i *INFContent) Features() FeatureSet {
+	return NewFeatureSet(i.SU...)
+}
+
+// SetFeatures sets the SU field of i to the features of f, sorted by name.
+func (i *INFContent) SetFeatures(f FeatureSet) {
+	i.SU = f.Features()
+	sort.Strings(i.SU)
+	i.suStr = string(INFFlagSU) + strings.Join(i.SU, ",")
+}
+
+// infField provides access to a known field of an INFContent together with
+// its raw parameter value.
+type infField interface {
+	isSet() bool
+	equal(other infField) bool
+	// assign sets the field to the value of other, which must be the same
+	// field of another INFContent.
+	assign(other infField)
+	unset()
+}
+
+// infFields lists the known fields of INFContent, see INFContent.Merge and
+// INFContent.Diff.
+var infFields = []struct {
+	name  INFFlag
+	field func(i *INFContent) infField
+}{
+	{INFFlagID, func(i *INFContent) infField { return base32Field{&i.ID, &i.idStr} }},
+	{INFFlagPD, func(i *INFContent) infField { return base32Field{&i.PD, &i.pdStr} }},
+	{INFFlagI4, func(i *INFContent) infField { return ipField{&i.I4, &i.i4Str} }},
+	{INFFlagI6, func(i *INFContent) infField { return ipField{&i.I6, &i.i6Str} }},
+	{INFFlagU4, func(i *INFContent) infField { return intField{&i.U4, &i.u4Str} }},
+	{INFFlagU6, func(i *INFContent) infField { return intField{&i.U6, &i.u6Str} }},
+	{INFFlagSS, func(i *INFContent) infField { return intField{&i.SS, &i.ssStr} }},
+	{INFFlagSF, func(i *INFContent) infField { return intField{&i.SF, &i.sfStr} }},
+	{INFFlagVE, func(i *INFContent) infField { return stringField{&i.VE, &i.veStr} }},
+	{INFFlagUS, func(i *INFContent) infField { return intField{&i.US, &i.usStr} }},
+	{INFFlagDS, func(i *INFContent) infField { return intField{&i.DS, &i.dsStr} }},
+	{INFFlagSL, func(i *INFContent) infField { return intField{&i.SL, &i.slStr} }},
+	{INFFlagAS, func(i *INFContent) infField { return intField{&i.AS, &i.asStr} }},
+	{INFFlagAM, func(i *INFContent) infField { return intField{&i.AM, &i.amStr} }},
+	{INFFlagEM, func(i *INFContent) infField { return stringField{&i.EM, &i.emStr} }},
+	{INFFlagNI, func(i *INFContent) infField { return stringField{&i.NI, &i.niStr} }},
+	{INFFlagDE, func(i *INFContent) infField { return stringField{&i.DE, &i.deStr} }},
+	{INFFlagHN, func(i *INFContent) infField { return intField{&i.HN, &i.hnStr} }},
+	{INFFlagHR, func(i *INFContent) infField { return intField{&i.HR, &i.hrStr} }},
+	{INFFlagHO, func(i *INFContent) infField { return intField{&i.HO, &i.hoStr} }},
+	{INFFlagTO, func(i *INFContent) infField { return stringField{&i.TO, &i.toStr} }},
+	{INFFlagCT, func(i *INFContent) infField { return intField{&i.CT, &i.ctStr} }},
+	{INFFlagAW, func(i *INFContent) infField { return intField{&i.AW, &i.awStr} }},
+	{INFFlagSU, func(i *INFContent) infField { return listField{&i.SU, &i.suStr} }},
+	{INFFlagRF, func(i *INFContent) infField { return stringField{&i.RF, &i.rfStr} }},
+}
+
+// Merge applies the INF update other to i, as done by hubs and clients when
+// receiving a subsequent INF of a user: Fields set in other overwrite the
+// fields of i, fields sent with an empty value in other (stored in
+// other.Flags) are removed from i. Fields not present in other are retained.
+//
+// i does not share memory with other afterwards, so that other can be reused.
+func (i *INFContent) Merge(other *INFContent) {
+	for _, f := range infFields {
+		if src := f.field(other); src.isSet() {
+			f.field(i).assign(src)
+		}
+	}
+
+	for name, val := range other.Flags {
+		if val != "" {
+			if i.Flags == nil {
+				i.Flags = make(map[string]string)
+			}
+			i.Flags[name] = val
+			continue
+		}
+
+		// An empty value denotes the removal of the field.
+		delete(i.Flags, name)
+		for _, f := range infFields {
+			if string(f.name) == name {
+				f.field(i).unset()
+			}
+		}
+	}
+}
+
+// Diff returns the minimal INF update which turns prev into i when merged
+// into prev (see Merge), i.e. the INF to send after the INF prev has been
+// sent. The update contains all fields of i which are not present in prev or
+// have a different value and the empty value (in Flags) for all fields of
+// prev which are not present in i. changed is false if i and prev are equal,
+// the update is empty in this case.
+func (i *INFContent) Diff(prev *INFContent) (update *INFContent, changed bool) {
+	update = &INFContent{}
+
+	remove := func(name string) {
+		if update.Flags == nil {
+			update.Flags = make(map[string]string)
+		}
+		update.Flags[name] = ""
+		changed = true
+	}
+
+	for _, f := range infFields {
+		cur, old := f.field(i), f.field(prev)
+
+		switch {
+		case cur.isSet() && (!old.isSet() || !cur.equal(old)):
+			f.field(update).assign(cur)
+			changed = true
+		case !cur.isSet() && old.isSet():
+			remove(string(f.name))
+		}
+	}
+
+	for name, val := range i.Flags {
+		if val == "" {
+			continue
+		}
+		if old, ok := prev.Flags[name]; ok && old == val {
+			continue
+		}
+
+		if update.Flags == nil {
+			update.Flags = make(map[string]string)
+		}
+		update.Flags[name] = val
+		changed = true
+	}
+	for name, val := range prev.Flags {
+		if val == "" || i.Flags[name] != "" {
+			continue
+		}
+
+		remove(name)
+	}
+
+	return update, changed
+}
+
+type base32Field struct {
+	val *maybe.Base32Value
+	raw *string
+}
+
+func (f base32Field) isSet() bool {
+	return f.val.IsSet
+}
+
+func (f base32Field) equal(other infField) bool {
+	o := other.(base32Field)
+	if f.val.Value == nil || o.val.Value == nil {
+		return f.val.Value == o.val.Value
+	}
+	return f.val.Value.String() == o.val.Value.String()
+}
+
+func (f base32Field) assign(other infField) {
+	o := other.(base32Field)
+	*f.val, *f.raw = *o.val, *o.raw
+}
+
+func (f base32Field) unset() {
+	*f.val, *f.raw = maybe.Base32Value{}, ""
+}
+
+type ipField struct {
+	val *maybe.IP
+	raw *string
+}
+
+func (f ipField) isSet() bool {
+	return f.val.IsSet
+}
+
+func (f ipField) equal(other infField) bool {
+	return f.val.Value.Equal(other.(ipField).val.Value)
+}
+
+func (f ipField) assign(other infField) {
+	o := other.(ipField)
+	*f.val, *f.raw = *o.val, *o.raw
+}
+
+func (f ipField) unset() {
+	*f.val, *f.raw = maybe.IP{}, ""
+}
+
+type intField struct {
+	val *maybe.Int
+	raw *string
+}
+
+func (f intField) isSet() bool {
+	return f.val.IsSet
+}
+
+func (f intField) equal(other infField) bool {
+	return f.val.Value == other.(intField).val.Value
+}
+
+func (f intField) assign(other infField) {
+	o := other.(intField)
+	*f.val, *f.raw = *o.val, *o.raw
+}
+
+func (f intField) unset() {
+	*f.val, *f.raw = maybe.Int{}, ""
+}
+
+type stringField struct {
+	val *maybe.String
+	raw *string
+}
+
+func (f stringField) isSet() bool {
+	return f.val.IsSet
+}
+
+func (f stringField) equal(other infField) bool {
+	return f.val.Value == other.(stringField).val.Value
+}
+
+func (f stringField) assign(other infField) {
+	o := other.(stringField)
+	*f.val, *f.raw = *o.val, *o.raw
+}
+
+func (f stringField) unset() {
+	*f.val, *f.raw = maybe.String{}, ""
+}
+
+// listField is a field with a list value, it is set if the list is not
+// empty.
+type listField struct {
+	val *[]string
+	raw *string
+}
+
+func (f listField) isSet() bool {
+	return len(*f.val) > 0
+}
+
+func (f listField) equal(other infField) bool {
+	o := *other.(listField).val
+	if len(*f.val) != len(o) {
+		return false
+	}
+	for i, v := range *f.val {
+		if v != o[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (f listField) assign(other infField) {
+	o := other.(listField)
+	// The list is copied, the memory of other may be reused by a parser.
+	*f.val = append((*f.val)[:0:0], *o.val...)
+	*f.raw = *o.raw
+}
+
+func (f listField) unset() {
+	*f.val, *f.raw = nil, ""
+}
@@ -0,0 +1,104 @@
+package message_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+)
+
+func parseINF(params string) *message.INFContent {
+	cnt, err := parser.ParseINFContent(parser.NewMessageReader(params))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return &cnt
+}
+
+// namedGet returns the value of the named parameter key, it fails if the
+// parameter is not set.
+func namedGet(cnt message.ParamAccessor, key string) string {
+	val, ok := cnt.NamedGet(key)
+	ExpectWithOffset(1, ok).Should(BeTrue())
+	return val
+}
+
+var _ = Describe("INFContent.Merge() and INFContent.Diff()", func() {
+	It("should apply INF updates", func() {
+		info := parseINF("IDAQEACQ5NQ6QXWXQQ2OLUJJTAFAEGMVVD7QDWWDI NIalice DEsome\\sdesc SS10 SUTCP4,UDP4 XXfoo")
+		update := parseINF("SS20 DE SU XX YYbar I4127.0.0.1")
+
+		info.Merge(update)
+		Ω(info.SS.Value).Should(Equal(20))
+		Ω(info.DE.IsSet).Should(BeFalse())
+		Ω(info.SU).Should(BeEmpty())
+		Ω(info.I4.Value.String()).Should(Equal("127.0.0.1"))
+		Ω(info.Named()).Should(Equal(map[string]string{
+			"ID": "AQEACQ5NQ6QXWXQQ2OLUJJTAFAEGMVVD7QDWWDI",
+			"NI": "alice",
+			"SS": "20",
+			"I4": "127.0.0.1",
+			"YY": "bar",
+		}))
+	})
+
+	It("should not share memory with the update", func() {
+		info := parseINF("NIalice")
+		update := parseINF("SUTCP4,UDP4")
+
+		info.Merge(update)
+		update.SU[0] = "ADC0"
+		Ω(info.SU).Should(Equal([]string{"TCP4", "UDP4"}))
+	})
+
+	It("should produce the minimal update", func() {
+		prev := parseINF("NIalice DEsome\\sdesc SS10 SUTCP4 XXfoo ZZbaz")
+		cur := parseINF("NIalice SS20 SUTCP4,UDP4 XXfoo YYbar")
+
+		update, changed := cur.Diff(prev)
+		Ω(changed).Should(BeTrue())
+		Ω(update.Named()).Should(Equal(map[string]string{
+			"DE": "",
+			"SS": "20",
+			"SU": "TCP4,UDP4",
+			"YY": "bar",
+			"ZZ": "",
+		}))
+
+		prev.Merge(update)
+		Ω(prev.Named()).Should(Equal(cur.Named()))
+	})
+
+	It("should compare values rather than raw parameters", func() {
+		prev := parseINF("NIalice SS10")
+		cur := &message.INFContent{}
+		cur.NI.Set("alice")
+		cur.SS.Set(10)
+
+		update, changed := cur.Diff(prev)
+		Ω(changed).Should(BeFalse())
+		Ω(update.Named()).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("INFContent accessors", func() {
+	It("should provide CT as ClientType", func() {
+		info := parseINF("CT6")
+		Ω(info.ClientType().Has(message.ClientTypeRegistered)).Should(BeTrue())
+		Ω(info.ClientType().Has(message.ClientTypeOperator)).Should(BeTrue())
+		Ω(info.ClientType().Has(message.ClientTypeBot)).Should(BeFalse())
+
+		info.SetClientType(message.ClientTypeHub)
+		Ω(info.CT.Value).Should(Equal(32))
+		Ω(namedGet(info, "CT")).Should(Equal("32"))
+		Ω(parseINF("NIalice").ClientType()).Should(BeZero())
+	})
+
+	It("should provide SU as FeatureSet", func() {
+		info := parseINF("SUUDP4,TCP4")
+		Ω(info.Features()).Should(Equal(message.NewFeatureSet("TCP4", "UDP4")))
+
+		info.SetFeatures(message.NewFeatureSet("TCP6", "ADC0"))
+		Ω(info.SU).Should(Equal([]string{"ADC0", "TCP6"}))
+		Ω(namedGet(info, "SU")).Should(Equal("ADC0,TCP6"))
+	})
+})
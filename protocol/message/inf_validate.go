@@ -0,0 +1,138 @@
+package message
+
+import (
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/maybe"
+)
+
+// ValidateINF checks the fields of info against the constraints defined by
+// BASE, e.g. hubs run it on INFs received from clients. The fields listed in
+// required must be present in info.
+//
+// The returned error is a recoverable *StatusError which can be sent to the
+// client, see StatusError.STAContent:
+//   - ErrorCodeNickInvalid if NI contains characters with a code point
+//     of 32 or below.
+//   - ErrorCodeINFField with the FM flag if a required field is missing.
+//   - ErrorCodeINFField with the FB flag if a field has an invalid value.
+//
+// Fields with an empty value, i.e. fields removed by an INF update, are not
+// checked apart from being treated as missing. The addresses in I4 and I6 are
+// not compared with the address of the client, including the zero address
+// hubs replace with it; this is up to the hub.
+func ValidateINF(info *INFContent, required ...INFFlag) error {
+	for _, name := range required {
+		if !hasINFField(info, name) {
+			sErr := infStatusError(ErrorCodeINFField, "Missing INF field "+string(name))
+			sErr.FM.Set(string(name))
+			return sErr
+		}
+	}
+
+	if info.NI.IsSet && !isPrintable(info.NI.Value, 33) {
+		return infStatusError(ErrorCodeNickInvalid, "Invalid nick")
+	}
+
+	for _, f := range infChecks {
+		if !f.valid(info) {
+			sErr := infStatusError(ErrorCodeINFField, "Invalid INF field "+string(f.name))
+			sErr.FB.Set(string(f.name))
+			return sErr
+		}
+	}
+
+	return nil
+}
+
+// infChecks lists the constraints checked by ValidateINF apart from NI.
+var infChecks = []struct {
+	name  INFFlag
+	valid func(i *INFContent) bool
+}{
+	{INFFlagI4, func(i *INFContent) bool { return !i.I4.IsSet || i.I4.Value.To4() != nil }},
+	{INFFlagI6, func(i *INFContent) bool {
+		return !i.I6.IsSet || (i.I6.Value.To16() != nil && i.I6.Value.To4() == nil)
+	}},
+	{INFFlagU4, func(i *INFContent) bool { return isInRange(i.U4, 1, 65535) }},
+	{INFFlagU6, func(i *INFContent) bool { return isInRange(i.U6, 1, 65535) }},
+	{INFFlagSS, func(i *INFContent) bool { return isInRange(i.SS, 0, -1) }},
+	{INFFlagSF, func(i *INFContent) bool { return isInRange(i.SF, 0, -1) }},
+	{INFFlagUS, func(i *INFContent) bool { return isInRange(i.US, 0, -1) }},
+	{INFFlagDS, func(i *INFContent) bool { return isInRange(i.DS, 0, -1) }},
+	{INFFlagSL, func(i *INFContent) bool { return isInRange(i.SL, 0, -1) }},
+	{INFFlagAS, func(i *INFContent) bool { return isInRange(i.AS, 0, -1) }},
+	{INFFlagAM, func(i *INFContent) bool { return isInRange(i.AM, 0, -1) }},
+	{INFFlagDE, func(i *INFContent) bool { return !i.DE.IsSet || isPrintable(i.DE.Value, 32) }},
+	{INFFlagHN, func(i *INFContent) bool { return isInRange(i.HN, 0, -1) }},
+	{INFFlagHR, func(i *INFContent) bool { return isInRange(i.HR, 0, -1) }},
+	{INFFlagHO, func(i *INFContent) bool { return isInRange(i.HO, 0, -1) }},
+	{INFFlagCT, func(i *INFContent) bool {
+		return isInRange(i.CT, 0, -1) && i.ClientType()&^clientTypeAll == 0
+	}},
+	{INFFlagAW, func(i *INFContent) bool { return isInRange(i.AW, 0, 2) }},
+	{INFFlagSU, func(i *INFContent) bool {
+		for _, feature := range i.SU {
+			if !isFeatureName(feature) {
+				return false
+			}
+		}
+		return true
+	}},
+}
+
+func infStatusError(code ErrorCode, description string) *StatusError {
+	return &StatusError{
+		Code:        StatusCode{Severity: SeverityRecoverable, Error: code},
+		Description: description,
+	}
+}
+
+// hasINFField returns true if the field name is present in info with a
+// non-empty value.
+func hasINFField(info *INFContent, name INFFlag) bool {
+	for _, f := range infFields {
+		if f.name == name {
+			return f.field(info).isSet()
+		}
+	}
+
+	return info.Flags[string(name)] != ""
+}
+
+// isInRange returns true if m is not set or its value is between min and max
+// (inclusive). A negative max means no upper bound.
+func isInRange(m maybe.Int, min, max int) bool {
+	if !m.IsSet {
+		return true
+	}
+
+	return m.Value >= min && (max < 0 || m.Value <= max)
+}
+
+// isPrintable returns true if s only contains characters with a code point of
+// at least min.
+func isPrintable(s string, min rune) bool {
+	for _, r := range s {
+		if r < min {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isFeatureName returns true if feature is a FOURCC, i.e. an upper case
+// letter followed by three upper case letters or digits.
+func isFeatureName(feature string) bool {
+	if len(feature) != 4 || !encoding.IsUpperAlpha(feature[0]) {
+		return false
+	}
+
+	for i := 1; i < len(feature); i++ {
+		if !encoding.IsUpperAlphaNum(feature[i]) {
+			return false
+		}
+	}
+
+	return true
+}
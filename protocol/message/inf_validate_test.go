@@ -0,0 +1,56 @@
+package message_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+var _ = Describe("ValidateINF()", func() {
+	statusError := func(err error) *message.StatusError {
+		ExpectWithOffset(1, err).Should(BeAssignableToTypeOf(&message.StatusError{}))
+		return err.(*message.StatusError)
+	}
+
+	It("should accept valid INFs", func() {
+		info := parseINF("IDAQEACQ5NQ6QXWXQQ2OLUJJTAFAEGMVVD7QDWWDI NIalice DEsome\\sdesc I40.0.0.0 U41412 SS10 CT6 AW1 SUTCP4,ADC0 XXfoo")
+		Ω(message.ValidateINF(info, message.INFFlagID, message.INFFlagNI)).Should(Succeed())
+		Ω(message.ValidateINF(parseINF("DE SU"))).Should(Succeed())
+	})
+
+	It("should reject invalid nicks", func() {
+		sErr := statusError(message.ValidateINF(parseINF("NIsome\\snick")))
+		Ω(sErr.Code.Error).Should(Equal(message.ErrorCodeNickInvalid))
+		Ω(sErr.Code.Severity).Should(Equal(message.SeverityRecoverable))
+	})
+
+	It("should report missing fields", func() {
+		sErr := statusError(message.ValidateINF(parseINF("NI DEdesc XX"), message.INFFlagNI))
+		Ω(sErr.Code.Error).Should(Equal(message.ErrorCodeINFField))
+		Ω(sErr.FM.Value).Should(Equal("NI"))
+
+		sErr = statusError(message.ValidateINF(parseINF("NIalice"), message.INFFlagNI, "XX"))
+		Ω(sErr.FM.Value).Should(Equal("XX"))
+	})
+
+	It("should report invalid fields", func() {
+		invalid := map[string]string{
+			"I6127.0.0.1": "I6",
+			"I4::1":       "I4",
+			"U40":         "U4",
+			"SS-1":        "SS",
+			"CT64":        "CT",
+			"AW3":         "AW",
+			"SUTCP4,tcp6": "SU",
+			"SUTCP4,1TCP": "SU",
+			"DEtab\\n":    "DE",
+		}
+
+		for params, field := range invalid {
+			sErr := statusError(message.ValidateINF(parseINF(params)))
+			Ω(sErr.Code.Error).Should(Equal(message.ErrorCodeINFField), params)
+			Ω(sErr.FB.Value).Should(Equal(field), params)
+		}
+	})
+})
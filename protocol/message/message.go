@@ -66,3 +66,33 @@ func (g *GenericContent) NamedGet(key string) (string, bool) {
 	val, ok := g.NamedParams[key]
 	return val, ok
 }
+
+// Reset sets all fields of g to their zero values, but retains the memory
+// allocated for slices and maps, so that g can be reused.
+func (g *GenericContent) Reset() {
+	for key := range g.NamedParams {
+		delete(g.NamedParams, key)
+	}
+
+	g.PositionalParams = g.PositionalParams[:0]
+}
+
+// namedName returns the name of the raw named parameter raw, i.e. its first
+// two characters.
+func namedName(raw string) string {
+	if len(raw) < 2 {
+		return ""
+	}
+
+	return raw[:2]
+}
+
+// namedValue returns the value of the raw named parameter raw, i.e. raw
+// without the leading parameter name.
+func namedValue(raw string) string {
+	if len(raw) < 2 {
+		return ""
+	}
+
+	return raw[2:]
+}
@@ -0,0 +1,22 @@
+package message_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+)
+
+func TestMessage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Message Suite")
+}
+
+func parseLine(line string) message.Message {
+	mes, err := parser.ParseMessage(parser.NewMessageReader(line))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return mes
+}
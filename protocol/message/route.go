@@ -0,0 +1,172 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/seoester/adcl/protocol/encoding"
+)
+
+// RouteKind describes how a message is to be delivered, it is determined by
+// the message type.
+type RouteKind int
+
+const (
+	// RouteInvalid is the kind of messages with an invalid type.
+	RouteInvalid RouteKind = iota
+	// RouteBroadcast messages (B) are sent to all clients by the hub.
+	RouteBroadcast
+	// RouteDirect messages (D) are sent to the target client by the hub.
+	RouteDirect
+	// RouteEcho messages (E) are sent to the target client and back to the
+	// source client by the hub.
+	RouteEcho
+	// RouteFeature messages (F) are sent to all clients matching the feature
+	// filter by the hub.
+	RouteFeature
+	// RouteHub messages (H) are sent from a client to the hub.
+	RouteHub
+	// RouteInfo messages (I) are sent from the hub to a client.
+	RouteInfo
+	// RouteClient messages (C) are exchanged between clients over a direct
+	// connection.
+	RouteClient
+	// RouteUDP messages (U) are sent between clients via UDP.
+	RouteUDP
+)
+
+func (r RouteKind) String() string {
+	switch r {
+	case RouteInvalid:
+		return "invalid"
+	case RouteBroadcast:
+		return "broadcast"
+	case RouteDirect:
+		return "direct"
+	case RouteEcho:
+		return "echo"
+	case RouteFeature:
+		return "feature"
+	case RouteHub:
+		return "hub"
+	case RouteInfo:
+		return "info"
+	case RouteClient:
+		return "client"
+	case RouteUDP:
+		return "udp"
+	default:
+		return fmt.Sprintf("RouteKind(%d)", r)
+	}
+}
+
+// RouteKindFromType returns the RouteKind of messages of type typ.
+func RouteKindFromType(typ Type) RouteKind {
+	switch typ {
+	case TypeBroadcast:
+		return RouteBroadcast
+	case TypeDirectmessage:
+		return RouteDirect
+	case TypeEchomessage:
+		return RouteEcho
+	case TypeFeaturebroadcast:
+		return RouteFeature
+	case TypeHubmessage:
+		return RouteHub
+	case TypeInfomessage:
+		return RouteInfo
+	case TypeClientmessage:
+		return RouteClient
+	case TypeUDPmessage:
+		return RouteUDP
+	default:
+		return RouteInvalid
+	}
+}
+
+// Route describes the delivery of a message as specified by its type and
+// header fields. Fields not applicable to the Kind are nil.
+type Route struct {
+	Kind RouteKind
+
+	// Source is the SID of the sending client (B, D, E, F).
+	Source *encoding.Base32Value
+	// SourceCID is the CID of the sending client (U).
+	SourceCID *encoding.Base32Value
+	// Target is the SID of the receiving client (D, E).
+	Target *encoding.Base32Value
+	// Features is the feature filter (F).
+	Features []FeatureOp
+}
+
+// Route returns the Route of m. The header fields of m are taken into account
+// even if they do not match the type of m.
+func (m *Message) Route() Route {
+	r := Route{
+		Kind: RouteKindFromType(m.Type),
+	}
+
+	r.Source, _ = m.SourceSID()
+	r.SourceCID, _ = m.SourceCID()
+	r.Target, _ = m.TargetSID()
+	r.Features, _ = m.Features()
+
+	return r
+}
+
+// SourceSID returns the SID of the sending client, which is part of the header
+// of B, D, E and F messages. ok is false if the header fields of m do not
+// contain a SID or the SID is nil.
+func (m *Message) SourceSID() (sid *encoding.Base32Value, ok bool) {
+	switch f := m.HeaderFields.(type) {
+	case BroadcastHeaderFields:
+		sid = f.MySID
+	case DEHeaderFields:
+		sid = f.MySID
+	case DirectHeaderFields:
+		sid = f.MySID
+	case EchoHeaderFields:
+		sid = f.MySID
+	case FeatureHeaderFields:
+		sid = f.MySID
+	}
+
+	return sid, sid != nil
+}
+
+// TargetSID returns the SID of the receiving client, which is part of the
+// header of D and E messages. ok is false if the header fields of m do not
+// contain a target SID or the SID is nil.
+func (m *Message) TargetSID() (sid *encoding.Base32Value, ok bool) {
+	switch f := m.HeaderFields.(type) {
+	case DEHeaderFields:
+		sid = f.TargetSID
+	case DirectHeaderFields:
+		sid = f.TargetSID
+	case EchoHeaderFields:
+		sid = f.TargetSID
+	}
+
+	return sid, sid != nil
+}
+
+// SourceCID returns the CID of the sending client, which is part of the header
+// of U messages. ok is false if the header fields of m do not contain a CID or
+// the CID is nil.
+func (m *Message) SourceCID() (cid *encoding.Base32Value, ok bool) {
+	if f, isUDP := m.HeaderFields.(UDPHeaderFields); isUDP {
+		cid = f.MyCID
+	}
+
+	return cid, cid != nil
+}
+
+// Features returns the feature filter, which is part of the header of F
+// messages. ok is false if the header fields of m are not FeatureHeaderFields.
+func (m *Message) Features() (features []FeatureOp, ok bool) {
+	f, ok := m.HeaderFields.(FeatureHeaderFields)
+	if !ok {
+		return nil, false
+	}
+
+	return f.Features, true
+}
@@ -0,0 +1,46 @@
+package message_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+var _ = Describe("Message.Route() - Routing information of parsed messages", func() {
+	It("should describe echo messages", func() {
+		mes := parseLine("EMSG AAAB AAAC hi")
+
+		route := mes.Route()
+		Ω(route.Kind).Should(Equal(message.RouteEcho))
+		Ω(route.Source.String()).Should(Equal("AAAB"))
+		Ω(route.Target.String()).Should(Equal("AAAC"))
+		Ω(route.SourceCID).Should(BeNil())
+		Ω(route.Features).Should(BeNil())
+	})
+
+	It("should describe feature broadcasts", func() {
+		mes := parseLine("FSCH AAAB +TCP4-NAT0 ANfoo")
+
+		route := mes.Route()
+		Ω(route.Kind).Should(Equal(message.RouteFeature))
+		Ω(route.Source.String()).Should(Equal("AAAB"))
+		Ω(route.Target).Should(BeNil())
+		Ω(route.Features).Should(Equal([]message.FeatureOp{
+			{OpAction: message.FeatureOpAdd, Feature: "TCP4"},
+			{OpAction: message.FeatureOpRemove, Feature: "NAT0"},
+		}))
+	})
+
+	It("should describe UDP and hub messages", func() {
+		mes := parseLine("URES LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ FNfoo SI1 SL1 TOtoken")
+		Ω(mes.Route().Kind).Should(Equal(message.RouteUDP))
+		Ω(mes.Route().SourceCID.String()).Should(Equal("LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"))
+
+		mes = parseLine("HSUP ADBASE")
+		Ω(mes.Route()).Should(Equal(message.Route{Kind: message.RouteHub}))
+
+		_, ok := mes.SourceSID()
+		Ω(ok).Should(BeFalse())
+	})
+})
@@ -0,0 +1,43 @@
+package message
+
+import (
+	"fmt"
+)
+
+// State is the state of an ADC session as defined by BASE.
+type State int
+
+const (
+	// StateProtocol is the initial state, the client and the hub negotiate
+	// the protocol features using SUP.
+	StateProtocol State = iota
+	// StateIdentify is entered when the hub assigns a SID, the client sends
+	// its INF.
+	StateIdentify
+	// StateVerify is entered when the hub requests a password using GPA, the
+	// client answers with PAS.
+	StateVerify
+	// StateNormal is entered when the hub sends the INF of the client. From
+	// then on, the client may send and receive arbitrary messages.
+	StateNormal
+	// StateData is the state of client-client connections transferring
+	// binary data. It is not used for hub connections.
+	StateData
+)
+
+func (s State) String() string {
+	switch s {
+	case StateProtocol:
+		return "PROTOCOL"
+	case StateIdentify:
+		return "IDENTIFY"
+	case StateVerify:
+		return "VERIFY"
+	case StateNormal:
+		return "NORMAL"
+	case StateData:
+		return "DATA"
+	default:
+		return fmt.Sprintf("State(%d)", s)
+	}
+}
@@ -2,6 +2,7 @@ package message
 
 import (
 	"errors"
+	"strconv"
 )
 
 // Error variables related to status types.
@@ -17,12 +18,103 @@ type Severity int
 
 const (
 	SeveritySuccess     Severity = 0
-	SeverityRecoverable          = 1
-	SeverityFatal                = 2
+	SeverityRecoverable Severity = 1
+	SeverityFatal       Severity = 2
 )
 
+// ErrorCode is the error part of a status code. The tens digit is the
+// category of the error, codes ending in 0 are generic errors of their
+// category. ErrorCode implements error, so that errors wrapping a status code
+// can be checked with errors.Is.
 type ErrorCode int
 
+// Error codes defined by BASE $ 5.3.1. STA.
+const (
+	ErrorCodeGeneric ErrorCode = 0
+
+	ErrorCodeHubGeneric  ErrorCode = 10
+	ErrorCodeHubFull     ErrorCode = 11
+	ErrorCodeHubDisabled ErrorCode = 12
+
+	ErrorCodeLoginGeneric    ErrorCode = 20
+	ErrorCodeNickInvalid     ErrorCode = 21
+	ErrorCodeNickTaken       ErrorCode = 22
+	ErrorCodeInvalidPassword ErrorCode = 23
+	ErrorCodeCIDTaken        ErrorCode = 24
+	// ErrorCodeAccessDenied is sent with the FC flag, the offending command.
+	ErrorCodeAccessDenied   ErrorCode = 25
+	ErrorCodeRegisteredOnly ErrorCode = 26
+	ErrorCodeInvalidPID     ErrorCode = 27
+
+	ErrorCodeBanGeneric        ErrorCode = 30
+	ErrorCodePermanentlyBanned ErrorCode = 31
+	// ErrorCodeTemporarilyBanned is sent with the TL flag, the remaining
+	// ban time in seconds.
+	ErrorCodeTemporarilyBanned ErrorCode = 32
+
+	ErrorCodeProtocolGeneric ErrorCode = 40
+	// ErrorCodeTransferProtocolUnsupported and
+	// ErrorCodeDirectConnectionFailed are sent with the TO flag, the token,
+	// and the PR flag, the protocol.
+	ErrorCodeTransferProtocolUnsupported ErrorCode = 41
+	ErrorCodeDirectConnectionFailed      ErrorCode = 42
+	// ErrorCodeINFField is sent with the FM flag, the missing field, or the
+	// FB flag, the bad field.
+	ErrorCodeINFField ErrorCode = 43
+	// ErrorCodeInvalidState is sent with the FC flag, the offending command.
+	ErrorCodeInvalidState ErrorCode = 44
+	// ErrorCodeFeatureMissing is sent with the FC flag, the missing feature.
+	ErrorCodeFeatureMissing ErrorCode = 45
+	// ErrorCodeInvalidIP is sent with the I4 or I6 flag, the correct IP.
+	ErrorCodeInvalidIP            ErrorCode = 46
+	ErrorCodeNoHubHashOverlap     ErrorCode = 47
+	ErrorCodeTransferGeneric      ErrorCode = 50
+	ErrorCodeFileNotAvailable     ErrorCode = 51
+	ErrorCodeFilePartNotAvailable ErrorCode = 52
+	ErrorCodeSlotsFull            ErrorCode = 53
+	ErrorCodeNoClientHashOverlap  ErrorCode = 54
+)
+
+var errorCodeDescriptions = map[ErrorCode]string{
+	ErrorCodeGeneric:                     "generic error",
+	ErrorCodeHubGeneric:                  "hub error",
+	ErrorCodeHubFull:                     "hub full",
+	ErrorCodeHubDisabled:                 "hub disabled",
+	ErrorCodeLoginGeneric:                "login error",
+	ErrorCodeNickInvalid:                 "nick invalid",
+	ErrorCodeNickTaken:                   "nick taken",
+	ErrorCodeInvalidPassword:             "invalid password",
+	ErrorCodeCIDTaken:                    "CID taken",
+	ErrorCodeAccessDenied:                "access denied",
+	ErrorCodeRegisteredOnly:              "registered users only",
+	ErrorCodeInvalidPID:                  "invalid PID",
+	ErrorCodeBanGeneric:                  "banned",
+	ErrorCodePermanentlyBanned:           "permanently banned",
+	ErrorCodeTemporarilyBanned:           "temporarily banned",
+	ErrorCodeProtocolGeneric:             "protocol error",
+	ErrorCodeTransferProtocolUnsupported: "transfer protocol unsupported",
+	ErrorCodeDirectConnectionFailed:      "direct connection failed",
+	ErrorCodeINFField:                    "required INF field missing or bad",
+	ErrorCodeInvalidState:                "invalid state",
+	ErrorCodeFeatureMissing:              "required feature missing",
+	ErrorCodeInvalidIP:                   "invalid IP",
+	ErrorCodeNoHubHashOverlap:            "no hash support overlap with hub",
+	ErrorCodeTransferGeneric:             "transfer error",
+	ErrorCodeFileNotAvailable:            "file not available",
+	ErrorCodeFilePartNotAvailable:        "file part not available",
+	ErrorCodeSlotsFull:                   "slots full",
+	ErrorCodeNoClientHashOverlap:         "no hash support overlap with client",
+}
+
+// Error returns a short description of the error code.
+func (e ErrorCode) Error() string {
+	if desc, ok := errorCodeDescriptions[e]; ok {
+		return desc
+	}
+
+	return "error code " + strconv.Itoa(int(e))
+}
+
 type StatusCode struct {
 	Severity Severity
 	Error    ErrorCode
@@ -32,6 +124,18 @@ func (s StatusCode) Code() int {
 	return int(s.Severity)*100 + int(s.Error)
 }
 
+// String returns the three-digit representation of the status code as used in
+// STA messages.
+func (s StatusCode) String() string {
+	code := s.Code()
+
+	return string([]byte{
+		byte0 + byte(code/100%10),
+		byte0 + byte(code/10%10),
+		byte0 + byte(code%10),
+	})
+}
+
 func ParseStatusCode(s string) (status StatusCode, err error) {
 	if len(s) != 3 {
 		return status, ErrInvalidStatusCode
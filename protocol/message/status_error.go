@@ -0,0 +1,224 @@
+package message
+
+import (
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/maybe"
+)
+
+// Error variables related to StatusError.
+var (
+	ErrInvalidStatusFlag = errors.New("invalid STA flag value")
+)
+
+// STA flags defined by BASE $ 5.3.1. STA.
+const (
+	staFlagFC = "FC"
+	staFlagTL = "TL"
+	staFlagTO = "TO"
+	staFlagPR = "PR"
+	staFlagFM = "FM"
+	staFlagFB = "FB"
+	staFlagI4 = "I4"
+	staFlagI6 = "I6"
+)
+
+// staFlagQP is the STA flag defined by EXT § 3.11 QP - Upload queue
+// notification.
+const staFlagQP = "QP"
+
+// StatusError is an error reported in a STA message, together with the
+// additional flags defined by BASE and the QP flag of EXT.
+//
+// errors.Is reports whether a StatusError matches an ErrorCode or another
+// StatusError with the same status code, e.g.
+//
+//	errors.Is(err, message.ErrorCodeNickTaken)
+type StatusError struct {
+	Code        StatusCode
+	Description string
+
+	// FC is the FOURCC of the offending command or of the missing feature.
+	FC maybe.String
+	// TL is the remaining time in seconds of a temporary ban, -1 means
+	// forever.
+	TL maybe.Int
+	// TO is the token of the failed connection attempt.
+	TO maybe.String
+	// PR is the protocol of the failed connection attempt.
+	PR maybe.String
+	// FM is the name of the missing INF field.
+	FM maybe.String
+	// FB is the name of the bad INF field.
+	FB maybe.String
+	// I4 is the IPv4 address the hub expected in the INF.
+	I4 maybe.IP
+	// I6 is the IPv6 address the hub expected in the INF.
+	I6 maybe.IP
+
+	// QP is the position of the client in the upload queue of the peer,
+	// which has no free slot.
+	QP maybe.Int
+}
+
+// NewStatusError returns the StatusError reported by cnt. Flags other than
+// those of BASE and QP are ignored. ErrInvalidStatusFlag is returned if a
+// known flag has an invalid value.
+func NewStatusError(cnt *STAContent) (*StatusError, error) {
+	s := &StatusError{
+		Code:        cnt.Code,
+		Description: cnt.Description,
+	}
+
+	for name, raw := range cnt.Flags {
+		var err error
+
+		switch name {
+		case staFlagFC:
+			err = decodeStatusString(&s.FC, raw)
+		case staFlagTL:
+			var tl int
+			tl, err = strconv.Atoi(raw)
+			s.TL.Set(tl)
+		case staFlagTO:
+			err = decodeStatusString(&s.TO, raw)
+		case staFlagPR:
+			err = decodeStatusString(&s.PR, raw)
+		case staFlagFM:
+			err = decodeStatusString(&s.FM, raw)
+		case staFlagFB:
+			err = decodeStatusString(&s.FB, raw)
+		case staFlagI4:
+			err = decodeStatusIP(&s.I4, raw)
+		case staFlagI6:
+			err = decodeStatusIP(&s.I6, raw)
+		case staFlagQP:
+			var qp int
+			qp, err = strconv.Atoi(raw)
+			s.QP.Set(qp)
+		}
+
+		if err != nil {
+			return nil, ErrInvalidStatusFlag
+		}
+	}
+
+	return s, nil
+}
+
+// NewSTAContent returns the content of a STA message reporting err.
+//
+// If err is or wraps a StatusError, it is sent as is. Otherwise, err is sent
+// as recoverable error with the description err.Error(). The error code is
+// taken from an ErrorCode wrapped by err, ErrorCodeGeneric is used if there
+// is none.
+func NewSTAContent(err error) (*STAContent, error) {
+	var s *StatusError
+	if !errors.As(err, &s) {
+		code := ErrorCodeGeneric
+		errors.As(err, &code)
+
+		s = &StatusError{
+			Code:        StatusCode{Severity: SeverityRecoverable, Error: code},
+			Description: err.Error(),
+		}
+	}
+
+	return s.STAContent()
+}
+
+// Error returns the status code and the description of s.
+func (s *StatusError) Error() string {
+	desc := s.Description
+	if desc == "" {
+		desc = s.Code.Error.Error()
+	}
+
+	return "status " + s.Code.String() + ": " + desc
+}
+
+// Unwrap returns the ErrorCode of s.
+func (s *StatusError) Unwrap() error {
+	return s.Code.Error
+}
+
+// Is reports whether target is a StatusError with the same status code as s.
+// Matching an ErrorCode is handled by Unwrap.
+func (s *StatusError) Is(target error) bool {
+	t, ok := target.(*StatusError)
+	return ok && t.Code == s.Code
+}
+
+// STAContent returns the content of a STA message reporting s. An error is
+// returned if the description or a flag is not a valid UTF-8 string.
+func (s *StatusError) STAContent() (*STAContent, error) {
+	desc, err := encoding.EncodeToADCString(s.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	cnt := &STAContent{}
+	cons := STAContentConstructor{Content: cnt}
+	cons.SetCode(s.Code, s.Code.String())
+	cons.SetDescription(s.Description, desc)
+
+	flags := []struct {
+		name string
+		val  maybe.String
+	}{
+		{staFlagFC, s.FC},
+		{staFlagTO, s.TO},
+		{staFlagPR, s.PR},
+		{staFlagFM, s.FM},
+		{staFlagFB, s.FB},
+	}
+	for _, f := range flags {
+		if !f.val.IsSet {
+			continue
+		}
+
+		raw, err := encoding.EncodeToADCString(f.val.Value)
+		if err != nil {
+			return nil, err
+		}
+		cons.SetFlag(f.name, raw)
+	}
+
+	if s.TL.IsSet {
+		cons.SetFlag(staFlagTL, strconv.Itoa(s.TL.Value))
+	}
+	if s.I4.IsSet {
+		cons.SetFlag(staFlagI4, s.I4.Value.String())
+	}
+	if s.I6.IsSet {
+		cons.SetFlag(staFlagI6, s.I6.Value.String())
+	}
+	if s.QP.IsSet {
+		cons.SetFlag(staFlagQP, strconv.Itoa(s.QP.Value))
+	}
+
+	return cnt, nil
+}
+
+func decodeStatusString(m *maybe.String, raw string) error {
+	s, err := encoding.DecodeADCString(raw)
+	if err != nil {
+		return err
+	}
+
+	m.Set(s)
+	return nil
+}
+
+func decodeStatusIP(m *maybe.IP, raw string) error {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return ErrInvalidStatusFlag
+	}
+
+	m.Set(ip)
+	return nil
+}
@@ -0,0 +1,107 @@
+package message_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/protocol/writer"
+)
+
+var _ = Describe("StatusError", func() {
+	It("should be written and parsed with its flags", func() {
+		sErr := &message.StatusError{
+			Code: message.StatusCode{
+				Severity: message.SeverityFatal,
+				Error:    message.ErrorCodeINFField,
+			},
+			Description: "Bad INF",
+		}
+		sErr.FB.Set("I4")
+		sErr.I4.Set(net.IPv4(10, 0, 0, 1))
+		sErr.TO.Set("some token")
+		sErr.QP.Set(3)
+
+		cnt, err := sErr.STAContent()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		mes := message.Message{
+			Type:         message.TypeInfomessage,
+			Command:      message.CommandSTA,
+			HeaderFields: message.InfoHeaderFields{},
+			Content:      cnt,
+		}
+		line, err := writer.FormatMessage(&mes)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(line).Should(Equal("ISTA 243 Bad\\sINF FBI4 I410.0.0.1 QP3 TOsome\\stoken\n"))
+
+		parsed, err := parser.ParseMessage(parser.NewMessageReader(line[:len(line)-1]))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		pErr, err := message.NewStatusError(parsed.Content.(*message.STAContent))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(pErr.Code).Should(Equal(sErr.Code))
+		Ω(pErr.Description).Should(Equal("Bad INF"))
+		Ω(pErr.FB.Value).Should(Equal("I4"))
+		Ω(pErr.TO.Value).Should(Equal("some token"))
+		Ω(pErr.QP.Value).Should(Equal(3))
+		Ω(pErr.I4.Value.Equal(sErr.I4.Value)).Should(BeTrue())
+		Ω(pErr.FC.IsSet).Should(BeFalse())
+		Ω(pErr.Error()).Should(Equal("status 243: Bad INF"))
+	})
+
+	It("should reject invalid flag values", func() {
+		for _, line := range []string{"ISTA 132 Banned TLforever", "ISTA 146 IP I4nope", "ISTA 253 Full QPfirst"} {
+			parsed, err := parser.ParseMessage(parser.NewMessageReader(line))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, err = message.NewStatusError(parsed.Content.(*message.STAContent))
+			Ω(err).Should(Equal(message.ErrInvalidStatusFlag))
+		}
+	})
+
+	It("should match error codes and status errors", func() {
+		sErr := &message.StatusError{
+			Code: message.StatusCode{
+				Severity: message.SeverityFatal,
+				Error:    message.ErrorCodeNickTaken,
+			},
+		}
+		wrapped := fmt.Errorf("login: %w", sErr)
+
+		Ω(errors.Is(wrapped, message.ErrorCodeNickTaken)).Should(BeTrue())
+		Ω(errors.Is(wrapped, message.ErrorCodeCIDTaken)).Should(BeFalse())
+		Ω(errors.Is(wrapped, &message.StatusError{Code: sErr.Code})).Should(BeTrue())
+		Ω(errors.Is(wrapped, &message.StatusError{
+			Code: message.StatusCode{Severity: message.SeverityRecoverable, Error: message.ErrorCodeNickTaken},
+		})).Should(BeFalse())
+		Ω(sErr.Error()).Should(Equal("status 222: nick taken"))
+	})
+
+	It("should build STA messages from errors", func() {
+		cnt, err := message.NewSTAContent(fmt.Errorf("no slot for you: %w", message.ErrorCodeSlotsFull))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cnt.Code.String()).Should(Equal("153"))
+		Ω(cnt.Description).Should(Equal("no slot for you: slots full"))
+
+		cnt, err = message.NewSTAContent(errors.New("something broke"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cnt.Code.String()).Should(Equal("100"))
+
+		sErr := &message.StatusError{
+			Code:        message.StatusCode{Severity: message.SeverityFatal, Error: message.ErrorCodeInvalidState},
+			Description: "Expected SUP",
+		}
+		sErr.FC.Set("INF")
+		cnt, err = message.NewSTAContent(fmt.Errorf("wrapped: %w", sErr))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cnt.Code.String()).Should(Equal("244"))
+		Ω(cnt.Description).Should(Equal("Expected SUP"))
+		Ω(cnt.Flags).Should(Equal(map[string]string{"FC": "INF"}))
+	})
+})
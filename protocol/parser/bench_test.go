@@ -0,0 +1,124 @@
+package parser_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/parser"
+)
+
+var benchmarkLines = []struct {
+	name string
+	line string
+}{
+	{"BSCH", "BSCH AAAB ANfoo ANbar NOmp3 EXflac TOtoken"},
+	{"FSCH", "FSCH AAAB +TCP4 TRLWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ TOtoken"},
+	{"DRES", "DRES AAAB AAAC FN/music/some\\sartist/song.flac SI12345678 SL3 TOtoken TRLWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"},
+	{"BINF", "BINF AAAB IDLWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ NIsome\\snick SS1024 SF12 HN1 HR0 HO0 SL3 I4192.168.1.1 SUTCP4,UDP4 VEadcl\\s0.1"},
+	{"Generic", "BZZZ AAAB foo bar XYbaz"},
+}
+
+// repeatReader provides the same content over and over again.
+type repeatReader struct {
+	data []byte
+	off  int
+}
+
+func (r *repeatReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		c := copy(p[n:], r.data[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.data)
+	}
+	return
+}
+
+func newRepeatReader(line string) *bufio.Reader {
+	return bufio.NewReaderSize(&repeatReader{data: []byte(line + "\n")}, 64<<10)
+}
+
+func BenchmarkParserReadMessage(b *testing.B) {
+	for _, bench := range benchmarkLines {
+		b.Run(bench.name, func(b *testing.B) {
+			p := New(newRepeatReader(bench.line))
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bench.line) + 1))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := p.ReadMessage(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParserReadMessageInto(b *testing.B) {
+	for _, bench := range benchmarkLines {
+		b.Run(bench.name, func(b *testing.B) {
+			p := New(newRepeatReader(bench.line))
+			var mes message.Message
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bench.line) + 1))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if err := p.ReadMessageInto(&mes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkParserReadMessageIntoMixed reads messages with alternating
+// commands, so that contents are obtained from the pools.
+func BenchmarkParserReadMessageIntoMixed(b *testing.B) {
+	var data bytes.Buffer
+	for _, bench := range benchmarkLines {
+		data.WriteString(bench.line + "\n")
+	}
+
+	p := New(bufio.NewReaderSize(&repeatReader{data: data.Bytes()}, 64<<10))
+	var mes message.Message
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := p.ReadMessageInto(&mes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConnReaderReadMessageLine(b *testing.B) {
+	connReader := NewConnReader(newRepeatReader(benchmarkLines[0].line))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := connReader.ReadMessageLine(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConnReaderReadMessageBytes(b *testing.B) {
+	connReader := NewConnReader(newRepeatReader(benchmarkLines[0].line))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := connReader.ReadMessageBytes(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -53,6 +53,20 @@ func (c *ConnReader) Reset(r *bufio.Reader) {
 // During reading, the first 5 bytes of the message are validated using
 // validateMessage().
 func (c *ConnReader) ReadMessageLine() (line string, err error) {
+	buf, err := c.ReadMessageBytes()
+	if err != nil {
+		return
+	}
+
+	line = string(buf)
+	return
+}
+
+// ReadMessageBytes is like ReadMessageLine, but returns the line as a byte
+// slice instead of a string. This avoids allocating and copying the line. The
+// returned slice is only valid until the next call of ReadMessageBytes or
+// ReadMessageLine.
+func (c *ConnReader) ReadMessageBytes() (line []byte, err error) {
 	var buf, lineBuf []byte
 	lineBuf = c.lineBuf
 
@@ -71,16 +85,21 @@ func (c *ConnReader) ReadMessageLine() (line string, err error) {
 	if len(buf) > MaxMessageLength {
 		err = ErrMessageTooLong
 
-		// Read ahead until line ending
-		_ = c.discardLine()
+		if buf[len(buf)-1] != eol {
+			// Read ahead until line ending
+			_ = c.discardLine()
+		}
 
 		return
 	}
 
 	if len(buf) >= 5 {
-		if err = c.validateMessage(buf); err != nil {
-			_ = c.discardLine()
+		if vErr := c.validateMessage(buf); vErr != nil {
+			if buf[len(buf)-1] != eol {
+				_ = c.discardLine()
+			}
 
+			err = vErr
 			return
 		}
 	}
@@ -88,15 +107,13 @@ func (c *ConnReader) ReadMessageLine() (line string, err error) {
 	if err == nil {
 		// Fast path: The whole message fit into the buffer.
 
-		line = string(buf[:len(buf)-1])
+		line = buf[:len(buf)-1]
 		return
 	}
 
+	// Slow path: The message is assembled in lineBuf.
 	for {
 		lineBuf = append(lineBuf, buf...)
-		// Write reference to slice back into ConnReader, as lineBuf might have
-		// been extended
-		c.lineBuf = lineBuf[0:0]
 
 		if err == nil {
 			break
@@ -104,7 +121,11 @@ func (c *ConnReader) ReadMessageLine() (line string, err error) {
 
 		buf, err = c.r.ReadSlice(eol)
 
-		if err != bufio.ErrBufferFull {
+		if err != nil && err != bufio.ErrBufferFull {
+			// Write reference to slice back into ConnReader, as lineBuf might
+			// have been extended
+			c.lineBuf = lineBuf[0:0]
+
 			if err != io.EOF {
 				// Try to read ahead until line ending
 				_ = c.discardLine()
@@ -114,16 +135,21 @@ func (c *ConnReader) ReadMessageLine() (line string, err error) {
 		}
 
 		if len(lineBuf)+len(buf) > MaxMessageLength {
+			c.lineBuf = lineBuf[0:0]
 			err = ErrMessageTooLong
 
-			// Read ahead until line ending
-			_ = c.discardLine()
+			if buf[len(buf)-1] != eol {
+				// Read ahead until line ending
+				_ = c.discardLine()
+			}
 
 			return
 		}
 	}
 
-	line = string(lineBuf[:len(lineBuf)-1])
+	c.lineBuf = lineBuf[0:0]
+
+	line = lineBuf[:len(lineBuf)-1]
 	return
 }
 
@@ -151,7 +177,8 @@ func (c *ConnReader) validateMessage(buf []byte) error {
 		return ErrInvalidMessage
 	}
 
-	if buf[4] != space {
+	// A message without any parameters ends directly after the command.
+	if buf[4] != space && buf[4] != eol {
 		return ErrInvalidMessage
 	}
 
@@ -0,0 +1,55 @@
+package parser_test
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// readLines reads all lines from s using a ConnReader with the minimal buffer
+// size, returning the lines and errors read until io.EOF.
+func readLines(s string) (lines []string, errs []error) {
+	connReader := NewConnReader(bufio.NewReaderSize(strings.NewReader(s), 16))
+
+	for {
+		line, err := connReader.ReadMessageLine()
+		if err == io.EOF {
+			return
+		}
+		lines = append(lines, line)
+		errs = append(errs, err)
+	}
+}
+
+var _ = Describe("ConnReader", func() {
+	Describe("ReadMessageLine() - Reading message lines", func() {
+		It("should read lines exceeding the buffer size", func() {
+			lines, errs := readLines("BINF AAAB NIsome\\slonger\\snick\nHSUP ADBASE\n")
+			Ω(lines).Should(Equal([]string{"BINF AAAB NIsome\\slonger\\snick", "HSUP ADBASE"}))
+			Ω(errs).Should(Equal([]error{nil, nil}))
+		})
+
+		It("should read messages without parameters", func() {
+			lines, errs := readLines("HQUI\n")
+			Ω(lines).Should(Equal([]string{"HQUI"}))
+			Ω(errs).Should(Equal([]error{nil}))
+		})
+
+		It("should discard incomplete lines at the end of input", func() {
+			lines, _ := readLines("HSUP ADBASE\nBINF AAAB NIincomplete")
+			Ω(lines).Should(Equal([]string{"HSUP ADBASE"}))
+		})
+
+		It("should only discard the invalid line", func() {
+			lines, errs := readLines("XINF AAAB\nHSUP ADBASE\n")
+			Ω(lines).Should(Equal([]string{"", "HSUP ADBASE"}))
+			Ω(errs).Should(Equal([]error{message.ErrInvalidType, nil}))
+		})
+	})
+})
@@ -0,0 +1,561 @@
+package parser_test
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/protocol/writer"
+)
+
+// The Fuzz... functions are run with their seed corpus by go test. Run
+//     go test -run '^$' -fuzz FuzzMessageRoundTrip ./protocol/parser
+// to fuzz one of them.
+
+var validTypes = []message.Type{
+	message.TypeBroadcast,
+	message.TypeClientmessage,
+	message.TypeDirectmessage,
+	message.TypeEchomessage,
+	message.TypeFeaturebroadcast,
+	message.TypeHubmessage,
+	message.TypeInfomessage,
+	message.TypeUDPmessage,
+}
+
+var knownContents = map[message.Command]message.ParamAccessor{
+	message.CommandSTA: &message.STAContent{},
+	message.CommandSUP: &message.SUPContent{},
+	message.CommandSID: &message.SIDContent{},
+	message.CommandINF: &message.INFContent{},
+	message.CommandMSG: &message.MSGContent{},
+	message.CommandSCH: &message.SCHContent{},
+	message.CommandRES: &message.RESContent{},
+	message.CommandCTM: &message.CTMContent{},
+	message.CommandRCM: &message.RCMContent{},
+	message.CommandGPA: &message.GPAContent{},
+	message.CommandPAS: &message.PASContent{},
+	message.CommandQUI: &message.QUIContent{},
+	message.CommandGET: &message.GETContent{},
+	message.CommandGFI: &message.GFIContent{},
+	message.CommandSND: &message.SNDContent{},
+}
+
+var knownCommands = []message.Command{
+	message.CommandSTA, message.CommandSUP, message.CommandSID, message.CommandINF,
+	message.CommandMSG, message.CommandSCH, message.CommandRES, message.CommandCTM,
+	message.CommandRCM, message.CommandGPA, message.CommandPAS, message.CommandQUI,
+	message.CommandGET, message.CommandGFI, message.CommandSND,
+}
+
+func isUpper(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+func isUpperOrDigit(b byte) bool {
+	return isUpper(b) || (b >= '0' && b <= '9')
+}
+
+func FuzzParseType(f *testing.F) {
+	for _, b := range []byte("BCDEFHIUAZbc0 \n\x00\xff") {
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, b byte) {
+		typ, err := message.ParseType(b)
+
+		valid := strings.IndexByte("BCDEFHIU", b) != -1
+		if valid != (err == nil) {
+			t.Fatalf("ParseType(%q) returned error %v", b, err)
+		}
+		if err == nil && byte(typ) != b {
+			t.Fatalf("ParseType(%q) returned %q", b, byte(typ))
+		}
+		if err != nil && err != message.ErrInvalidType {
+			t.Fatalf("ParseType(%q) returned unexpected error %v", b, err)
+		}
+	})
+}
+
+func FuzzParseCommand(f *testing.F) {
+	for _, s := range []string{"STA", "INF", "ZZZ", "A00", "0AB", "AbC", "ABCD", "AB", "", "SCH ", "\x00\x00\x00"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		cmd, known, err := message.ParseCommand(s)
+
+		valid := len(s) == 3 && isUpper(s[0]) && isUpperOrDigit(s[1]) && isUpperOrDigit(s[2])
+		if valid != (err == nil) {
+			t.Fatalf("ParseCommand(%q) returned error %v", s, err)
+		}
+		if err != nil {
+			if err != message.ErrInvalidCommandName {
+				t.Fatalf("ParseCommand(%q) returned unexpected error %v", s, err)
+			}
+			return
+		}
+
+		if string(cmd) != s {
+			t.Fatalf("ParseCommand(%q) returned %q", s, cmd)
+		}
+
+		isKnown := false
+		for _, knownCmd := range knownCommands {
+			if knownCmd == cmd {
+				isKnown = true
+			}
+		}
+		if known != isKnown {
+			t.Fatalf("ParseCommand(%q) returned known = %t", s, known)
+		}
+	})
+}
+
+func FuzzParseHeaderFields(f *testing.F) {
+	f.Add(byte('B'), "AAAB")
+	f.Add(byte('D'), "AAAB AAAC")
+	f.Add(byte('E'), "AAAB")
+	f.Add(byte('F'), "AAAB +TIGR-ZLIG")
+	f.Add(byte('F'), "AAAB +TIG")
+	f.Add(byte('U'), "LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ")
+	f.Add(byte('I'), "")
+	f.Add(byte('X'), "AAAB")
+
+	f.Fuzz(func(t *testing.T, typ byte, s string) {
+		fields, err := ParseHeaderFields(NewMessageReader(s), message.Type(typ))
+		if err != nil {
+			return
+		}
+
+		var m writer.MessageWriter
+		err = writer.WriteHeaderFields(&m, message.Type(typ), fields)
+		if err != nil {
+			t.Fatalf("WriteHeaderFields() failed for fields %#v parsed from %q: %v", fields, s, err)
+		}
+
+		reparsed, err := ParseHeaderFields(NewMessageReader(m.String()), message.Type(typ))
+		if err != nil {
+			t.Fatalf("re-parsing header fields %q failed: %v", m.String(), err)
+		}
+
+		if !equalValues(reflect.ValueOf(fields), reflect.ValueOf(reparsed)) {
+			t.Fatalf("header fields %#v parsed from %q differ from re-parsed %#v", fields, s, reparsed)
+		}
+	})
+}
+
+func FuzzReadMessageLine(f *testing.F) {
+	f.Add([]byte("BINF AAAB NIfoo\n"))
+	f.Add([]byte("BINF AAAB\nHSUP ADBASE\n\n"))
+	f.Add([]byte("XINF AAAB\nHSUP ADBASE\nHSUP"))
+	f.Add([]byte("BINF\n"))
+	f.Add([]byte(strings.Repeat("a", 40) + "\nHSUP ADBASE\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// A small buffer exercises the slow path of ReadMessageLine.
+		connReader := NewConnReader(bufio.NewReaderSize(bytes.NewReader(data), 16))
+
+		// Each call consumes at least one byte, unless the end is reached.
+		for i := 0; i <= len(data); i++ {
+			line, err := connReader.ReadMessageLine()
+			if err != nil && err != ErrMessageTooLong && err != ErrInvalidMessage &&
+				err != message.ErrInvalidType && err != message.ErrInvalidCommandName {
+				return
+			}
+
+			if strings.IndexByte(line, '\n') != -1 {
+				t.Fatalf("line %q contains end-of-line character", line)
+			}
+			if len(line) >= MaxMessageLength {
+				t.Fatalf("line of length %d exceeds MaxMessageLength", len(line))
+			}
+			if err == nil && !bytes.Contains(data, []byte(line+"\n")) {
+				t.Fatalf("line %q is not contained in input", line)
+			}
+		}
+
+		t.Fatalf("ReadMessageLine() did not reach the end of input %q", data)
+	})
+}
+
+func FuzzParseMessage(f *testing.F) {
+	for _, line := range []string{
+		"BINF AAAB NIfoo SS1024 SU" + "TCP4,UDP4",
+		"ISTA 144 Invalid\\sparameter TOqwertzu",
+		"HSUP ADBASE ADTIGR",
+		"FSCH AAAB +TCP4-NAT0 ANfoo NObar EXmp3 TRAAAA TD2",
+		"DRES AAAB AAAC FNfoo SI12 SL3 TOtoken",
+		"BZZZ AAAB foo bar",
+		"ZZZ",
+		"BINF AAAB I4256.0.0.1",
+	} {
+		f.Add(line)
+	}
+
+	f.Fuzz(func(t *testing.T, line string) {
+		mes, err := parseLine(line)
+		if err != nil {
+			return
+		}
+
+		formatted, err := writer.FormatMessage(&mes)
+		if err != nil {
+			// Not everything accepted by the parser may be written, e.g.
+			// parameters containing end-of-line characters.
+			return
+		}
+
+		reparsed, err := parseLine(strings.TrimSuffix(formatted, "\n"))
+		if err != nil {
+			t.Fatalf("re-parsing %q (from %q) failed: %v", formatted, line, err)
+		}
+
+		reformatted, err := writer.FormatMessage(&reparsed)
+		if err != nil {
+			t.Fatalf("re-formatting %q failed: %v", formatted, err)
+		}
+		if reformatted != formatted {
+			t.Fatalf("re-formatting %q yielded %q", formatted, reformatted)
+		}
+	})
+}
+
+func FuzzMessageRoundTrip(f *testing.F) {
+	for seed := int64(0); seed < 200; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		mes := randomMessage(rand.New(rand.NewSource(seed)))
+
+		formatted, err := writer.FormatMessage(mes)
+		if err != nil {
+			t.Fatalf("FormatMessage() failed for %#v: %v", mes, err)
+		}
+
+		connReader := NewConnReader(bufio.NewReader(strings.NewReader(formatted)))
+		line, err := connReader.ReadMessageLine()
+		if err != nil {
+			t.Fatalf("ReadMessageLine() failed for %q: %v", formatted, err)
+		}
+
+		parsed, err := parseLine(line)
+		if err != nil {
+			t.Fatalf("ParseMessage() failed for %q: %v", line, err)
+		}
+
+		if !equalValues(reflect.ValueOf(*mes), reflect.ValueOf(parsed)) {
+			t.Fatalf("message %#v parsed from %q differs from original %#v", parsed, line, mes)
+		}
+	})
+}
+
+// randomMessage returns a random, valid message. Its content is either a
+// GenericContent (for unknown commands) or a typed content with all exported
+// fields set randomly.
+func randomMessage(r *rand.Rand) *message.Message {
+	mes := &message.Message{
+		Type: validTypes[r.Intn(len(validTypes))],
+	}
+
+	switch mes.Type {
+	case message.TypeBroadcast:
+		mes.HeaderFields = message.BroadcastHeaderFields{MySID: randomSID(r)}
+	case message.TypeDirectmessage:
+		mes.HeaderFields = message.DirectHeaderFields{MySID: randomSID(r), TargetSID: randomSID(r)}
+	case message.TypeEchomessage:
+		mes.HeaderFields = message.EchoHeaderFields{MySID: randomSID(r), TargetSID: randomSID(r)}
+	case message.TypeFeaturebroadcast:
+		mes.HeaderFields = message.FeatureHeaderFields{MySID: randomSID(r), Features: randomFeatureOps(r)}
+	case message.TypeUDPmessage:
+		mes.HeaderFields = message.UDPHeaderFields{MyCID: randomBase32(r, 24)}
+	case message.TypeClientmessage:
+		mes.HeaderFields = message.ClientHeaderFields{}
+	case message.TypeHubmessage:
+		mes.HeaderFields = message.HubHeaderFields{}
+	case message.TypeInfomessage:
+		mes.HeaderFields = message.InfoHeaderFields{}
+	}
+
+	if r.Intn(4) == 0 {
+		mes.Command = randomUnknownCommand(r)
+		mes.Content = randomGenericContent(r)
+		return mes
+	}
+
+	mes.Command = knownCommands[r.Intn(len(knownCommands))]
+
+	cnt := reflect.New(reflect.TypeOf(knownContents[mes.Command]).Elem())
+	fillRandom(r, cnt.Elem())
+
+	if sup, ok := cnt.Interface().(*message.SUPContent); ok {
+		// SUP does not allow any named parameters besides AD and RM.
+		sup.Flags = nil
+	}
+
+	mes.Content = cnt.Interface().(message.ParamAccessor)
+
+	return mes
+}
+
+const base32Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+func randomSID(r *rand.Rand) *encoding.Base32Value {
+	sid := make([]byte, 4)
+	for i := range sid {
+		sid[i] = base32Alphabet[r.Intn(len(base32Alphabet))]
+	}
+
+	val, err := encoding.ParseBase32Value(string(sid))
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+func randomBase32(r *rand.Rand, n int) *encoding.Base32Value {
+	raw := make([]byte, n)
+	r.Read(raw)
+
+	return encoding.NewBase32Value(raw)
+}
+
+func randomFeature(r *rand.Rand) string {
+	feature := []byte{byte('A' + r.Intn(26)), 0, 0, 0}
+	for i := 1; i < len(feature); i++ {
+		if r.Intn(4) == 0 {
+			feature[i] = byte('0' + r.Intn(10))
+		} else {
+			feature[i] = byte('A' + r.Intn(26))
+		}
+	}
+
+	return string(feature)
+}
+
+func randomFeatureOps(r *rand.Rand) []message.FeatureOp {
+	ops := make([]message.FeatureOp, 1+r.Intn(3))
+	for i := range ops {
+		ops[i].Feature = randomFeature(r)
+		if r.Intn(2) == 0 {
+			ops[i].OpAction = message.FeatureOpRemove
+		}
+	}
+
+	return ops
+}
+
+func randomUnknownCommand(r *rand.Rand) message.Command {
+	for {
+		cmd := message.Command(randomFeature(r)[:3])
+		// PMI is registered by the RegisterContentParser tests.
+		if _, known, _ := message.ParseCommand(string(cmd)); !known && cmd != "PMI" {
+			return cmd
+		}
+	}
+}
+
+// randomString returns a non-empty string, which includes characters
+// requiring escaping.
+func randomString(r *rand.Rand) string {
+	const chars = "abcXYZ019 \n\\,äö€"
+
+	runes := []rune(chars)
+	s := make([]rune, 1+r.Intn(12))
+	for i := range s {
+		s[i] = runes[r.Intn(len(runes))]
+	}
+
+	return string(s)
+}
+
+// randomFlags returns random additional flags. Their names start with X,
+// which no known named parameter does.
+func randomFlags(r *rand.Rand) map[string]string {
+	n := r.Intn(3)
+	if n == 0 {
+		return nil
+	}
+
+	flags := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		name := "X" + string(rune('0'+r.Intn(10)))
+		value, _ := encoding.EncodeToADCString(randomString(r))
+		flags[name] = value
+	}
+
+	return flags
+}
+
+func randomGenericContent(r *rand.Rand) *message.GenericContent {
+	cnt := &message.GenericContent{
+		PositionalParams: make([]string, r.Intn(4)),
+	}
+	for i := range cnt.PositionalParams {
+		cnt.PositionalParams[i], _ = encoding.EncodeToADCString(randomString(r))
+	}
+
+	return cnt
+}
+
+var (
+	base32ValuePtrType = reflect.TypeOf((*encoding.Base32Value)(nil))
+	ipType             = reflect.TypeOf(net.IP(nil))
+	statusCodeType     = reflect.TypeOf(message.StatusCode{})
+	featureOpsType     = reflect.TypeOf([]message.FeatureOp(nil))
+	searchTermsType    = reflect.TypeOf([]message.SearchTerm(nil))
+	stringsType        = reflect.TypeOf([]string(nil))
+	flagsType          = reflect.TypeOf(map[string]string(nil))
+)
+
+// fillRandom sets all exported fields of the content struct v to random
+// values.
+func fillRandom(r *rand.Rand, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		// maybe types
+		if field.Kind() == reflect.Struct && field.Type().PkgPath() == "github.com/seoester/adcl/protocol/maybe" {
+			if r.Intn(2) == 0 {
+				field.FieldByName("IsSet").SetBool(true)
+				field.FieldByName("Value").Set(randomValue(r, field.FieldByName("Value").Type()))
+			}
+			continue
+		}
+
+		field.Set(randomValue(r, field.Type()))
+	}
+}
+
+func randomValue(r *rand.Rand, typ reflect.Type) reflect.Value {
+	switch typ {
+	case base32ValuePtrType:
+		return reflect.ValueOf(randomBase32(r, 1+r.Intn(24)))
+	case ipType:
+		ip := make(net.IP, net.IPv6len)
+		r.Read(ip)
+		if r.Intn(2) == 0 {
+			ip = net.IPv4(ip[0], ip[1], ip[2], ip[3])
+		}
+		return reflect.ValueOf(ip)
+	case statusCodeType:
+		return reflect.ValueOf(message.StatusCode{
+			Severity: message.Severity(r.Intn(3)),
+			Error:    message.ErrorCode(r.Intn(100)),
+		})
+	case featureOpsType:
+		return reflect.ValueOf(randomFeatureOps(r))
+	case searchTermsType:
+		terms := make([]message.SearchTerm, 1+r.Intn(3))
+		for i := range terms {
+			terms[i].TermAction = message.SearchTermAction(1 + r.Intn(3))
+			terms[i].Term = randomString(r)
+		}
+		return reflect.ValueOf(terms)
+	case stringsType:
+		list := make([]string, 1+r.Intn(3))
+		for i := range list {
+			list[i] = randomFeature(r)
+		}
+		return reflect.ValueOf(list)
+	case flagsType:
+		return reflect.ValueOf(randomFlags(r))
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(randomString(r)).Convert(typ)
+	case reflect.Int:
+		return reflect.ValueOf(r.Intn(1 << 20)).Convert(typ)
+	case reflect.Float64:
+		return reflect.ValueOf(float64(r.Intn(1<<20)) / 4).Convert(typ)
+	}
+
+	panic("unsupported type " + typ.String())
+}
+
+// equalValues compares a and b deeply, ignoring unexported fields (the raw
+// parameter values) and comparing Base32Values by their string and IPs using
+// net.IP.Equal.
+func equalValues(a, b reflect.Value) bool {
+	if a.IsValid() != b.IsValid() {
+		return false
+	}
+	if !a.IsValid() {
+		return true
+	}
+
+	if a.Kind() == reflect.Interface {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Type() {
+	case base32ValuePtrType:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		return a.Interface().(*encoding.Base32Value).String() == b.Interface().(*encoding.Base32Value).String()
+	case ipType:
+		return a.Interface().(net.IP).Equal(b.Interface().(net.IP))
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).PkgPath != "" {
+				// unexported
+				continue
+			}
+			if !equalValues(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValues(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			if !equalValues(a.MapIndex(key), b.MapIndex(key)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
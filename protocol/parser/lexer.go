@@ -23,6 +23,17 @@ type Lexer struct {
 	offset int
 }
 
+// NewLexer creates a new Lexer reading the passed in string.
+//
+// Equivalent to:
+//     var lexer Lexer
+//     lexer.Reset(s)
+func NewLexer(s string) *Lexer {
+	l := &Lexer{}
+	l.Reset(s)
+	return l
+}
+
 // Reset sets string s as the input and resets the internal state. Afterwards,
 // Next() will return the first token in s.
 func (l *Lexer) Reset(s string) {
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"net"
 	"strconv"
 
 	"github.com/seoester/adcl/protocol/encoding"
@@ -10,6 +11,7 @@ import (
 // Error variables related to MessageReader.
 var (
 	ErrInvalidNamedParameter = errors.New("invalid named parameter, the parameter cannot be interpreted as a named parameter")
+	ErrInvalidIP             = errors.New("invalid IP address, the parameter cannot be interpreted as an IP address")
 )
 
 type MessageReader struct {
@@ -98,6 +100,18 @@ func (p *Positional) ValueBase32Bytes() ([]byte, error) {
 	return encoding.DecodeBase32String(p.RawValue())
 }
 
+func (p *Positional) ValueInt() (int, error) {
+	return strconv.Atoi(p.RawValue())
+}
+
+func (p *Positional) ValueIP() (net.IP, error) {
+	ip := net.ParseIP(p.RawValue())
+	if ip == nil {
+		return nil, ErrInvalidIP
+	}
+	return ip, nil
+}
+
 func (p *Positional) ValueUint64() (uint64, error) {
 	return strconv.ParseUint(p.RawValue(), 10, 64)
 }
@@ -146,6 +160,18 @@ func (n *Named) ValueBase32Bytes() ([]byte, error) {
 	return encoding.DecodeBase32String(n.RawValue())
 }
 
+func (n *Named) ValueInt() (int, error) {
+	return strconv.Atoi(n.RawValue())
+}
+
+func (n *Named) ValueIP() (net.IP, error) {
+	ip := net.ParseIP(n.RawValue())
+	if ip == nil {
+		return nil, ErrInvalidIP
+	}
+	return ip, nil
+}
+
 func (n *Named) ValueUint64() (uint64, error) {
 	return strconv.ParseUint(n.RawValue(), 10, 64)
 }
@@ -12,6 +12,8 @@ var (
 	ErrInvalidMessage         = errors.New("message invalid")
 	ErrIncompleteMessage      = errors.New("message incomplete, required elements are missing")
 	ErrInvalidFeatureEncoding = errors.New("feature invalid encoded in feature broadcast header")
+	ErrInvalidFeatureOp       = errors.New("feature operation invalid, it is not of the form ADxxxx or RMxxxx")
+	ErrInvalidSearchTerm      = errors.New("search term invalid, it is not an AN, NO or EX parameter")
 )
 
 // Constants which are used throughout the parser package.
@@ -34,6 +36,27 @@ func ParseMessage(m *MessageReader) (mes message.Message, err error) {
 	return
 }
 
+// ParseMessageInto is like ParseMessage, but parses into mes. The content of
+// mes is reused if it has the content type of the parsed message, otherwise it
+// is released (see ParseContentInto). Hence, the previous content of mes must
+// not be used anymore after calling ParseMessageInto.
+func ParseMessageInto(m *MessageReader, mes *message.Message) (err error) {
+	cnt := mes.Content
+
+	*mes, err = ParseHeader(m)
+	if err != nil {
+		ReleaseContent(cnt)
+		return
+	}
+
+	mes.Content, err = ParseContentInto(m, mes.Command, cnt)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
 func ParseHeader(m *MessageReader) (mes message.Message, err error) {
 	fourcc, err := m.ReadPositional()
 	if err == io.EOF {
@@ -65,22 +88,45 @@ func ParseHeader(m *MessageReader) (mes message.Message, err error) {
 	return
 }
 
+// ParseHeaderFields parses the additional header fields of a message of type
+// typ. The returned value has the header fields type declared for typ, e.g.
+// DirectHeaderFields for Directmessage and HubHeaderFields for Hubmessage.
 func ParseHeaderFields(m *MessageReader, typ message.Type) (message.HeaderFields, error) {
 	switch typ {
 	case message.TypeBroadcast:
 		return ParseBroadcastHeaderFields(m)
 	case message.TypeClientmessage:
-		return ParseCIHHeaderFields(m)
+		fields, err := ParseCIHHeaderFields(m)
+		if err != nil {
+			return nil, err
+		}
+		return message.ClientHeaderFields(fields), nil
 	case message.TypeDirectmessage:
-		return ParseDEHeaderFields(m)
+		fields, err := ParseDEHeaderFields(m)
+		if err != nil {
+			return nil, err
+		}
+		return message.DirectHeaderFields(fields), nil
 	case message.TypeEchomessage:
-		return ParseDEHeaderFields(m)
+		fields, err := ParseDEHeaderFields(m)
+		if err != nil {
+			return nil, err
+		}
+		return message.EchoHeaderFields(fields), nil
 	case message.TypeFeaturebroadcast:
 		return ParseFeatureHeaderFields(m)
 	case message.TypeHubmessage:
-		return ParseCIHHeaderFields(m)
+		fields, err := ParseCIHHeaderFields(m)
+		if err != nil {
+			return nil, err
+		}
+		return message.HubHeaderFields(fields), nil
 	case message.TypeInfomessage:
-		return ParseCIHHeaderFields(m)
+		fields, err := ParseCIHHeaderFields(m)
+		if err != nil {
+			return nil, err
+		}
+		return message.InfoHeaderFields(fields), nil
 	case message.TypeUDPmessage:
 		return ParseUDPHeaderFields(m)
 	default:
@@ -212,108 +258,16 @@ func ParseUDPHeaderFields(m *MessageReader) (fields message.UDPHeaderFields, err
 	return
 }
 
-func ParseContent(m *MessageReader, cmd message.Command) (cnt message.ParamAccessor, err error) {
-	switch cmd {
-	case message.CommandSTA:
-		mes, err := ParseSTAContent(m)
-		if err != nil {
-			return nil, err
-		}
-		return &mes, err
-	// case message.CommandSUP:
-	// 	mes, err := ParseSUPContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandSID:
-	// 	mes, err := ParseSIDContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandINF:
-	// 	mes, err := ParseINFContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandMSG:
-	// 	mes, err := ParseMSGContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandSCH:
-	// 	mes, err := ParseSCHContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandRES:
-	// 	mes, err := ParseRESContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandCTM:
-	// 	mes, err := ParseCTMContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandRCM:
-	// 	mes, err := ParseRCMContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandGPA:
-	// 	mes, err := ParseGPAContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandPAS:
-	// 	mes, err := ParsePASContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandQUI:
-	// 	mes, err := ParseQUIContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandGET:
-	// 	mes, err := ParseGETContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandGFI:
-	// 	mes, err := ParseGFIContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	// case message.CommandSND:
-	// 	mes, err := ParseSNDContent(m)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	return &mes, err
-	default:
-		mes, err := ParseGenericContent(m)
-		if err != nil {
-			return nil, err
-		}
-		return &mes, err
-	}
+func ParseGenericContent(m *MessageReader) (cnt message.GenericContent, err error) {
+	err = ParseGenericContentInto(m, &cnt)
+	return
 }
 
-func ParseGenericContent(m *MessageReader) (cnt message.GenericContent, err error) {
+// ParseGenericContentInto is like ParseGenericContent, but parses into cnt,
+// which is reset beforehand. Memory allocated by cnt is reused.
+func ParseGenericContentInto(m *MessageReader, cnt *message.GenericContent) (err error) {
+	cnt.Reset()
+
 	positional, err := m.ReadPositional()
 	for ; err == nil; positional, err = m.ReadPositional() {
 		cnt.PositionalParams = append(cnt.PositionalParams, positional.RawValue())
@@ -325,3 +279,53 @@ func ParseGenericContent(m *MessageReader) (cnt message.GenericContent, err erro
 
 	return
 }
+
+// ParseSUPFeatureOp parses a single positional parameter of a SUP message,
+// i.e. a feature name prefixed by AD (add) or RM (remove).
+func ParseSUPFeatureOp(s string) (op message.FeatureOp, err error) {
+	if len(s) != 6 {
+		return op, ErrInvalidFeatureOp
+	}
+
+	switch s[:2] {
+	case "AD":
+		op.OpAction = message.FeatureOpAdd
+	case "RM":
+		op.OpAction = message.FeatureOpRemove
+	default:
+		return op, ErrInvalidFeatureOp
+	}
+
+	if !(encoding.IsUpperAlpha(s[2]) &&
+		encoding.IsUpperAlphaNum(s[3]) &&
+		encoding.IsUpperAlphaNum(s[4]) &&
+		encoding.IsUpperAlphaNum(s[5])) {
+		return op, ErrInvalidFeatureOp
+	}
+
+	op.Feature = s[2:]
+
+	return
+}
+
+// ParseSearchTerm parses a search term named parameter of a SCH message, i.e.
+// an AN (include), NO (exclude) or EX (extension) parameter.
+func ParseSearchTerm(n Named) (term message.SearchTerm, err error) {
+	switch message.SCHFlag(n.Name()) {
+	case message.SCHFlagAN:
+		term.TermAction = message.SearchTermInclude
+	case message.SCHFlagNO:
+		term.TermAction = message.SearchTermExclude
+	case message.SCHFlagEX:
+		term.TermAction = message.SearchTermExtension
+	default:
+		return term, ErrInvalidSearchTerm
+	}
+
+	term.Term, err = n.ValueString()
+	if err != nil {
+		return
+	}
+
+	return
+}
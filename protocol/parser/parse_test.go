@@ -0,0 +1,256 @@
+package parser_test
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/parser"
+)
+
+func parseLine(line string) (message.Message, error) {
+	return ParseMessage(NewMessageReader(line))
+}
+
+// namedGet returns the value of the named parameter key, it fails if the
+// parameter is not set.
+func namedGet(cnt message.ParamAccessor, key string) string {
+	val, ok := cnt.NamedGet(key)
+	ExpectWithOffset(1, ok).Should(BeTrue())
+	return val
+}
+
+var _ = Describe("ParseContent() - Parsing typed message contents", func() {
+	It("should parse STA and populate the raw values", func() {
+		mes, err := parseLine("ISTA 144 Invalid\\sparameter TOqwertzu")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.STAContent)
+		Ω(cnt.Code.Code()).Should(Equal(144))
+		Ω(cnt.Description).Should(Equal("Invalid parameter"))
+		Ω(cnt.Positional()).Should(Equal([]string{"144", "Invalid\\sparameter"}))
+		Ω(namedGet(cnt, "TO")).Should(Equal("qwertzu"))
+	})
+
+	It("should parse SUP", func() {
+		mes, err := parseLine("HSUP ADBASE ADTIGR RMZLIF")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.SUPContent)
+		Ω(cnt.FeatureOps).Should(Equal([]message.FeatureOp{
+			{OpAction: message.FeatureOpAdd, Feature: "BASE"},
+			{OpAction: message.FeatureOpAdd, Feature: "TIGR"},
+			{OpAction: message.FeatureOpRemove, Feature: "ZLIF"},
+		}))
+		Ω(cnt.PosLen()).Should(Equal(3))
+		Ω(cnt.PosAt(2)).Should(Equal("RMZLIF"))
+
+		_, err = parseLine("HSUP ADBAS")
+		Ω(err).Should(Equal(ErrInvalidFeatureOp))
+	})
+
+	It("should parse SID", func() {
+		mes, err := parseLine("ISID AAAB")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.SIDContent)
+		Ω(cnt.SID.String()).Should(Equal("AAAB"))
+
+		_, err = parseLine("ISID")
+		Ω(err).Should(Equal(ErrIncompleteMessage))
+	})
+
+	It("should parse INF", func() {
+		mes, err := parseLine("BINF AAAB NIsome\\snick I40.0.0.0 SS1024 SUTCP4,UDP4 DE XXfoo")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.INFContent)
+		Ω(cnt.NI.Value).Should(Equal("some nick"))
+		Ω(cnt.I4.Value.Equal(net.IPv4zero)).Should(BeTrue())
+		Ω(cnt.SS.Value).Should(Equal(1024))
+		Ω(cnt.SU).Should(Equal([]string{"TCP4", "UDP4"}))
+		Ω(cnt.DE.IsSet).Should(BeFalse())
+		Ω(cnt.Flags).Should(Equal(map[string]string{"DE": "", "XX": "foo"}))
+
+		Ω(namedGet(cnt, "NI")).Should(Equal("some\\snick"))
+		Ω(namedGet(cnt, "DE")).Should(Equal(""))
+		Ω(cnt.Named()).Should(Equal(map[string]string{
+			"NI": "some\\snick",
+			"I4": "0.0.0.0",
+			"SS": "1024",
+			"SU": "TCP4,UDP4",
+			"DE": "",
+			"XX": "foo",
+		}))
+
+		_, err = parseLine("BINF AAAB SSabc")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should parse MSG", func() {
+		mes, err := parseLine("EMSG AAAB AAAC hello\\sworld PMAAAB ME1")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.MSGContent)
+		Ω(cnt.Text).Should(Equal("hello world"))
+		Ω(cnt.PM.Value.String()).Should(Equal("AAAB"))
+		Ω(cnt.ME.Value).Should(Equal(1))
+		Ω(namedGet(cnt, "PM")).Should(Equal("AAAB"))
+	})
+
+	It("should parse SCH", func() {
+		mes, err := parseLine("BSCH AAAB ANfoo NObar ANbaz EXmp3 TD3 TOtoken")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.SCHContent)
+		Ω(cnt.SearchTerms).Should(Equal([]message.SearchTerm{
+			{TermAction: message.SearchTermInclude, Term: "foo"},
+			{TermAction: message.SearchTermExclude, Term: "bar"},
+			{TermAction: message.SearchTermInclude, Term: "baz"},
+			{TermAction: message.SearchTermExtension, Term: "mp3"},
+		}))
+		Ω(cnt.TD.Value).Should(Equal(3))
+		Ω(cnt.PosLen()).Should(Equal(0))
+		Ω(namedGet(cnt, "AN")).Should(Equal("foo"))
+	})
+
+	It("should parse the size, token and type fields of SCH", func() {
+		mes, err := parseLine("FSCH AAAB +TCP4 ANfoo GE100 LE200 EQ150 TOsome\\stoken TY1 XXbar")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.SCHContent)
+		Ω(cnt.GE.Value).Should(Equal(100))
+		Ω(cnt.LE.Value).Should(Equal(200))
+		Ω(cnt.EQ.Value).Should(Equal(150))
+		Ω(cnt.TO.Value).Should(Equal("some token"))
+		Ω(cnt.TY.Value).Should(Equal(1))
+		Ω(cnt.Flags).Should(Equal(map[string]string{"XX": "bar"}))
+		Ω(namedGet(cnt, "TO")).Should(Equal("some\\stoken"))
+
+		_, err = parseLine("BSCH AAAB ANfoo LEbig")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should parse RES and require FN and SI", func() {
+		mes, err := parseLine("DRES AAAB AAAC FN/dir/file SI1234 SL3 TOtoken TRLWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.RESContent)
+		Ω(cnt.FN).Should(Equal("/dir/file"))
+		Ω(cnt.SI).Should(Equal(1234))
+		Ω(cnt.SL.Value).Should(Equal(3))
+		Ω(cnt.TO.Value).Should(Equal("token"))
+		Ω(cnt.TR.Value.String()).Should(Equal("LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"))
+		Ω(namedGet(cnt, "FN")).Should(Equal("/dir/file"))
+		_, ok := cnt.NamedGet("TD")
+		Ω(ok).Should(BeFalse())
+
+		// Responses to GFI do not contain a token.
+		mes, err = parseLine("CRES FN/dir/file SI1234")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content.(*message.RESContent).TO.IsSet).Should(BeFalse())
+
+		_, err = parseLine("DRES AAAB AAAC FN/dir/file TOtoken")
+		Ω(err).Should(Equal(ErrIncompleteMessage))
+	})
+
+	It("should parse CTM and RCM", func() {
+		mes, err := parseLine("DCTM AAAB AAAC ADC/1.0 4567 token")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ctm := mes.Content.(*message.CTMContent)
+		Ω(ctm.Protocol).Should(Equal("ADC/1.0"))
+		Ω(ctm.Port).Should(Equal("4567"))
+		Ω(ctm.Token).Should(Equal("token"))
+
+		mes, err = parseLine("DRCM AAAB AAAC ADC/1.0 token")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		rcm := mes.Content.(*message.RCMContent)
+		Ω(rcm.Protocol).Should(Equal("ADC/1.0"))
+		Ω(rcm.Token).Should(Equal("token"))
+	})
+
+	It("should parse GPA and PAS", func() {
+		mes, err := parseLine("IGPA ABCDEFGH")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content.(*message.GPAContent).Data.String()).Should(Equal("ABCDEFGH"))
+
+		mes, err = parseLine("HPAS ABCDEFGH")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content.(*message.PASContent).Password.String()).Should(Equal("ABCDEFGH"))
+	})
+
+	It("should parse QUI", func() {
+		mes, err := parseLine("IQUI AAAB MSkicked TL-1")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		cnt := mes.Content.(*message.QUIContent)
+		Ω(cnt.SID.String()).Should(Equal("AAAB"))
+		Ω(cnt.MS.Value).Should(Equal("kicked"))
+		Ω(cnt.TL.Value).Should(Equal(-1))
+	})
+
+	It("should parse GET, GFI and SND", func() {
+		mes, err := parseLine("CGET file TTH/LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ 0 -1 RE1 ZL1")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		get := mes.Content.(*message.GETContent)
+		Ω(get.Namespace).Should(Equal("file"))
+		Ω(get.Identifer).Should(Equal("TTH/LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"))
+		Ω(get.StartPos).Should(Equal(0))
+		Ω(get.Bytes).Should(Equal(-1))
+		Ω(get.RE.Value).Should(Equal(1))
+		Ω(get.Flags).Should(Equal(map[string]string{"ZL": "1"}))
+
+		mes, err = parseLine("CGFI file files.xml.bz2")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content.(*message.GFIContent).Identifer).Should(Equal("files.xml.bz2"))
+
+		mes, err = parseLine("CSND list / 0 1234")
+		Ω(err).ShouldNot(HaveOccurred())
+		snd := mes.Content.(*message.SNDContent)
+		Ω(snd.Namespace).Should(Equal("list"))
+		Ω(snd.Bytes).Should(Equal(1234))
+
+		_, err = parseLine("CSND list / 0")
+		Ω(err).Should(Equal(ErrIncompleteMessage))
+	})
+})
+
+var _ = Describe("ParseMessage() - Parsing complete messages", func() {
+	It("should parse unknown commands into GenericContent", func() {
+		mes, err := parseLine("BZZZ AAAB foo NIbar")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Command).Should(Equal(message.Command("ZZZ")))
+		Ω(mes.Content).Should(BeAssignableToTypeOf(&message.GenericContent{}))
+		Ω(mes.Content.PosLen()).Should(Equal(2))
+	})
+
+	It("should reject invalid command names", func() {
+		for _, line := range []string{"Bzzz AAAB", "B0ZZ AAAB", "BZ_Z AAAB"} {
+			_, err := parseLine(line)
+			Ω(err).Should(Equal(message.ErrInvalidCommandName), line)
+		}
+	})
+})
+
+var _ = Describe("ParseHeaderFields() - Parsing header fields", func() {
+	It("should return the header fields type declared for the message type", func() {
+		cases := map[string]message.HeaderFields{
+			"CMSG":           message.ClientHeaderFields{},
+			"HMSG":           message.HubHeaderFields{},
+			"IMSG":           message.InfoHeaderFields{},
+			"DMSG AAAB AAAC": message.DirectHeaderFields{},
+			"EMSG AAAB AAAC": message.EchoHeaderFields{},
+		}
+
+		for line, fields := range cases {
+			mes, err := parseLine(line + " hi")
+			Ω(err).ShouldNot(HaveOccurred(), line)
+			Ω(mes.HeaderFields).Should(BeAssignableToTypeOf(fields), line)
+		}
+	})
+})
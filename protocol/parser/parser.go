@@ -26,16 +26,35 @@
 // MessageReader to the ParseMessage() function, which in turn calls a bunch
 // of further Parse... functions for extracting message header and content.
 // The resulting Message is returned by ReadMessage().
+//
+// For high message rates, ReadMessageInto() parses into an existing Message.
+// Contents are reused or taken from per-type pools, thereby avoiding most of
+// the allocations of ReadMessage(). The line is read as byte slice using
+// ConnReader.ReadMessageBytes() and copied into larger chunks of memory
+// instead of being converted into a string of its own.
+// ConnReader.ReadMessageBytes() also gives access to the raw line without any
+// allocations, e.g. for forwarding messages.
 package parser
 
 import (
 	"bufio"
+	"unsafe"
 
 	"github.com/seoester/adcl/protocol/message"
 )
 
+// Constants related to Parser.
+const (
+	// lineChunkSize is the size of the chunks of memory ReadMessageInto
+	// copies lines into.
+	lineChunkSize = 4 << 10
+)
+
 type Parser struct {
-	connReader ConnReader
+	connReader    ConnReader
+	messageReader MessageReader
+	// lines is the chunk the lines read by ReadMessageInto are appended to.
+	lines []byte
 }
 
 // New creates a new Parser reading from the passed in bufio.Reader.
@@ -74,3 +93,48 @@ func (p *Parser) ReadMessage() (message.Message, error) {
 
 	return mes, nil
 }
+
+// ReadMessageInto reads the next Message from the underlying reader into mes.
+// One line is consumed in any case.
+//
+// In contrast to ReadMessage, the content of mes is reused or released into a
+// pool (see ParseMessageInto). When reading into the same Message repeatedly,
+// this mostly avoids allocations for the content. The previous content of mes
+// must not be used anymore after calling ReadMessageInto.
+//
+// Strings of the parsed message refer to a chunk of memory shared by several
+// lines, so that no allocation is needed per line. A string retained from the
+// message keeps its whole chunk (a few KiB) alive.
+func (p *Parser) ReadMessageInto(mes *message.Message) error {
+	line, err := p.connReader.ReadMessageBytes()
+	if err != nil {
+		return err
+	}
+
+	p.messageReader.Reset(p.lineString(line))
+
+	return ParseMessageInto(&p.messageReader, mes)
+}
+
+// lineString returns a string with the contents of line. line is appended to
+// the current chunk, which is replaced by a new one if line does not fit.
+// Chunks are never written to at already used positions, so the returned
+// strings stay immutable.
+func (p *Parser) lineString(line []byte) string {
+	if len(line) == 0 {
+		return ""
+	}
+
+	if len(line) > cap(p.lines)-len(p.lines) {
+		size := lineChunkSize
+		if len(line) > size {
+			size = len(line)
+		}
+		p.lines = make([]byte, 0, size)
+	}
+
+	start := len(p.lines)
+	p.lines = append(p.lines, line...)
+
+	return unsafe.String(&p.lines[start], len(line))
+}
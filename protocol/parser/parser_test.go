@@ -0,0 +1,86 @@
+package parser_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parser", func() {
+	Describe("ReadMessageInto() - Reading messages into a reused Message", func() {
+		It("should reuse the content if the command does not change", func() {
+			p := New(bufio.NewReader(strings.NewReader(
+				"BSCH AAAB ANfoo ANbar XYbaz TOtoken\nBSCH AAAC EXflac\n",
+			)))
+			var mes message.Message
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			first := mes.Content.(*message.SCHContent)
+			Ω(first.SearchTerms).Should(HaveLen(2))
+			Ω(first.Flags).Should(HaveKeyWithValue("XY", "baz"))
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			Ω(mes.HeaderFields.(message.BroadcastHeaderFields).MySID.String()).Should(Equal("AAAC"))
+			Ω(mes.Content).Should(BeIdenticalTo(first))
+			Ω(first.SearchTerms).Should(Equal([]message.SearchTerm{
+				{TermAction: message.SearchTermExtension, Term: "flac"},
+			}))
+			Ω(first.Flags).Should(BeEmpty())
+			Ω(first.Named()).Should(Equal(map[string]string{"EX": "flac"}))
+		})
+
+		It("should parse messages with changing commands", func() {
+			p := New(bufio.NewReader(strings.NewReader(
+				"BINF AAAB NIfoo\nBZZZ AAAB bar\nBINF AAAB NIbaz\nBINF\nBZZZ AAAB qux\n",
+			)))
+			var mes message.Message
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			Ω(mes.Content.(*message.INFContent).NI.Value).Should(Equal("foo"))
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			Ω(mes.Content.Positional()).Should(Equal([]string{"bar"}))
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			Ω(mes.Content.(*message.INFContent).NI.Value).Should(Equal("baz"))
+
+			Ω(p.ReadMessageInto(&mes)).Should(Equal(ErrIncompleteMessage))
+			Ω(mes.Content).Should(BeNil())
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			Ω(mes.Content.Positional()).Should(Equal([]string{"qux"}))
+		})
+
+		It("should keep strings of previous messages intact", func() {
+			p := New(newRepeatReader("BINF AAAB NIfoo"))
+			var mes message.Message
+
+			Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			nick := mes.Content.(*message.INFContent).NI.Value
+
+			// Enough lines to fill several chunks.
+			for i := 0; i < 1000; i++ {
+				Ω(p.ReadMessageInto(&mes)).Should(Succeed())
+			}
+			Ω(nick).Should(Equal("foo"))
+		})
+
+		It("should not allocate per line", func() {
+			for _, line := range []string{"BSCH AAAB ANfoo ANbar NOmp3 EXflac TOtoken", "BZZZ AAAB foo bar XYbaz"} {
+				p := New(newRepeatReader(line))
+				var mes message.Message
+
+				allocs := testing.AllocsPerRun(1000, func() {
+					_ = p.ReadMessageInto(&mes)
+				})
+				Ω(allocs).Should(BeNumerically("<=", 1), line)
+			}
+		})
+	})
+})
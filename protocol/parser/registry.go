@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// ContentParserFunc parses the parameters of a message. It is called with a
+// MessageReader positioned after the message header.
+type ContentParserFunc func(m *MessageReader) (message.ParamAccessor, error)
+
+var (
+	contentParsersMu sync.RWMutex
+	contentParsers   = make(map[message.Command]ContentParserFunc)
+)
+
+// RegisterContentParser makes parse available as the parser for messages with
+// command cmd. This allows applications to obtain typed contents for commands
+// defined by extensions, e.g. CMD (UCMD) or NAT and RNT (NATT). ParseContent
+// (and thus ParseMessage and Parser.ReadMessage) calls parse for all messages
+// with the command cmd instead of ParseGenericContent.
+//
+// RegisterContentParser is meant to be called from init functions. It panics
+// if cmd is not a valid command name, is a command known to this package, if
+// a parser for cmd has already been registered or if parse is nil.
+func RegisterContentParser(cmd message.Command, parse ContentParserFunc) {
+	_, known, err := message.ParseCommand(string(cmd))
+	if err != nil {
+		panic("parser: RegisterContentParser with invalid command " + string(cmd))
+	}
+	if known {
+		panic("parser: RegisterContentParser with known command " + string(cmd))
+	}
+	if parse == nil {
+		panic("parser: RegisterContentParser with nil parse function")
+	}
+
+	contentParsersMu.Lock()
+	defer contentParsersMu.Unlock()
+
+	if _, dup := contentParsers[cmd]; dup {
+		panic("parser: RegisterContentParser called twice for command " + string(cmd))
+	}
+	contentParsers[cmd] = parse
+}
+
+// registeredContentParser returns the parser registered for cmd by
+// RegisterContentParser.
+func registeredContentParser(cmd message.Command) (parse ContentParserFunc, ok bool) {
+	contentParsersMu.RLock()
+	parse, ok = contentParsers[cmd]
+	contentParsersMu.RUnlock()
+
+	return
+}
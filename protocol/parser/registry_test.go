@@ -0,0 +1,71 @@
+package parser_test
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// pmiContent is the content of the (fictional) PMI extension command used for
+// testing RegisterContentParser.
+type pmiContent struct {
+	message.GenericContent
+
+	Text string
+}
+
+func parsePMIContent(m *MessageReader) (message.ParamAccessor, error) {
+	param, err := m.ReadPositional()
+	if err == io.EOF {
+		return nil, ErrIncompleteMessage
+	} else if err != nil {
+		return nil, err
+	}
+
+	cnt := &pmiContent{}
+	cnt.PositionalParams = []string{param.RawValue()}
+	cnt.Text, err = param.ValueString()
+	if err != nil {
+		return nil, err
+	}
+
+	return cnt, nil
+}
+
+func init() {
+	RegisterContentParser("PMI", parsePMIContent)
+}
+
+var _ = Describe("RegisterContentParser() - Registering parsers for extension commands", func() {
+	It("should be used by Parser.ReadMessage", func() {
+		p := New(bufio.NewReader(strings.NewReader("BPMI AAAB hello\\sworld\nBPMI AAAB\n")))
+
+		mes, err := p.ReadMessage()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Command).Should(Equal(message.Command("PMI")))
+		Ω(mes.Content).Should(BeAssignableToTypeOf(&pmiContent{}))
+		Ω(mes.Content.(*pmiContent).Text).Should(Equal("hello world"))
+
+		_, err = p.ReadMessage()
+		Ω(err).Should(Equal(ErrIncompleteMessage))
+	})
+
+	It("should not affect other unknown commands", func() {
+		mes, err := parseLine("BPMX AAAB hello")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content).Should(BeAssignableToTypeOf(&message.GenericContent{}))
+	})
+
+	It("should panic on invalid registrations", func() {
+		Ω(func() { RegisterContentParser("PMI", parsePMIContent) }).Should(Panic())
+		Ω(func() { RegisterContentParser("INF", parsePMIContent) }).Should(Panic())
+		Ω(func() { RegisterContentParser("pmi", parsePMIContent) }).Should(Panic())
+		Ω(func() { RegisterContentParser("PMJ", nil) }).Should(Panic())
+	})
+})
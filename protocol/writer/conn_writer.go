@@ -0,0 +1,210 @@
+package writer
+
+import (
+	"bufio"
+	"sync"
+	"time"
+
+	"github.com/seoester/adcl/protocol/parser"
+)
+
+// Constants related to ConnWriter.
+const (
+	// MaxMessageLength is the maximum length a message may have, including
+	// the concluding end-of-line character. It is equal to the limit enforced
+	// by parser.ConnReader.
+	MaxMessageLength int = parser.MaxMessageLength
+)
+
+// Error variables related to ConnWriter.
+var (
+	ErrMessageTooLong = parser.ErrMessageTooLong
+)
+
+// FlushPolicy configures when a ConnWriter flushes buffered messages to the
+// underlying writer.
+//
+// The zero value flushes after every message, i.e. disables batching.
+type FlushPolicy struct {
+	// MaxLatency is the maximum duration a message is buffered before it is
+	// flushed. If MaxLatency is zero, the buffer is flushed after every
+	// message.
+	MaxLatency time.Duration
+	// MaxSize is the number of buffered bytes at which the buffer is flushed
+	// immediately, regardless of MaxLatency. If MaxSize is zero, only
+	// MaxLatency and the size of the bufio.Writer trigger a flush.
+	MaxSize int
+}
+
+// ConnWriter is a helper to write ADC messages to a bufio.Writer. It is the
+// counterpart of parser.ConnReader.
+//
+// ConnWriter batches messages according to its FlushPolicy so that multiple
+// messages are written to the underlying connection with a single write
+// call. Flushes triggered by MaxLatency happen on a separate goroutine, an
+// error occurring during such a flush is returned by the next call to a
+// ConnWriter method.
+//
+// ConnWriter is safe for concurrent use.
+type ConnWriter struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	policy FlushPolicy
+	timer  *time.Timer
+	// pending is true if the timer is armed and the buffer contains messages
+	// which have not been flushed.
+	pending bool
+	err     error
+}
+
+// NewConnWriter creates a new ConnWriter writing to the passed in
+// bufio.Writer and flushing according to policy.
+func NewConnWriter(w *bufio.Writer, policy FlushPolicy) *ConnWriter {
+	return &ConnWriter{
+		w:      w,
+		policy: policy,
+	}
+}
+
+// Reset sets w as the writer and resets the internal state. Messages buffered
+// by the previous writer are discarded.
+func (c *ConnWriter) Reset(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimer()
+	c.w = w
+	c.err = nil
+}
+
+// SetFlushPolicy replaces the flush policy of c. It takes effect for the
+// next message written.
+func (c *ConnWriter) SetFlushPolicy(policy FlushPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.policy = policy
+}
+
+// WriteMessageLine writes a single message line. The line must not contain
+// the concluding end-of-line character, it is appended by WriteMessageLine.
+// If the line (including the end-of-line character) is longer than
+// MaxMessageLength, ErrMessageTooLong is returned and nothing is written.
+//
+// The line is copied into the buffer, it may be modified after
+// WriteMessageLine returns.
+func (c *ConnWriter) WriteMessageLine(line []byte) error {
+	if len(line)+1 > MaxMessageLength {
+		return ErrMessageTooLong
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	if _, err := c.w.Write(line); err != nil {
+		c.err = err
+		return err
+	}
+	if err := c.w.WriteByte(eol); err != nil {
+		c.err = err
+		return err
+	}
+
+	if c.policy.MaxLatency <= 0 ||
+		(c.policy.MaxSize > 0 && c.w.Buffered() >= c.policy.MaxSize) {
+		return c.flush()
+	}
+
+	if c.w.Buffered() == 0 {
+		// bufio.Writer has flushed on its own, nothing is pending.
+		return nil
+	}
+
+	if !c.pending {
+		c.pending = true
+
+		if c.timer == nil {
+			c.timer = time.AfterFunc(c.policy.MaxLatency, c.timerFlush)
+		} else {
+			c.timer.Reset(c.policy.MaxLatency)
+		}
+	}
+
+	return nil
+}
+
+// Buffered returns the number of bytes which have been written but not yet
+// flushed.
+func (c *ConnWriter) Buffered() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.w.Buffered()
+}
+
+// Flush writes all buffered messages to the underlying writer.
+func (c *ConnWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	return c.flush()
+}
+
+// Close flushes all buffered messages and stops the flush timer. The
+// underlying writer is not closed.
+func (c *ConnWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		c.stopTimer()
+		return c.err
+	}
+
+	return c.flush()
+}
+
+// flush flushes the buffer and stops the timer. c.mu must be held.
+func (c *ConnWriter) flush() error {
+	c.stopTimer()
+
+	if err := c.w.Flush(); err != nil {
+		c.err = err
+		return err
+	}
+
+	return nil
+}
+
+// stopTimer stops the flush timer if it is armed. c.mu must be held.
+func (c *ConnWriter) stopTimer() {
+	if c.pending {
+		c.timer.Stop()
+		c.pending = false
+	}
+}
+
+// timerFlush is run by the flush timer.
+func (c *ConnWriter) timerFlush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pending || c.err != nil {
+		// The buffer has been flushed in the meantime.
+		return
+	}
+
+	c.pending = false
+
+	if err := c.w.Flush(); err != nil {
+		c.err = err
+	}
+}
@@ -0,0 +1,134 @@
+package writer_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/protocol/writer"
+)
+
+// recordingWriter records the number of Write calls, it is safe for
+// concurrent use.
+type recordingWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+}
+
+func (r *recordingWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writes++
+	return r.buf.Write(p)
+}
+
+func (r *recordingWriter) Writes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.writes
+}
+
+func (r *recordingWriter) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.buf.String()
+}
+
+var _ = Describe("ConnWriter", func() {
+	var rec *recordingWriter
+
+	BeforeEach(func() {
+		rec = &recordingWriter{}
+	})
+
+	It("should flush every message with the zero policy", func() {
+		c := NewConnWriter(bufio.NewWriter(rec), FlushPolicy{})
+
+		Ω(c.WriteMessageLine([]byte("HSUP ADBASE"))).ShouldNot(HaveOccurred())
+		Ω(rec.Writes()).Should(Equal(1))
+		Ω(c.WriteMessageLine([]byte("HSUP ADTIGR"))).ShouldNot(HaveOccurred())
+		Ω(rec.Writes()).Should(Equal(2))
+
+		Ω(rec.String()).Should(Equal("HSUP ADBASE\nHSUP ADTIGR\n"))
+	})
+
+	It("should batch messages until the latency has passed", func() {
+		c := NewConnWriter(bufio.NewWriter(rec), FlushPolicy{
+			MaxLatency: 20 * time.Millisecond,
+		})
+
+		for i := 0; i < 10; i++ {
+			Ω(c.WriteMessageLine([]byte("BMSG AAAB hi"))).ShouldNot(HaveOccurred())
+		}
+		Ω(rec.Writes()).Should(Equal(0))
+		Ω(c.Buffered()).Should(Equal(10 * len("BMSG AAAB hi\n")))
+
+		Eventually(rec.Writes).Should(Equal(1))
+		Ω(rec.String()).Should(Equal(strings.Repeat("BMSG AAAB hi\n", 10)))
+		Ω(c.Close()).ShouldNot(HaveOccurred())
+	})
+
+	It("should flush as soon as the size threshold is reached", func() {
+		c := NewConnWriter(bufio.NewWriter(rec), FlushPolicy{
+			MaxLatency: time.Hour,
+			MaxSize:    20,
+		})
+
+		Ω(c.WriteMessageLine([]byte("BMSG AAAB hi"))).ShouldNot(HaveOccurred())
+		Ω(rec.Writes()).Should(Equal(0))
+		Ω(c.WriteMessageLine([]byte("BMSG AAAB hi"))).ShouldNot(HaveOccurred())
+		Ω(rec.Writes()).Should(Equal(1))
+
+		Ω(c.Close()).ShouldNot(HaveOccurred())
+		Ω(rec.Writes()).Should(Equal(1))
+	})
+
+	It("should flush buffered messages on Close()", func() {
+		c := NewConnWriter(bufio.NewWriter(rec), FlushPolicy{
+			MaxLatency: time.Hour,
+		})
+
+		Ω(c.WriteMessageLine([]byte("BMSG AAAB hi"))).ShouldNot(HaveOccurred())
+		Ω(c.Close()).ShouldNot(HaveOccurred())
+		Ω(rec.String()).Should(Equal("BMSG AAAB hi\n"))
+	})
+
+	It("should reject messages longer than MaxMessageLength", func() {
+		c := NewConnWriter(bufio.NewWriter(rec), FlushPolicy{})
+
+		line := bytes.Repeat([]byte("A"), MaxMessageLength)
+		Ω(c.WriteMessageLine(line)).Should(Equal(ErrMessageTooLong))
+		Ω(c.WriteMessageLine(line[1:])).ShouldNot(HaveOccurred())
+		Ω(rec.buf.Len()).Should(Equal(MaxMessageLength))
+	})
+})
+
+var _ = Describe("Writer", func() {
+	It("should serialise and write messages", func() {
+		var buf bytes.Buffer
+		w := New(bufio.NewWriter(&buf), FlushPolicy{MaxLatency: time.Hour})
+
+		mes := message.Message{
+			Type:    message.TypeHubmessage,
+			Command: message.CommandSTA,
+			Content: &message.STAContent{Description: "OK"},
+		}
+
+		Ω(w.WriteMessage(&mes)).ShouldNot(HaveOccurred())
+		Ω(w.WriteMessage(&mes)).ShouldNot(HaveOccurred())
+		Ω(buf.Len()).Should(Equal(0))
+
+		Ω(w.Flush()).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal("HSTA 000 OK\nHSTA 000 OK\n"))
+	})
+})
@@ -0,0 +1,170 @@
+package writer
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/encoding"
+)
+
+// Error variables related to MessageWriter.
+var (
+	ErrInvalidParameter     = errors.New("invalid parameter, the parameter is empty or contains separator or end-of-line characters")
+	ErrInvalidParameterName = errors.New("invalid parameter name, the name cannot be used for a named parameter")
+	ErrMissingValue         = errors.New("missing value, a required parameter value is nil")
+)
+
+// MessageWriter is a helper to compose the line of an ADC message token by
+// token. It is the counterpart of parser.MessageReader.
+//
+// All tokens are separated by a single space (0x20). The concluding
+// end-of-line character is not written by MessageWriter.
+type MessageWriter struct {
+	buf []byte
+}
+
+// NewMessageWriter creates a new, empty MessageWriter.
+//
+// Equivalent to:
+//     var messageWriter MessageWriter
+//     messageWriter.Reset()
+func NewMessageWriter() *MessageWriter {
+	return &MessageWriter{}
+}
+
+// Reset discards all tokens written so far. The internal buffer is retained
+// and reused by subsequent writes.
+func (m *MessageWriter) Reset() {
+	m.buf = m.buf[:0]
+}
+
+// Len returns the number of bytes written so far.
+func (m *MessageWriter) Len() int {
+	return len(m.buf)
+}
+
+// Bytes returns the line written so far. The returned slice is only valid
+// until the next call to a write method or Reset.
+func (m *MessageWriter) Bytes() []byte {
+	return m.buf
+}
+
+// String returns a copy of the line written so far.
+func (m *MessageWriter) String() string {
+	return string(m.buf)
+}
+
+// WritePositional writes a raw positional parameter. raw must already be
+// encoded, i.e. must not contain any spaces or end-of-line characters.
+func (m *MessageWriter) WritePositional(raw string) error {
+	if len(raw) == 0 || !isValidRaw(raw) {
+		return ErrInvalidParameter
+	}
+
+	m.writeToken(raw)
+	return nil
+}
+
+func (m *MessageWriter) WritePositionalString(s string) error {
+	raw, err := encoding.EncodeToADCString(s)
+	if err != nil {
+		return err
+	}
+
+	return m.WritePositional(raw)
+}
+
+func (m *MessageWriter) WritePositionalBase32Value(v *encoding.Base32Value) error {
+	if v == nil {
+		return ErrMissingValue
+	}
+
+	return m.WritePositional(v.String())
+}
+
+func (m *MessageWriter) WritePositionalInt(i int) error {
+	return m.WritePositional(strconv.Itoa(i))
+}
+
+func (m *MessageWriter) WritePositionalFloat64(f float64) error {
+	return m.WritePositional(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func (m *MessageWriter) WritePositionalIP(ip net.IP) error {
+	if ip == nil {
+		return ErrMissingValue
+	}
+
+	return m.WritePositional(ip.String())
+}
+
+// WriteNamed writes a named parameter consisting of the two-character name
+// and the raw value. raw must already be encoded, i.e. must not contain any
+// spaces or end-of-line characters. In contrast to positional parameters, raw
+// may be empty.
+func (m *MessageWriter) WriteNamed(name, raw string) error {
+	if len(name) != 2 || !(encoding.IsUpperAlpha(name[0]) &&
+		encoding.IsUpperAlphaNum(name[1])) {
+		return ErrInvalidParameterName
+	}
+	if !isValidRaw(raw) {
+		return ErrInvalidParameter
+	}
+
+	m.writeSeparator()
+	m.buf = append(m.buf, name...)
+	m.buf = append(m.buf, raw...)
+	return nil
+}
+
+func (m *MessageWriter) WriteNamedString(name, s string) error {
+	raw, err := encoding.EncodeToADCString(s)
+	if err != nil {
+		return err
+	}
+
+	return m.WriteNamed(name, raw)
+}
+
+func (m *MessageWriter) WriteNamedBase32Value(name string, v *encoding.Base32Value) error {
+	if v == nil {
+		return ErrMissingValue
+	}
+
+	return m.WriteNamed(name, v.String())
+}
+
+func (m *MessageWriter) WriteNamedInt(name string, i int) error {
+	return m.WriteNamed(name, strconv.Itoa(i))
+}
+
+func (m *MessageWriter) WriteNamedFloat64(name string, f float64) error {
+	return m.WriteNamed(name, strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func (m *MessageWriter) WriteNamedIP(name string, ip net.IP) error {
+	if ip == nil {
+		return ErrMissingValue
+	}
+
+	return m.WriteNamed(name, ip.String())
+}
+
+func (m *MessageWriter) writeToken(tok string) {
+	m.writeSeparator()
+	m.buf = append(m.buf, tok...)
+}
+
+func (m *MessageWriter) writeSeparator() {
+	if len(m.buf) > 0 {
+		m.buf = append(m.buf, space)
+	}
+}
+
+// isValidRaw returns true if raw may be used as (part of) a token, i.e. it
+// contains neither separator nor end-of-line characters.
+func isValidRaw(raw string) bool {
+	return strings.IndexByte(raw, space) == -1 && strings.IndexByte(raw, eol) == -1
+}
@@ -0,0 +1,54 @@
+package writer
+
+import (
+	"sync"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// ContentWriterFunc writes the parameters of cnt.
+type ContentWriterFunc func(m *MessageWriter, cnt message.ParamAccessor) error
+
+var (
+	contentWritersMu sync.RWMutex
+	contentWriters   = make(map[message.Command]ContentWriterFunc)
+)
+
+// RegisterContentWriter makes write available as the writer for the contents
+// of messages with command cmd. It is the counterpart of
+// parser.RegisterContentParser. WriteMessage calls write for all messages with
+// the command cmd instead of WriteContent.
+//
+// RegisterContentWriter is meant to be called from init functions. It panics
+// if cmd is not a valid command name, is a command known to this package, if
+// a writer for cmd has already been registered or if write is nil.
+func RegisterContentWriter(cmd message.Command, write ContentWriterFunc) {
+	_, known, err := message.ParseCommand(string(cmd))
+	if err != nil {
+		panic("writer: RegisterContentWriter with invalid command " + string(cmd))
+	}
+	if known {
+		panic("writer: RegisterContentWriter with known command " + string(cmd))
+	}
+	if write == nil {
+		panic("writer: RegisterContentWriter with nil write function")
+	}
+
+	contentWritersMu.Lock()
+	defer contentWritersMu.Unlock()
+
+	if _, dup := contentWriters[cmd]; dup {
+		panic("writer: RegisterContentWriter called twice for command " + string(cmd))
+	}
+	contentWriters[cmd] = write
+}
+
+// registeredContentWriter returns the writer registered for cmd by
+// RegisterContentWriter.
+func registeredContentWriter(cmd message.Command) (write ContentWriterFunc, ok bool) {
+	contentWritersMu.RLock()
+	write, ok = contentWriters[cmd]
+	contentWritersMu.RUnlock()
+
+	return
+}
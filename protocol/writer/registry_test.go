@@ -0,0 +1,79 @@
+package writer_test
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	. "github.com/seoester/adcl/protocol/writer"
+)
+
+// natContent is a simplified content of NAT (NATT extension) messages used
+// for testing RegisterContentWriter.
+type natContent struct {
+	message.GenericContent
+
+	Protocol string
+	Port     string
+	Token    string
+}
+
+func init() {
+	parser.RegisterContentParser("NAT", func(m *parser.MessageReader) (message.ParamAccessor, error) {
+		cnt := &natContent{}
+
+		for _, field := range []*string{&cnt.Protocol, &cnt.Port, &cnt.Token} {
+			param, err := m.ReadPositional()
+			if err == io.EOF {
+				return nil, parser.ErrIncompleteMessage
+			} else if err != nil {
+				return nil, err
+			}
+
+			*field, err = param.ValueString()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return cnt, nil
+	})
+
+	RegisterContentWriter("NAT", func(m *MessageWriter, cnt message.ParamAccessor) (err error) {
+		nat := cnt.(*natContent)
+
+		for _, field := range []string{nat.Protocol, nat.Port, nat.Token} {
+			err = m.WritePositionalString(field)
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+}
+
+var _ = Describe("RegisterContentWriter() - Registering writers for extension commands", func() {
+	It("should be used by WriteMessage", func() {
+		line := "DNAT AAAB AAAC ADC/1.0 4567 token"
+
+		mes, err := parser.ParseMessage(parser.NewMessageReader(line))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mes.Content).Should(BeAssignableToTypeOf(&natContent{}))
+
+		mes.Content.(*natContent).Port = "4568"
+		Ω(FormatMessage(&mes)).Should(Equal("DNAT AAAB AAAC ADC/1.0 4568 token\n"))
+	})
+
+	It("should panic on invalid registrations", func() {
+		write := func(m *MessageWriter, cnt message.ParamAccessor) error { return nil }
+
+		Ω(func() { RegisterContentWriter("NAT", write) }).Should(Panic())
+		Ω(func() { RegisterContentWriter("CTM", write) }).Should(Panic())
+		Ω(func() { RegisterContentWriter("NaT", write) }).Should(Panic())
+		Ω(func() { RegisterContentWriter("RNT", nil) }).Should(Panic())
+	})
+})
@@ -0,0 +1,305 @@
+package writer
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+var (
+	ErrInvalidHeaderFields = errors.New("header fields invalid, they do not match the message type")
+	ErrInvalidFeature      = errors.New("feature invalid, it is not a four character feature name")
+)
+
+// Constants which are used throughout the writer package.
+const (
+	space byte = ' '
+	eol        = '\n'
+)
+
+func WriteMessage(m *MessageWriter, mes *message.Message) (err error) {
+	err = WriteHeader(m, mes)
+	if err != nil {
+		return
+	}
+
+	if write, ok := registeredContentWriter(mes.Command); ok {
+		err = write(m, mes.Content)
+	} else {
+		err = WriteContent(m, mes.Content)
+	}
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func WriteHeader(m *MessageWriter, mes *message.Message) (err error) {
+	if _, err = message.ParseType(byte(mes.Type)); err != nil {
+		return
+	}
+
+	if _, _, err = message.ParseCommand(string(mes.Command)); err != nil {
+		return
+	}
+
+	fourcc := make([]byte, 0, 4)
+	fourcc = append(fourcc, byte(mes.Type))
+	fourcc = append(fourcc, mes.Command...)
+
+	err = m.WritePositional(string(fourcc))
+	if err != nil {
+		return
+	}
+
+	err = WriteHeaderFields(m, mes.Type, mes.HeaderFields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// WriteHeaderFields writes the additional header fields of a message of type
+// typ. fields must either have the header fields type declared for typ (e.g.
+// DirectHeaderFields for Directmessage) or the shared type (DEHeaderFields or
+// CIHHeaderFields), otherwise ErrInvalidHeaderFields is returned.
+func WriteHeaderFields(m *MessageWriter, typ message.Type, fields message.HeaderFields) error {
+	switch typ {
+	case message.TypeBroadcast:
+		f, ok := fields.(message.BroadcastHeaderFields)
+		if !ok {
+			return ErrInvalidHeaderFields
+		}
+		return WriteBroadcastHeaderFields(m, f)
+	case message.TypeClientmessage, message.TypeHubmessage, message.TypeInfomessage:
+		switch f := fields.(type) {
+		case nil:
+			return nil
+		case message.CIHHeaderFields:
+			return WriteCIHHeaderFields(m, f)
+		case message.ClientHeaderFields:
+			if typ != message.TypeClientmessage {
+				return ErrInvalidHeaderFields
+			}
+			return WriteCIHHeaderFields(m, message.CIHHeaderFields(f))
+		case message.HubHeaderFields:
+			if typ != message.TypeHubmessage {
+				return ErrInvalidHeaderFields
+			}
+			return WriteCIHHeaderFields(m, message.CIHHeaderFields(f))
+		case message.InfoHeaderFields:
+			if typ != message.TypeInfomessage {
+				return ErrInvalidHeaderFields
+			}
+			return WriteCIHHeaderFields(m, message.CIHHeaderFields(f))
+		default:
+			return ErrInvalidHeaderFields
+		}
+	case message.TypeDirectmessage, message.TypeEchomessage:
+		switch f := fields.(type) {
+		case message.DEHeaderFields:
+			return WriteDEHeaderFields(m, f)
+		case message.DirectHeaderFields:
+			if typ != message.TypeDirectmessage {
+				return ErrInvalidHeaderFields
+			}
+			return WriteDEHeaderFields(m, message.DEHeaderFields(f))
+		case message.EchoHeaderFields:
+			if typ != message.TypeEchomessage {
+				return ErrInvalidHeaderFields
+			}
+			return WriteDEHeaderFields(m, message.DEHeaderFields(f))
+		default:
+			return ErrInvalidHeaderFields
+		}
+	case message.TypeFeaturebroadcast:
+		f, ok := fields.(message.FeatureHeaderFields)
+		if !ok {
+			return ErrInvalidHeaderFields
+		}
+		return WriteFeatureHeaderFields(m, f)
+	case message.TypeUDPmessage:
+		f, ok := fields.(message.UDPHeaderFields)
+		if !ok {
+			return ErrInvalidHeaderFields
+		}
+		return WriteUDPHeaderFields(m, f)
+	default:
+		return message.ErrInvalidType
+	}
+}
+
+func WriteBroadcastHeaderFields(m *MessageWriter, fields message.BroadcastHeaderFields) error {
+	return m.WritePositionalBase32Value(fields.MySID)
+}
+
+func WriteCIHHeaderFields(m *MessageWriter, fields message.CIHHeaderFields) error {
+	return nil
+}
+
+func WriteDEHeaderFields(m *MessageWriter, fields message.DEHeaderFields) (err error) {
+	err = m.WritePositionalBase32Value(fields.MySID)
+	if err != nil {
+		return
+	}
+
+	err = m.WritePositionalBase32Value(fields.TargetSID)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func WriteFeatureHeaderFields(m *MessageWriter, fields message.FeatureHeaderFields) (err error) {
+	err = m.WritePositionalBase32Value(fields.MySID)
+	if err != nil {
+		return
+	}
+
+	features := make([]byte, 0, 5*len(fields.Features))
+
+	for _, op := range fields.Features {
+		switch op.OpAction {
+		case message.FeatureOpAdd:
+			features = append(features, '+')
+		case message.FeatureOpRemove:
+			features = append(features, '-')
+		default:
+			return ErrInvalidFeature
+		}
+
+		if !isValidFeature(op.Feature) {
+			return ErrInvalidFeature
+		}
+
+		features = append(features, op.Feature...)
+	}
+
+	err = m.WritePositional(string(features))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func WriteUDPHeaderFields(m *MessageWriter, fields message.UDPHeaderFields) error {
+	return m.WritePositionalBase32Value(fields.MyCID)
+}
+
+// WriteGenericContent writes the raw parameters as returned by the
+// Positional() and Named() methods of cnt. Named parameters are written in
+// lexical order of their names.
+func WriteGenericContent(m *MessageWriter, cnt message.ParamAccessor) (err error) {
+	for _, raw := range cnt.Positional() {
+		err = m.WritePositional(raw)
+		if err != nil {
+			return
+		}
+	}
+
+	err = writeFlags(m, cnt.Named())
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// writeFlags writes all entries of flags as named parameters in lexical order
+// of their names. The values are expected to be raw (encoded) values.
+func writeFlags(m *MessageWriter, flags map[string]string) error {
+	if len(flags) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := m.WriteNamed(name, flags[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeStatusCode writes code as three-digit positional parameter.
+func writeStatusCode(m *MessageWriter, code message.StatusCode) error {
+	if code.Severity < message.SeveritySuccess || code.Severity > message.SeverityFatal ||
+		code.Error < 0 || code.Error > 99 {
+		return message.ErrInvalidStatusCode
+	}
+
+	return m.WritePositional(code.String())
+}
+
+// writeSUPFeatureOps writes each feature operation as positional parameter,
+// i.e. the feature name prefixed by AD (add) or RM (remove).
+func writeSUPFeatureOps(m *MessageWriter, ops []message.FeatureOp) error {
+	for _, op := range ops {
+		var prefix string
+
+		switch op.OpAction {
+		case message.FeatureOpAdd:
+			prefix = "AD"
+		case message.FeatureOpRemove:
+			prefix = "RM"
+		default:
+			return ErrInvalidFeature
+		}
+
+		if !isValidFeature(op.Feature) {
+			return ErrInvalidFeature
+		}
+
+		if err := m.WritePositional(prefix + op.Feature); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSearchTerms writes each search term as AN (include), NO (exclude) or
+// EX (extension) named parameter.
+func writeSearchTerms(m *MessageWriter, terms []message.SearchTerm) error {
+	for _, term := range terms {
+		var name message.SCHFlag
+
+		switch term.TermAction {
+		case message.SearchTermInclude:
+			name = message.SCHFlagAN
+		case message.SearchTermExclude:
+			name = message.SCHFlagNO
+		case message.SearchTermExtension:
+			name = message.SCHFlagEX
+		default:
+			return ErrInvalidParameter
+		}
+
+		if err := m.WriteNamedString(string(name), term.Term); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isValidFeature returns true if s is a valid feature name (FOURCC).
+func isValidFeature(s string) bool {
+	return len(s) == 4 &&
+		encoding.IsUpperAlpha(s[0]) &&
+		encoding.IsUpperAlphaNum(s[1]) &&
+		encoding.IsUpperAlphaNum(s[2]) &&
+		encoding.IsUpperAlphaNum(s[3])
+}
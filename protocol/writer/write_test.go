@@ -0,0 +1,221 @@
+package writer_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	. "github.com/seoester/adcl/protocol/writer"
+)
+
+func mustBase32(s string) *encoding.Base32Value {
+	v, err := encoding.ParseBase32Value(s)
+	Ω(err).ShouldNot(HaveOccurred())
+	return v
+}
+
+var _ = Describe("FormatMessage()", func() {
+	It("should write an STA message with escaped description", func() {
+		mes := message.Message{
+			Type:         message.TypeInfomessage,
+			Command:      message.CommandSTA,
+			HeaderFields: message.CIHHeaderFields{},
+			Content: &message.STAContent{
+				Code: message.StatusCode{
+					Severity: message.SeverityRecoverable,
+					Error:    44,
+				},
+				Description: "Invalid parameter value!\n\\",
+				Flags:       map[string]string{"TO": "qwertzu", "FC": "SCH"},
+			},
+		}
+
+		Ω(FormatMessage(&mes)).Should(Equal(
+			"ISTA 144 Invalid\\sparameter\\svalue!\\n\\\\ FCSCH TOqwertzu\n",
+		))
+	})
+
+	It("should be parsable by the parser", func() {
+		mes := message.Message{
+			Type:         message.TypeBroadcast,
+			Command:      message.CommandSTA,
+			HeaderFields: message.BroadcastHeaderFields{MySID: mustBase32("AAAB")},
+			Content: &message.STAContent{
+				Description: "all good",
+			},
+		}
+
+		line, err := FormatMessage(&mes)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		p := parser.New(bufio.NewReader(strings.NewReader(line)))
+		parsed, err := p.ReadMessage()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(parsed.Type).Should(Equal(message.TypeBroadcast))
+		Ω(parsed.Command).Should(Equal(message.CommandSTA))
+		fields := parsed.HeaderFields.(message.BroadcastHeaderFields)
+		Ω(fields.MySID.String()).Should(Equal("AAAB"))
+		cnt := parsed.Content.(*message.STAContent)
+		Ω(cnt.Code.Code()).Should(Equal(0))
+		Ω(cnt.Description).Should(Equal("all good"))
+	})
+
+	It("should write feature broadcast header fields", func() {
+		mes := message.Message{
+			Type:    message.TypeFeaturebroadcast,
+			Command: message.CommandSCH,
+			HeaderFields: message.FeatureHeaderFields{
+				MySID: mustBase32("AAAB"),
+				Features: []message.FeatureOp{
+					{OpAction: message.FeatureOpAdd, Feature: "TCP4"},
+					{OpAction: message.FeatureOpRemove, Feature: "NAT0"},
+				},
+			},
+			Content: &message.SCHContent{
+				SearchTerms: []message.SearchTerm{
+					{TermAction: message.SearchTermInclude, Term: "some file"},
+					{TermAction: message.SearchTermExtension, Term: "mp3"},
+				},
+			},
+		}
+
+		Ω(FormatMessage(&mes)).Should(Equal(
+			"FSCH AAAB +TCP4-NAT0 ANsome\\sfile EXmp3\n",
+		))
+	})
+
+	It("should write the typed parameters of INF", func() {
+		cnt := &message.INFContent{}
+		cnt.NI.Set("nick name")
+		cnt.I4.Set(net.IPv4zero)
+		cnt.SS.Set(1024)
+		cnt.SU = []string{"TCP4", "UDP4"}
+
+		mes := message.Message{
+			Type:         message.TypeBroadcast,
+			Command:      message.CommandINF,
+			HeaderFields: message.BroadcastHeaderFields{MySID: mustBase32("AAAB")},
+			Content:      cnt,
+		}
+
+		Ω(FormatMessage(&mes)).Should(Equal(
+			"BINF AAAB I40.0.0.0 SS1024 NInick\\sname SUTCP4,UDP4\n",
+		))
+	})
+
+	It("should write generic content as is", func() {
+		mes := message.Message{
+			Type:         message.TypeHubmessage,
+			Command:      message.CommandSUP,
+			HeaderFields: message.CIHHeaderFields{},
+			Content: &message.GenericContent{
+				PositionalParams: []string{"ADBASE", "ADTIGR"},
+			},
+		}
+
+		Ω(FormatMessage(&mes)).Should(Equal("HSUP ADBASE ADTIGR\n"))
+	})
+
+	It("should reject header fields not matching the type", func() {
+		mes := message.Message{
+			Type:         message.TypeDirectmessage,
+			Command:      message.CommandMSG,
+			HeaderFields: message.BroadcastHeaderFields{MySID: mustBase32("AAAB")},
+			Content:      &message.MSGContent{Text: "hi"},
+		}
+
+		_, err := FormatMessage(&mes)
+		Ω(err).Should(Equal(ErrInvalidHeaderFields))
+	})
+
+	It("should accept the per-type header fields only for their type", func() {
+		fields := message.DEHeaderFields{MySID: mustBase32("AAAB"), TargetSID: mustBase32("AAAC")}
+		mes := message.Message{
+			Type:         message.TypeEchomessage,
+			Command:      message.CommandMSG,
+			HeaderFields: message.EchoHeaderFields(fields),
+			Content:      &message.MSGContent{Text: "hi"},
+		}
+
+		Ω(FormatMessage(&mes)).Should(Equal("EMSG AAAB AAAC hi\n"))
+
+		mes.HeaderFields = fields
+		Ω(FormatMessage(&mes)).Should(Equal("EMSG AAAB AAAC hi\n"))
+
+		mes.HeaderFields = message.DirectHeaderFields(fields)
+		_, err := FormatMessage(&mes)
+		Ω(err).Should(Equal(ErrInvalidHeaderFields))
+	})
+
+	It("should reject empty positional parameters", func() {
+		mes := message.Message{
+			Type:    message.TypeInfomessage,
+			Command: message.CommandSTA,
+			Content: &message.STAContent{},
+		}
+
+		_, err := FormatMessage(&mes)
+		Ω(err).Should(Equal(ErrInvalidParameter))
+	})
+})
+
+var _ = Describe("MessageWriter", func() {
+	It("should reject raw values containing separators", func() {
+		m := NewMessageWriter()
+		Ω(m.WritePositional("a b")).Should(Equal(ErrInvalidParameter))
+		Ω(m.WriteNamed("NI", "a\nb")).Should(Equal(ErrInvalidParameter))
+		Ω(m.Len()).Should(Equal(0))
+	})
+
+	It("should reject invalid parameter names", func() {
+		m := NewMessageWriter()
+		Ω(m.WriteNamed("ni", "a")).Should(Equal(ErrInvalidParameterName))
+		Ω(m.WriteNamed("1I", "a")).Should(Equal(ErrInvalidParameterName))
+		Ω(m.WriteNamed("NIX", "a")).Should(Equal(ErrInvalidParameterName))
+	})
+
+	It("should allow empty named values", func() {
+		m := NewMessageWriter()
+		Ω(m.WritePositional("BINF")).ShouldNot(HaveOccurred())
+		Ω(m.WriteNamed("DE", "")).ShouldNot(HaveOccurred())
+		Ω(m.String()).Should(Equal("BINF DE"))
+	})
+})
+
+var _ = Describe("Round trip", func() {
+	lines := []string{
+		"ISTA 144 Invalid\\sparameter TOqwertzu",
+		"HSUP ADBASE ADTIGR RMZLIF",
+		"ISID AAAB",
+		"BINF AAAB I40.0.0.0 SS1024 NIsome\\snick SUTCP4,UDP4 DE XXfoo",
+		"EMSG AAAB AAAC hello\\sworld PMAAAB ME1",
+		"FSCH AAAB +TCP4 ANfoo NObar EXmp3 TD3 TOtoken",
+		"DRES AAAB AAAC FN/dir/file SI1234 SL3 TOtoken",
+		"DCTM AAAB AAAC ADC/1.0 4567 token",
+		"DRCM AAAB AAAC ADC/1.0 token",
+		"IGPA ABCDEFGH",
+		"HPAS ABCDEFGH",
+		"IQUI AAAB TL-1 MSkicked",
+		"CGET file TTH/LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ 0 -1 RE1 ZL1",
+		"CGFI file files.xml.bz2",
+		"CSND list / 0 1234",
+	}
+
+	for _, line := range lines {
+		line := line
+
+		It("should write the parsed message "+line+" unchanged", func() {
+			mes, err := parser.ParseMessage(parser.NewMessageReader(line))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(FormatMessage(&mes)).Should(Equal(line + "\n"))
+		})
+	}
+})
@@ -0,0 +1,111 @@
+// Package writer provides serialisation functionality for ADC protocol
+// messages as well as for writing messages to connections. It is the
+// counterpart of the parser package.
+//
+// Users will likely only interact with the Writer type.
+//
+// Overview
+//
+//     Writer (bufio.Writer)
+//         |  \
+//         |   +---------------+
+//     ConnWriter         WriteMessage() - - - - - -
+//         ^                   |                   ¦
+//         ¦                   |                   v
+//         +- - - - - - -  MessageWriter <- - - WriteMessage()
+//                                                | | \
+//                                                | |  \
+//                                                .......
+//
+// The above diagram outlines the structure of this package in terms of
+// encapsulation (solid lines) and data flow (dashed lines).
+//
+// The Writer type is initialised with a bufio.Writer. Its WriteMessage()
+// method passes a MessageWriter to the WriteMessage() function and hands the
+// composed line to a ConnWriter, which batches lines and flushes them
+// according to a FlushPolicy.
+//
+// The WriteMessage() function composes the line of a message.Message in a MessageWriter by
+// calling WriteHeader() and WriteContent(). WriteContent() chooses the
+// Write...Content function matching the type of the message's content. All
+// string parameters are escaped using encoding.EncodeToADCString, all base32
+// parameters are encoded using encoding.Base32Value.String.
+package writer
+
+import (
+	"bufio"
+	"sync"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Writer writes messages to a bufio.Writer. Writer is safe for concurrent
+// use.
+type Writer struct {
+	mu         sync.Mutex
+	m          MessageWriter
+	connWriter ConnWriter
+}
+
+// New creates a new Writer writing to the passed in bufio.Writer and
+// flushing according to policy.
+func New(w *bufio.Writer, policy FlushPolicy) *Writer {
+	return &Writer{
+		connWriter: ConnWriter{
+			w:      w,
+			policy: policy,
+		},
+	}
+}
+
+// Reset sets w as the writer and resets the internal state.
+func (w *Writer) Reset(wr *bufio.Writer) {
+	w.connWriter.Reset(wr)
+}
+
+// ConnWriter returns the ConnWriter used by w. It may be used to write
+// pre-formatted lines, e.g. when broadcasting the same message to many
+// connections.
+func (w *Writer) ConnWriter() *ConnWriter {
+	return &w.connWriter
+}
+
+// WriteMessage serialises mes and writes it to the underlying writer.
+// Depending on the FlushPolicy, the message is buffered.
+func (w *Writer) WriteMessage(mes *message.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.m.Reset()
+
+	if err := WriteMessage(&w.m, mes); err != nil {
+		return err
+	}
+
+	return w.connWriter.WriteMessageLine(w.m.Bytes())
+}
+
+// Flush writes all buffered messages to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.connWriter.Flush()
+}
+
+// Close flushes all buffered messages and stops the flush timer. The
+// underlying writer is not closed.
+func (w *Writer) Close() error {
+	return w.connWriter.Close()
+}
+
+// FormatMessage returns the line of mes, including the concluding
+// end-of-line character.
+func FormatMessage(mes *message.Message) (string, error) {
+	var m MessageWriter
+
+	if err := WriteMessage(&m, mes); err != nil {
+		return "", err
+	}
+
+	m.buf = append(m.buf, eol)
+
+	return m.String(), nil
+}
@@ -0,0 +1,235 @@
+// Package search matches files and directories of a share against searches
+// (SCH) and creates the search results (RES) sent in response.
+//
+// Usage:
+//
+//	q, err := search.Compile(sch)
+//	if err != nil {
+//	    ...
+//	}
+//
+//	for _, entry := range entries {
+//	    if q.Match(&entry) {
+//	        res, err := q.Result(&entry, slots)
+//	        ...
+//	    }
+//	}
+package search
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/maybe"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Error variables related to Compile.
+var (
+	ErrEmptyQuery  = errors.New("search contains neither AN, EX nor TR")
+	ErrInvalidSize = errors.New("invalid size constraint, LE, GE and EQ must be non-negative integers")
+	ErrInvalidType = errors.New("invalid type, TY must be 1 (file) or 2 (directory)")
+)
+
+// Type restricts the type of entries matched by a Query, it is the TY field
+// of SCH messages.
+type Type int
+
+// Constants related to Type.
+const (
+	TypeAny       Type = 0
+	TypeFile      Type = 1
+	TypeDirectory Type = 2
+)
+
+// Entry is a file or directory of a share.
+type Entry struct {
+	// Path is the virtual path of the entry, i.e. its path in the file
+	// list, separated by "/". The paths of directories end with "/".
+	Path string
+	// Size is the size of a file in bytes.
+	Size int64
+	// TTH is the Tiger tree hash root of a file, nil for directories.
+	TTH   *encoding.Base32Value
+	IsDir bool
+}
+
+// Query is a compiled search, it matches entries against the criteria of a
+// SCH message.
+type Query struct {
+	// Include contains the AN terms in lower case, all of them must be
+	// contained in the path of an entry.
+	Include []string
+	// Exclude contains the NO terms in lower case, none of them may be
+	// contained in the path of an entry.
+	Exclude []string
+	// Extensions contains the EX terms in lower case, files must have one
+	// of them as extension.
+	Extensions []string
+
+	// LE, GE and EQ are the size constraints (less or equal, greater or
+	// equal and equal). Only files satisfy size constraints.
+	LE maybe.Int
+	GE maybe.Int
+	EQ maybe.Int
+
+	Type Type
+
+	// TTH is the Tiger tree hash root searched for (TR). If it is set,
+	// only files with this TTH are matched and all other criteria are
+	// ignored.
+	TTH *encoding.Base32Value
+
+	// Token is the token of the search, it is sent back in results (TO).
+	Token string
+}
+
+// Compile compiles the search sch into a Query. ErrEmptyQuery is returned if
+// sch does not contain any criterion selecting entries, i.e. neither AN, EX
+// nor TR.
+func Compile(sch *message.SCHContent) (*Query, error) {
+	q := &Query{
+		LE:    sch.LE,
+		GE:    sch.GE,
+		EQ:    sch.EQ,
+		Token: sch.TO.Value,
+	}
+
+	for _, term := range sch.SearchTerms {
+		lower := strings.ToLower(term.Term)
+
+		switch term.TermAction {
+		case message.SearchTermInclude:
+			q.Include = append(q.Include, lower)
+		case message.SearchTermExclude:
+			q.Exclude = append(q.Exclude, lower)
+		case message.SearchTermExtension:
+			q.Extensions = append(q.Extensions, "."+strings.TrimPrefix(lower, "."))
+		}
+	}
+
+	if sch.TR.IsSet {
+		q.TTH = sch.TR.Value
+	}
+
+	for _, size := range []maybe.Int{q.LE, q.GE, q.EQ} {
+		if size.IsSet && size.Value < 0 {
+			return nil, ErrInvalidSize
+		}
+	}
+
+	if sch.TY.IsSet {
+		q.Type = Type(sch.TY.Value)
+		if q.Type != TypeFile && q.Type != TypeDirectory {
+			return nil, ErrInvalidType
+		}
+	}
+
+	if len(q.Include) == 0 && len(q.Extensions) == 0 && q.TTH == nil {
+		return nil, ErrEmptyQuery
+	}
+
+	return q, nil
+}
+
+// Match returns true if e satisfies all criteria of q. Terms are matched
+// against the whole path of e ignoring case.
+func (q *Query) Match(e *Entry) bool {
+	if q.TTH != nil {
+		return !e.IsDir && e.TTH != nil && e.TTH.String() == q.TTH.String()
+	}
+
+	switch q.Type {
+	case TypeFile:
+		if e.IsDir {
+			return false
+		}
+	case TypeDirectory:
+		if !e.IsDir {
+			return false
+		}
+	}
+
+	if !q.matchSize(e) {
+		return false
+	}
+
+	path := strings.ToLower(e.Path)
+
+	for _, term := range q.Include {
+		if !strings.Contains(path, term) {
+			return false
+		}
+	}
+	for _, term := range q.Exclude {
+		if strings.Contains(path, term) {
+			return false
+		}
+	}
+
+	if len(q.Extensions) > 0 {
+		if e.IsDir {
+			return false
+		}
+
+		matched := false
+		for _, ext := range q.Extensions {
+			if strings.HasSuffix(path, ext) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q *Query) matchSize(e *Entry) bool {
+	if !q.LE.IsSet && !q.GE.IsSet && !q.EQ.IsSet {
+		return true
+	}
+	if e.IsDir {
+		return false
+	}
+
+	return (!q.LE.IsSet || e.Size <= int64(q.LE.Value)) &&
+		(!q.GE.IsSet || e.Size >= int64(q.GE.Value)) &&
+		(!q.EQ.IsSet || e.Size == int64(q.EQ.Value))
+}
+
+// Result returns the search result (RES) for the matching entry e. slots is
+// the number of free upload slots (SL), a negative value omits SL. TO is
+// omitted if the search has no token. An error is returned if the path of e
+// or the token is not a valid UTF-8 string.
+func (q *Query) Result(e *Entry, slots int) (*message.RESContent, error) {
+	fn, err := encoding.EncodeToADCString(e.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &message.RESContent{}
+	cons := message.RESContentConstructor{Content: res}
+	cons.SetFN(e.Path, string(message.RESFlagFN)+fn)
+	cons.SetSI(int(e.Size), string(message.RESFlagSI)+strconv.FormatInt(e.Size, 10))
+
+	if q.Token != "" {
+		to, err := encoding.EncodeToADCString(q.Token)
+		if err != nil {
+			return nil, err
+		}
+		cons.SetTO(q.Token, string(message.RESFlagTO)+to)
+	}
+	if slots >= 0 {
+		cons.SetSL(slots, string(message.RESFlagSL)+strconv.Itoa(slots))
+	}
+	if !e.IsDir && e.TTH != nil {
+		cons.SetTR(e.TTH, string(message.RESFlagTR)+e.TTH.String())
+	}
+
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package search_test
+
+import (
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/parser"
+	. "github.com/seoester/adcl/search"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const testTTH = "LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"
+
+func mustBase32(s string) *encoding.Base32Value {
+	v, err := encoding.ParseBase32Value(s)
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return v
+}
+
+func compile(params string) (*Query, error) {
+	sch, err := parser.ParseSCHContent(parser.NewMessageReader(params))
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return Compile(&sch)
+}
+
+func mustCompile(params string) *Query {
+	q, err := compile(params)
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return q
+}
+
+var _ = Describe("Query", func() {
+	var song, video, album, lyrics Entry
+
+	BeforeEach(func() {
+		song = Entry{Path: "/Music/Some Band/Great Song.mp3", Size: 4000000, TTH: mustBase32(testTTH)}
+		video = Entry{Path: "/Videos/great.MKV", Size: 700000000}
+		album = Entry{Path: "/Music/Some Band/", IsDir: true}
+		lyrics = Entry{Path: "/Music/Some Band/Great Song.txt", Size: 2000}
+	})
+
+	matches := func(q *Query) (paths []string) {
+		for _, e := range []Entry{song, video, album, lyrics} {
+			if q.Match(&e) {
+				paths = append(paths, e.Path)
+			}
+		}
+		return
+	}
+
+	It("should match include and exclude terms ignoring case", func() {
+		Ω(matches(mustCompile("ANgreat"))).Should(Equal([]string{song.Path, video.Path, lyrics.Path}))
+		Ω(matches(mustCompile("ANgreat ANsong NOtxt"))).Should(Equal([]string{song.Path}))
+		Ω(matches(mustCompile("ANsome\\sband"))).Should(Equal([]string{song.Path, album.Path, lyrics.Path}))
+	})
+
+	It("should match extensions", func() {
+		Ω(matches(mustCompile("EXmp3 EXmkv"))).Should(Equal([]string{song.Path, video.Path}))
+		Ω(matches(mustCompile("ANband EXtxt"))).Should(Equal([]string{lyrics.Path}))
+	})
+
+	It("should match size constraints and types", func() {
+		Ω(matches(mustCompile("ANgreat GE3000 LE5000000"))).Should(Equal([]string{song.Path}))
+		Ω(matches(mustCompile("ANgreat EQ2000"))).Should(Equal([]string{lyrics.Path}))
+		Ω(matches(mustCompile("ANsome TY2"))).Should(Equal([]string{album.Path}))
+		Ω(matches(mustCompile("ANsome TY1"))).Should(Equal([]string{song.Path, lyrics.Path}))
+	})
+
+	It("should match TTHs only", func() {
+		Ω(matches(mustCompile("TR" + testTTH + " ANvideos"))).Should(Equal([]string{song.Path}))
+	})
+
+	It("should reject invalid searches", func() {
+		_, err := compile("NOfoo TY1")
+		Ω(err).Should(Equal(ErrEmptyQuery))
+
+		_, err = compile("ANfoo LE-1")
+		Ω(err).Should(Equal(ErrInvalidSize))
+
+		_, err = compile("ANfoo TY3")
+		Ω(err).Should(Equal(ErrInvalidType))
+	})
+
+	It("should create results", func() {
+		q := mustCompile("ANgreat TOsome\\stoken")
+
+		res, err := q.Result(&song, 3)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.FN).Should(Equal(song.Path))
+		Ω(res.SI).Should(Equal(4000000))
+		Ω(res.TO.Value).Should(Equal("some token"))
+		Ω(res.SL.Value).Should(Equal(3))
+		Ω(res.TR.Value.String()).Should(Equal(testTTH))
+		Ω(res.Named()).Should(Equal(map[string]string{
+			"FN": "/Music/Some\\sBand/Great\\sSong.mp3",
+			"SI": "4000000",
+			"TO": "some\\stoken",
+			"SL": "3",
+			"TR": testTTH,
+		}))
+		fn, ok := res.NamedGet("FN")
+		Ω(ok).Should(BeTrue())
+		Ω(fn).Should(Equal("/Music/Some\\sBand/Great\\sSong.mp3"))
+
+		res, err = q.Result(&album, -1)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.SL.IsSet).Should(BeFalse())
+		Ω(res.TR.IsSet).Should(BeFalse())
+		_, ok = res.NamedGet("SL")
+		Ω(ok).Should(BeFalse())
+	})
+})
@@ -0,0 +1,114 @@
+package share
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheVersion is incremented whenever the format of the cache file changes,
+// cache files of other versions are discarded.
+const cacheVersion = 1
+
+// cacheEntry is the hash of a local file, it is valid as long as the size and
+// modification time of the file do not change.
+type cacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	TTH     string `json:"tth"`
+	TTHL    []byte `json:"tthl,omitempty"`
+}
+
+type cacheFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]*cacheEntry `json:"entries"`
+}
+
+// cache stores the hashes of local files keyed by their path. It is not safe
+// for concurrent use.
+type cache struct {
+	path    string
+	entries map[string]*cacheEntry
+}
+
+// loadCache loads the cache stored at path. A missing file or a file of
+// another cache version results in an empty cache. If path is empty, the
+// cache is not persisted.
+func loadCache(path string) (*cache, error) {
+	c := &cache{path: path, entries: make(map[string]*cacheEntry)}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var f cacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Version == cacheVersion && f.Entries != nil {
+		c.entries = f.Entries
+	}
+
+	return c, nil
+}
+
+// get returns the entry of the local file path, if the size and modification
+// time of the entry match.
+func (c *cache) get(path string, size int64, modTime time.Time) (*cacheEntry, bool) {
+	e, ok := c.entries[path]
+	if !ok || e.Size != size || e.ModTime != modTime.UnixNano() {
+		return nil, false
+	}
+
+	return e, true
+}
+
+func (c *cache) put(path string, e *cacheEntry) {
+	c.entries[path] = e
+}
+
+// prune removes all entries whose path is not contained in keep.
+func (c *cache) prune(keep map[string]bool) {
+	for path := range c.entries {
+		if !keep[path] {
+			delete(c.entries, path)
+		}
+	}
+}
+
+// save writes the cache to its file. The file is replaced atomically, so
+// that a crash does not leave a corrupt cache behind.
+func (c *cache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cacheFile{Version: cacheVersion, Entries: c.entries})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,95 @@
+package share
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/tth"
+)
+
+// hashWorker hashes queued files until the share is closed.
+func (s *Share) hashWorker() {
+	defer s.workers.Done()
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+
+		f := s.queue[0]
+		s.queue = s.queue[1:]
+		s.active++
+		local, size, modTime := f.LocalPath, f.Size, f.ModTime
+		s.mu.Unlock()
+
+		root, tthl, err := hashFile(local, size, modTime)
+
+		s.mu.Lock()
+		s.active--
+		f.queued = false
+
+		// f may have been removed by Refresh in the meantime.
+		hashed := err == nil && s.files[f.Path] == f
+		if hashed {
+			f.TTH, f.TTHL = root, tthl
+			s.add(f)
+			s.cache.put(local, &cacheEntry{
+				Size:    size,
+				ModTime: modTime.UnixNano(),
+				TTH:     root.String(),
+				TTHL:    tthl,
+			})
+		}
+
+		// Saving the cache after each file would be quadratic, it is
+		// saved once the queue has been drained.
+		if len(s.queue) == 0 && s.active == 0 {
+			_ = s.cache.save()
+		}
+		s.mu.Unlock()
+
+		if hashed {
+			s.changed()
+		}
+		s.pending.Done()
+	}
+}
+
+// errFileChanged is returned by hashFile if the file has been modified since
+// it has been found by Refresh.
+var errFileChanged = errors.New("file changed while hashing")
+
+// hashFile computes the Tiger tree hash root and the leaf data of the local
+// file path. errFileChanged is returned if the size or modification time of
+// the file do not match size and modTime anymore.
+func hashFile(path string, size int64, modTime time.Time) (root *encoding.Base32Value, tthl []byte, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	tree := tth.New()
+	n, err := io.Copy(tree, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if n != size || info.Size() != size || !info.ModTime().Equal(modTime) {
+		return nil, nil, errFileChanged
+	}
+
+	return tree.Root(), tree.TTHL(), nil
+}
@@ -0,0 +1,421 @@
+// Package share maintains the index of the files shared by a client.
+//
+// The configured directories are walked by Refresh, files are hashed in the
+// background by a pool of workers. Only hashed files are shared, i.e. found
+// by lookups and searches and counted in the SS and SF fields of the INF.
+// Hashes are persisted in a cache file, files are not hashed again as long as
+// their size and modification time do not change.
+//
+// Usage:
+//
+//	s, err := share.New(share.Config{
+//	    Dirs:      map[string]string{"Music": "/home/user/music"},
+//	    CachePath: "/home/user/.adcl/hashes.json",
+//	})
+//	if err != nil {
+//	    ...
+//	}
+//	defer s.Close()
+//
+//	if err := s.Refresh(); err != nil {
+//	    ...
+//	}
+package share
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/search"
+)
+
+// Error variables related to Share.
+var (
+	ErrInvalidName = errors.New("invalid share name, it must not be empty or contain a slash")
+	ErrClosed      = errors.New("share has been closed")
+)
+
+// Config contains the parameters of a Share.
+type Config struct {
+	// Dirs maps the names of the top level directories of the share to the
+	// local directories shared under these names.
+	Dirs map[string]string
+
+	// CachePath is the path of the file hashes are persisted in. If it is
+	// empty, hashes are not persisted.
+	CachePath string
+
+	// Workers is the number of files hashed concurrently. It defaults to
+	// the number of CPUs.
+	Workers int
+
+	// OnChange is called whenever the shared files change, i.e. files have
+	// been hashed or removed. It is meant to trigger sending the new SS and
+	// SF fields, see SetINF. OnChange is called on the share's goroutines
+	// and must not block.
+	OnChange func()
+}
+
+// File is a file of the share.
+type File struct {
+	// Path is the virtual path of the file, i.e. its path in the file
+	// list, e.g. "/Music/song.mp3".
+	Path      string
+	LocalPath string
+	Size      int64
+	ModTime   time.Time
+
+	// TTH is the Tiger tree hash root of the file, nil if the file has not
+	// been hashed yet.
+	TTH *encoding.Base32Value
+	// TTHL is the leaf data of the file, i.e. the hashes of one level of the
+	// Tiger tree as served in the tthl namespace.
+	TTHL []byte
+
+	// queued is true while the file is waiting to be hashed or being
+	// hashed.
+	queued bool
+}
+
+// Entry returns f as entry to be matched by searches.
+func (f *File) Entry() search.Entry {
+	return search.Entry{Path: f.Path, Size: f.Size, TTH: f.TTH}
+}
+
+// Share is the index of shared files. It is safe for concurrent use.
+type Share struct {
+	config Config
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	files map[string]*File
+	// byTTH holds one of the hashed files for each TTH, it is only used by
+	// ByTTH.
+	byTTH map[string]*File
+	// size and count are the total size and number of hashed files.
+	size  int64
+	count int
+	// generation is incremented whenever the hashed files change.
+	generation uint64
+	cache      *cache
+
+	queue  []*File
+	active int
+	closed bool
+
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+// New creates a new Share and loads the hash cache. The share is empty until
+// Refresh is called.
+func New(config Config) (*Share, error) {
+	for name := range config.Dirs {
+		if name == "" || strings.Contains(name, "/") {
+			return nil, ErrInvalidName
+		}
+	}
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	c, err := loadCache(config.CachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Share{
+		config: config,
+		files:  make(map[string]*File),
+		byTTH:  make(map[string]*File),
+		cache:  c,
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	s.workers.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go s.hashWorker()
+	}
+
+	return s, nil
+}
+
+// Refresh walks the configured directories. New and modified files are
+// queued for hashing, unless their hashes are found in the cache. Removed
+// files are removed from the share. Refresh returns once the directories have
+// been walked, use Wait to wait for hashing to finish.
+func (s *Share) Refresh() error {
+	found := make(map[string]*File)
+	for name, dir := range s.config.Dirs {
+		if err := walk(name, dir, found); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	keep := make(map[string]bool, len(found))
+	for vpath, f := range found {
+		keep[f.LocalPath] = true
+
+		if old, ok := s.files[vpath]; ok && old.LocalPath == f.LocalPath &&
+			old.Size == f.Size && old.ModTime.Equal(f.ModTime) && (old.TTH != nil || old.queued) {
+			found[vpath] = old
+			continue
+		}
+
+		if e, ok := s.cache.get(f.LocalPath, f.Size, f.ModTime); ok {
+			if tth, err := encoding.ParseBase32Value(e.TTH); err == nil {
+				f.TTH, f.TTHL = tth, e.TTHL
+				continue
+			}
+		}
+
+		f.queued = true
+		s.queue = append(s.queue, f)
+		s.pending.Add(1)
+	}
+
+	s.files = found
+	s.reindex()
+	s.cache.prune(keep)
+	s.cond.Broadcast()
+
+	err := s.cache.save()
+	s.changed()
+
+	return err
+}
+
+// walk adds all regular files below the local directory dir to found, the
+// virtual paths of the files start with "/name/". Subdirectories which
+// cannot be read are skipped.
+func walk(name, dir string, found map[string]*File) error {
+	return filepath.Walk(dir, func(local string, info os.FileInfo, err error) error {
+		if err != nil {
+			if local == dir {
+				return err
+			}
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, local)
+		if err != nil {
+			return err
+		}
+
+		vpath := "/" + name + "/" + filepath.ToSlash(rel)
+		found[vpath] = &File{
+			Path:      vpath,
+			LocalPath: local,
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+		}
+
+		return nil
+	})
+}
+
+// reindex rebuilds the TTH index and the totals from s.files. s.mu must be
+// held.
+func (s *Share) reindex() {
+	s.byTTH = make(map[string]*File, len(s.files))
+	s.size, s.count = 0, 0
+	s.generation++
+
+	for _, f := range s.files {
+		if f.TTH != nil {
+			s.add(f)
+		}
+	}
+}
+
+// add adds the hashed file f to the TTH index and the totals. s.mu must be
+// held.
+func (s *Share) add(f *File) {
+	s.byTTH[f.TTH.String()] = f
+	s.size += f.Size
+	s.count++
+	s.generation++
+}
+
+// changed calls OnChange.
+func (s *Share) changed() {
+	if s.config.OnChange != nil {
+		s.config.OnChange()
+	}
+}
+
+// Wait waits until all files queued by Refresh have been hashed. Wait must
+// not be called concurrently with Refresh.
+func (s *Share) Wait() {
+	s.pending.Wait()
+}
+
+// Close stops hashing and saves the cache. Files which have not been hashed
+// yet are hashed again after the next Refresh.
+func (s *Share) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	for range s.queue {
+		s.pending.Done()
+	}
+	s.queue = nil
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.workers.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.save()
+}
+
+// Stats returns the total size in bytes and the number of shared files.
+func (s *Share) Stats() (size int64, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size, s.count
+}
+
+// Generation returns a number which is incremented whenever the shared files
+// change. It allows caching data derived from Files, e.g. file lists.
+func (s *Share) Generation() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.generation
+}
+
+// SetINF sets the SS (share size) and SF (shared files) fields of info. The
+// share size is capped to the largest int, as SS holds an int.
+func (s *Share) SetINF(info *message.INFContent) {
+	size, count := s.Stats()
+	if size > math.MaxInt {
+		size = math.MaxInt
+	}
+
+	cons := message.INFContentConstructor{Content: info}
+	cons.SetSS(int(size), string(message.INFFlagSS)+strconv.FormatInt(size, 10))
+	cons.SetSF(count, string(message.INFFlagSF)+strconv.Itoa(count))
+}
+
+// ByTTH returns the shared file with the Tiger tree hash root tth.
+func (s *Share) ByTTH(tth *encoding.Base32Value) (File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.byTTH[tth.String()]
+	if !ok {
+		return File{}, false
+	}
+
+	return *f, true
+}
+
+// ByPath returns the shared file with the virtual path vpath.
+func (s *Share) ByPath(vpath string) (File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[vpath]
+	if !ok || f.TTH == nil {
+		return File{}, false
+	}
+
+	return *f, true
+}
+
+// Files returns all shared files sorted by their virtual path.
+func (s *Share) Files() []File {
+	s.mu.Lock()
+	files := make([]File, 0, s.count)
+	for _, f := range s.files {
+		if f.TTH != nil {
+			files = append(files, *f)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files
+}
+
+// Search returns up to max entries matching q, in no particular order. The
+// entries include directories, the size of a directory is the total size of
+// the files it contains. A negative max returns all matching entries.
+func (s *Share) Search(q *search.Query, max int) []search.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []search.Entry
+	full := func() bool {
+		return max >= 0 && len(results) >= max
+	}
+
+	dirs := make(map[string]int64)
+
+	// s.files is searched rather than s.byTTH, which holds only one of
+	// several files with the same content.
+	for _, f := range s.files {
+		if f.TTH == nil {
+			continue
+		}
+		if full() {
+			return results
+		}
+
+		e := f.Entry()
+		if q.Match(&e) {
+			results = append(results, e)
+		}
+
+		for dir := path.Dir(f.Path); dir != "/"; dir = path.Dir(dir) {
+			dirs[dir+"/"] += f.Size
+		}
+	}
+
+	for dir, size := range dirs {
+		if full() {
+			break
+		}
+
+		e := search.Entry{Path: dir, Size: size, IsDir: true}
+		if q.Match(&e) {
+			results = append(results, e)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,229 @@
+package share_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/search"
+	. "github.com/seoester/adcl/share"
+	"github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func tthOf(data string) *encoding.Base32Value {
+	sum := tth.Sum([]byte(data))
+	return encoding.NewBase32Value(sum[:])
+}
+
+func writeFile(path, data string) {
+	ExpectWithOffset(1, os.MkdirAll(filepath.Dir(path), 0755)).Should(Succeed())
+	ExpectWithOffset(1, ioutil.WriteFile(path, []byte(data), 0644)).Should(Succeed())
+}
+
+var _ = Describe("Share", func() {
+	var (
+		dir, musicDir, cachePath string
+		changes                  int32
+		config                   Config
+	)
+
+	song := strings.Repeat("la", 2000)
+	lyrics := "la la la"
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "share")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		musicDir = filepath.Join(dir, "music")
+		cachePath = filepath.Join(dir, "hashes.json")
+		writeFile(filepath.Join(musicDir, "Band", "song.mp3"), song)
+		writeFile(filepath.Join(musicDir, "Band", "lyrics.txt"), lyrics)
+
+		atomic.StoreInt32(&changes, 0)
+		config = Config{
+			Dirs:      map[string]string{"Music": musicDir},
+			CachePath: cachePath,
+			Workers:   2,
+			OnChange: func() {
+				atomic.AddInt32(&changes, 1)
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	newShare := func() *Share {
+		s, err := New(config)
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+		return s
+	}
+
+	It("should hash and index all files", func() {
+		s := newShare()
+		defer s.Close()
+
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		size, count := s.Stats()
+		Ω(size).Should(Equal(int64(len(song) + len(lyrics))))
+		Ω(count).Should(Equal(2))
+		Ω(atomic.LoadInt32(&changes)).Should(BeNumerically(">=", 2))
+
+		f, ok := s.ByTTH(tthOf(song))
+		Ω(ok).Should(BeTrue())
+		Ω(f.Path).Should(Equal("/Music/Band/song.mp3"))
+		Ω(f.LocalPath).Should(Equal(filepath.Join(musicDir, "Band", "song.mp3")))
+		Ω(f.TTHL).Should(HaveLen(4 * tth.Size))
+
+		f, ok = s.ByPath("/Music/Band/lyrics.txt")
+		Ω(ok).Should(BeTrue())
+		Ω(f.TTH.String()).Should(Equal(tthOf(lyrics).String()))
+
+		_, ok = s.ByPath("/Music/Band/missing.txt")
+		Ω(ok).Should(BeFalse())
+
+		files := s.Files()
+		Ω(files).Should(HaveLen(2))
+		Ω(files[0].Path).Should(Equal("/Music/Band/lyrics.txt"))
+		Ω(files[1].Path).Should(Equal("/Music/Band/song.mp3"))
+
+		info := &message.INFContent{}
+		s.SetINF(info)
+		Ω(info.SS.Value).Should(Equal(len(song) + len(lyrics)))
+		Ω(info.SF.Value).Should(Equal(2))
+		Ω(info.Named()).Should(HaveKeyWithValue("SS", strconv.Itoa(len(song)+len(lyrics))))
+		Ω(info.Named()).Should(HaveKeyWithValue("SF", "2"))
+	})
+
+	It("should answer searches", func() {
+		s := newShare()
+		defer s.Close()
+
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		sch, err := parser.ParseSCHContent(parser.NewMessageReader("ANband"))
+		Ω(err).ShouldNot(HaveOccurred())
+		q, err := search.Compile(&sch)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		results := s.Search(q, -1)
+		var paths []string
+		for _, e := range results {
+			paths = append(paths, e.Path)
+			if e.IsDir {
+				Ω(e.Size).Should(Equal(int64(len(song) + len(lyrics))))
+			}
+		}
+		Ω(paths).Should(ConsistOf("/Music/Band/", "/Music/Band/song.mp3", "/Music/Band/lyrics.txt"))
+		Ω(s.Search(q, 1)).Should(HaveLen(1))
+	})
+
+	It("should find all files with the same content", func() {
+		writeFile(filepath.Join(musicDir, "Copy", "song.mp3"), song)
+		s := newShare()
+		defer s.Close()
+
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		size, count := s.Stats()
+		Ω(size).Should(Equal(int64(2*len(song) + len(lyrics))))
+		Ω(count).Should(Equal(3))
+
+		sch, err := parser.ParseSCHContent(parser.NewMessageReader("ANsong"))
+		Ω(err).ShouldNot(HaveOccurred())
+		q, err := search.Compile(&sch)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var paths []string
+		for _, e := range s.Search(q, -1) {
+			paths = append(paths, e.Path)
+		}
+		Ω(paths).Should(ConsistOf("/Music/Band/song.mp3", "/Music/Copy/song.mp3"))
+
+		sch, err = parser.ParseSCHContent(parser.NewMessageReader("ANmusic"))
+		Ω(err).ShouldNot(HaveOccurred())
+		q, err = search.Compile(&sch)
+		Ω(err).ShouldNot(HaveOccurred())
+		sizes := make(map[string]int64)
+		for _, e := range s.Search(q, -1) {
+			if e.IsDir {
+				sizes[e.Path] = e.Size
+			}
+		}
+		Ω(sizes).Should(HaveKeyWithValue("/Music/Copy/", int64(len(song))))
+		Ω(sizes).Should(HaveKeyWithValue("/Music/Band/", int64(len(song)+len(lyrics))))
+	})
+
+	It("should use cached hashes of unchanged files", func() {
+		s := newShare()
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+		Ω(s.Close()).Should(Succeed())
+		Ω(cachePath).Should(BeAnExistingFile())
+
+		// Modify one file, the other one is taken from the cache.
+		lyricsPath := filepath.Join(musicDir, "Band", "lyrics.txt")
+		writeFile(lyricsPath, "new lyrics")
+		later := time.Now().Add(time.Minute)
+		Ω(os.Chtimes(lyricsPath, later, later)).Should(Succeed())
+
+		config.Workers = 1
+		s = newShare()
+		defer s.Close()
+
+		Ω(s.Refresh()).Should(Succeed())
+		_, ok := s.ByTTH(tthOf(song))
+		Ω(ok).Should(BeTrue())
+
+		s.Wait()
+		_, ok = s.ByTTH(tthOf("new lyrics"))
+		Ω(ok).Should(BeTrue())
+		_, ok = s.ByTTH(tthOf(lyrics))
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("should remove deleted files", func() {
+		s := newShare()
+		defer s.Close()
+
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		Ω(os.Remove(filepath.Join(musicDir, "Band", "song.mp3"))).Should(Succeed())
+		Ω(s.Refresh()).Should(Succeed())
+
+		_, ok := s.ByTTH(tthOf(song))
+		Ω(ok).Should(BeFalse())
+		_, count := s.Stats()
+		Ω(count).Should(Equal(1))
+	})
+
+	It("should reject invalid names", func() {
+		config.Dirs = map[string]string{"a/b": musicDir}
+		_, err := New(config)
+		Ω(err).Should(Equal(ErrInvalidName))
+	})
+
+	It("should fail after being closed", func() {
+		s := newShare()
+		Ω(s.Close()).Should(Succeed())
+		Ω(s.Refresh()).Should(Equal(ErrClosed))
+		Ω(s.Close()).Should(Equal(ErrClosed))
+	})
+})
@@ -0,0 +1,416 @@
+// Package slots implements the upload slot policy of a client.
+//
+// A peer requesting data must be granted a slot first. The number of slots is
+// announced in the SL field of the INF. If the AS field is set, additional
+// slots are opened as long as the total upload speed stays below it, keeping
+// at least AM slots. Requests for file lists, Tiger tree leaves and small
+// files are served on separate mini slots. Peers refused a slot are queued
+// and told their queue position in the STA (EXT QP); a slot becoming free is
+// reserved for the peers at the front of the queue.
+//
+// Usage:
+//
+//	m, err := slots.New(slots.Config{
+//	    Slots:    3,
+//	    OnChange: func() { changed <- struct{}{} },
+//	})
+//	if err != nil {
+//	    ...
+//	}
+//
+//	// On receiving from changed.
+//	m.SetINF(info)
+//	err = c.UpdateINF(info)
+//
+//	// For each client-client connection.
+//	src := slots.NewSource(m, conn.PeerCID().String(), shareSource)
+//	defer src.Close()
+//	err := conn.Serve(src)
+//
+// Like the share package, Manager does not start any goroutines.
+package slots
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seoester/adcl/filelist"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/transfer"
+)
+
+// Error variables related to Manager.
+var (
+	ErrInvalidSlots = errors.New("invalid number of slots, it must not be negative")
+)
+
+// Constants related to Manager.
+const (
+	// DefaultMiniSlots is the default number of mini slots.
+	DefaultMiniSlots = 3
+	// DefaultMiniSize is the default size up to which files are served on
+	// mini slots.
+	DefaultMiniSize int64 = 64 << 10
+	// DefaultQueueTimeout is the default time a queued peer keeps its
+	// position without requesting again.
+	DefaultQueueTimeout = 2 * time.Minute
+
+	// speedWindow is the period the upload speed is averaged over. At most
+	// one automatic slot is opened per period, as the speed of a new upload
+	// is not known before.
+	speedWindow = 10 * time.Second
+)
+
+// Kind is the kind of a slot.
+type Kind int
+
+// Kinds of slots.
+const (
+	// KindNormal is one of the slots announced in the SL field.
+	KindNormal Kind = iota
+	// KindAuto is a slot opened by the automatic slot allocator, see
+	// Config.AutoSpeed.
+	KindAuto
+	// KindMini is a slot for file lists, Tiger tree leaves and small files.
+	KindMini
+)
+
+// Config contains the parameters of a Manager.
+type Config struct {
+	// Slots is the number of simultaneous uploads, it is sent in the SL
+	// field.
+	Slots int
+	// AutoSpeed enables the automatic slot allocator if it is greater than
+	// 0: additional slots are opened as long as the total upload speed in
+	// bytes per second is below AutoSpeed. It is sent in the AS field.
+	AutoSpeed int
+	// AutoMin is the minimum number of slots if the automatic slot allocator
+	// is enabled. It is sent in the AM field.
+	AutoMin int
+
+	// MiniSlots is the number of mini slots, it defaults to
+	// DefaultMiniSlots. A negative value disables mini slots.
+	MiniSlots int
+	// MiniSize is the size up to which files are served on mini slots, it
+	// defaults to DefaultMiniSize.
+	MiniSize int64
+
+	// QueueTimeout is the time a queued peer keeps its position without
+	// requesting again, it defaults to DefaultQueueTimeout.
+	QueueTimeout time.Duration
+
+	// OnChange is called whenever the number of free slots changes. It is
+	// meant to trigger sending the new SL and FS fields, see SetINF.
+	// OnChange is called on the goroutine acquiring or releasing a slot and
+	// must not block.
+	OnChange func()
+}
+
+// queued is a peer waiting for a slot.
+type queued struct {
+	peer string
+	last time.Time
+}
+
+// sample is the number of bytes uploaded in a second.
+type sample struct {
+	at time.Time
+	n  int64
+}
+
+// Manager grants upload slots to peers. It is safe for concurrent use.
+type Manager struct {
+	config Config
+
+	mu    sync.Mutex
+	used  int
+	auto  int
+	mini  int
+	queue []queued
+	// lastAuto is the time the last automatic slot was opened.
+	lastAuto time.Time
+	samples  []sample
+}
+
+// New creates a Manager. ErrInvalidSlots is returned if Slots is negative.
+func New(config Config) (*Manager, error) {
+	if config.Slots < 0 {
+		return nil, ErrInvalidSlots
+	}
+	if config.MiniSlots == 0 {
+		config.MiniSlots = DefaultMiniSlots
+	} else if config.MiniSlots < 0 {
+		config.MiniSlots = 0
+	}
+	if config.MiniSize <= 0 {
+		config.MiniSize = DefaultMiniSize
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = DefaultQueueTimeout
+	}
+
+	return &Manager{config: config}, nil
+}
+
+// Slot is a slot granted to a peer.
+type Slot struct {
+	m        *Manager
+	kind     Kind
+	released bool
+}
+
+// Kind returns the kind of s.
+func (s *Slot) Kind() Kind {
+	return s.kind
+}
+
+// Release makes s available to other peers. Further calls have no effect.
+func (s *Slot) Release() {
+	s.m.release(s)
+}
+
+// Acquire grants a slot to the peer requesting the content of size bytes
+// described by get. peer identifies the peer, e.g. by its CID.
+//
+// If no slot is free, the peer is queued and a *message.StatusError with the
+// code 253 (slots full) and the QP flag set to its queue position is
+// returned. The peer keeps its position as long as it requests again within
+// QueueTimeout.
+func (m *Manager) Acquire(peer string, get *message.GETContent, size int64) (*Slot, error) {
+	m.mu.Lock()
+
+	now := time.Now()
+	m.expire(now)
+
+	if m.isMini(get, size) && m.mini < m.config.MiniSlots {
+		m.mini++
+		m.mu.Unlock()
+		return &Slot{m: m, kind: KindMini}, nil
+	}
+
+	pos := len(m.queue) + 1
+	for i, q := range m.queue {
+		if q.peer == peer {
+			pos = i + 1
+			break
+		}
+	}
+
+	kind := KindNormal
+	switch {
+	case pos <= m.free():
+	case pos == 1 && m.canOpenAuto(now):
+		kind = KindAuto
+		m.lastAuto = now
+	default:
+		if pos > len(m.queue) {
+			m.queue = append(m.queue, queued{peer: peer})
+		}
+		m.queue[pos-1].last = now
+		m.mu.Unlock()
+
+		return nil, slotsFull(pos)
+	}
+
+	if pos <= len(m.queue) {
+		m.queue = append(m.queue[:pos-1], m.queue[pos:]...)
+	}
+	m.used++
+	if kind == KindAuto {
+		m.auto++
+	}
+	m.mu.Unlock()
+
+	m.changed()
+	return &Slot{m: m, kind: kind}, nil
+}
+
+func (m *Manager) release(s *Slot) {
+	m.mu.Lock()
+	if s.released {
+		m.mu.Unlock()
+		return
+	}
+	s.released = true
+
+	if s.kind == KindMini {
+		m.mini--
+		m.mu.Unlock()
+		return
+	}
+	m.used--
+	if s.kind == KindAuto {
+		m.auto--
+	}
+	m.mu.Unlock()
+
+	m.changed()
+}
+
+// slotsFull returns the error reported to a peer at the queue position pos.
+func slotsFull(pos int) *message.StatusError {
+	sErr := &message.StatusError{
+		Code: message.StatusCode{
+			Severity: message.SeverityFatal,
+			Error:    message.ErrorCodeSlotsFull,
+		},
+		Description: "slots full",
+	}
+	sErr.QP.Set(pos)
+
+	return sErr
+}
+
+// isMini reports whether the request get for content of size bytes may be
+// served on a mini slot.
+func (m *Manager) isMini(get *message.GETContent, size int64) bool {
+	switch get.Namespace {
+	case transfer.NamespaceList, transfer.NamespaceTTHL:
+		return true
+	case transfer.NamespaceFile:
+		return get.Identifer == filelist.FileName || size <= m.config.MiniSize
+	default:
+		return false
+	}
+}
+
+// slots returns the number of slots, not counting automatic slots.
+func (m *Manager) slots() int {
+	if m.config.AutoSpeed > 0 && m.config.AutoMin > m.config.Slots {
+		return m.config.AutoMin
+	}
+
+	return m.config.Slots
+}
+
+// free returns the number of free slots.
+func (m *Manager) free() int {
+	if free := m.slots() + m.auto - m.used; free > 0 {
+		return free
+	}
+
+	return 0
+}
+
+// canOpenAuto reports whether the automatic slot allocator opens another
+// slot.
+func (m *Manager) canOpenAuto(now time.Time) bool {
+	if m.config.AutoSpeed <= 0 || now.Sub(m.lastAuto) < speedWindow {
+		return false
+	}
+
+	return m.speed(now) < int64(m.config.AutoSpeed)
+}
+
+// expire removes the peers which have not requested again within
+// QueueTimeout from the queue.
+func (m *Manager) expire(now time.Time) {
+	queue := m.queue[:0]
+	for _, q := range m.queue {
+		if now.Sub(q.last) < m.config.QueueTimeout {
+			queue = append(queue, q)
+		}
+	}
+	m.queue = queue
+}
+
+// Uploaded records that n bytes have been uploaded, it is used to measure
+// the upload speed for the automatic slot allocator.
+func (m *Manager) Uploaded(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Truncate(time.Second)
+	if l := len(m.samples); l > 0 && m.samples[l-1].at.Equal(now) {
+		m.samples[l-1].n += n
+		return
+	}
+	m.samples = append(m.samples, sample{at: now, n: n})
+}
+
+// Speed returns the total upload speed in bytes per second, averaged over the
+// last seconds.
+func (m *Manager) Speed() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.speed(time.Now())
+}
+
+func (m *Manager) speed(now time.Time) int64 {
+	i := 0
+	for i < len(m.samples) && now.Sub(m.samples[i].at) >= speedWindow {
+		i++
+	}
+	m.samples = m.samples[i:]
+
+	var total int64
+	for _, s := range m.samples {
+		total += s.n
+	}
+
+	return total / int64(speedWindow/time.Second)
+}
+
+// Free returns the number of free slots, not counting mini slots.
+func (m *Manager) Free() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.free()
+}
+
+// Queued returns the number of peers waiting for a slot.
+func (m *Manager) Queued() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(time.Now())
+	return len(m.queue)
+}
+
+// SetSlots changes the number of slots. Granted slots are not revoked if the
+// number decreases. ErrInvalidSlots is returned if slots is negative.
+func (m *Manager) SetSlots(slots int) error {
+	if slots < 0 {
+		return ErrInvalidSlots
+	}
+
+	m.mu.Lock()
+	m.config.Slots = slots
+	m.mu.Unlock()
+
+	m.changed()
+	return nil
+}
+
+// SetINF sets the SL (slots), AS and AM (automatic slot allocator) fields and
+// the FS (free slots) flag of info. AS and AM are removed if the automatic
+// slot allocator is disabled.
+func (m *Manager) SetINF(info *message.INFContent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cons := message.INFContentConstructor{Content: info}
+
+	slots := m.slots() + m.auto
+	cons.SetSL(slots, string(message.INFFlagSL)+strconv.Itoa(slots))
+	if m.config.AutoSpeed > 0 {
+		cons.SetAS(m.config.AutoSpeed, string(message.INFFlagAS)+strconv.Itoa(m.config.AutoSpeed))
+		cons.SetAM(m.config.AutoMin, string(message.INFFlagAM)+strconv.Itoa(m.config.AutoMin))
+	} else {
+		info.AS.Unset()
+		info.AM.Unset()
+	}
+
+	cons.SetFlag("FS", strconv.Itoa(m.free()))
+}
+
+// changed calls OnChange.
+func (m *Manager) changed() {
+	if m.config.OnChange != nil {
+		m.config.OnChange()
+	}
+}
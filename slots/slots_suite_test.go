@@ -0,0 +1,13 @@
+package slots_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSlots(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Slots Suite")
+}
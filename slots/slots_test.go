@@ -0,0 +1,329 @@
+package slots_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/seoester/adcl/filelist"
+	"github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	. "github.com/seoester/adcl/slots"
+	"github.com/seoester/adcl/transfer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func fileGET(identifier string) *message.GETContent {
+	return &message.GETContent{Namespace: transfer.NamespaceFile, Identifer: identifier, Bytes: -1}
+}
+
+// expectSlotsFull expects err to be the slots full error with the queue
+// position pos.
+func expectSlotsFull(err error, pos int) {
+	var sErr *message.StatusError
+	ExpectWithOffset(1, errors.As(err, &sErr)).Should(BeTrue())
+	ExpectWithOffset(1, sErr.Code.String()).Should(Equal("253"))
+	ExpectWithOffset(1, sErr.QP.IsSet).Should(BeTrue())
+	ExpectWithOffset(1, sErr.QP.Value).Should(Equal(pos))
+}
+
+// memSource serves the same data for all identifiers.
+type memSource struct {
+	data []byte
+	// opened is the number of calls to Open.
+	opened int32
+}
+
+func (m *memSource) Open(get *message.GETContent) (transfer.Content, error) {
+	atomic.AddInt32(&m.opened, 1)
+	return bytes.NewReader(m.data), nil
+}
+
+func (m *memSource) Info(gfi *message.GFIContent) (*message.RESContent, error) {
+	return &message.RESContent{FN: gfi.Identifer, SI: len(m.data)}, nil
+}
+
+var _ = Describe("Manager", func() {
+	const large = 1 << 20
+
+	var (
+		config  Config
+		changes int32
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&changes, 0)
+		config = Config{
+			Slots: 2,
+			OnChange: func() {
+				atomic.AddInt32(&changes, 1)
+			},
+		}
+	})
+
+	It("should reject a negative number of slots", func() {
+		config.Slots = -1
+		_, err := New(config)
+		Ω(err).Should(Equal(ErrInvalidSlots))
+	})
+
+	It("should grant the configured slots and queue further peers", func() {
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		a, err := m.Acquire("a", fileGET("/a"), large)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(a.Kind()).Should(Equal(KindNormal))
+		_, err = m.Acquire("b", fileGET("/b"), large)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(m.Free()).Should(Equal(0))
+
+		_, err = m.Acquire("c", fileGET("/c"), large)
+		expectSlotsFull(err, 1)
+		_, err = m.Acquire("d", fileGET("/d"), large)
+		expectSlotsFull(err, 2)
+		_, err = m.Acquire("c", fileGET("/c"), large)
+		expectSlotsFull(err, 1)
+		Ω(m.Queued()).Should(Equal(2))
+
+		a.Release()
+		a.Release()
+		Ω(m.Free()).Should(Equal(1))
+
+		// The free slot is reserved for the first peer in the queue.
+		_, err = m.Acquire("d", fileGET("/d"), large)
+		expectSlotsFull(err, 2)
+		c, err := m.Acquire("c", fileGET("/c"), large)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(c.Kind()).Should(Equal(KindNormal))
+
+		_, err = m.Acquire("d", fileGET("/d"), large)
+		expectSlotsFull(err, 1)
+		Ω(m.Queued()).Should(Equal(1))
+
+		Ω(atomic.LoadInt32(&changes)).Should(BeEquivalentTo(4))
+	})
+
+	It("should report the slots full error in STA messages", func() {
+		config.Slots = 0
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = m.Acquire("a", fileGET("/a"), large)
+		cnt, err := message.NewSTAContent(err)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cnt.Code.String()).Should(Equal("253"))
+		Ω(cnt.Flags).Should(HaveKeyWithValue("QP", "1"))
+	})
+
+	It("should drop peers not requesting again from the queue", func() {
+		config.Slots = 0
+		config.QueueTimeout = 20 * time.Millisecond
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = m.Acquire("a", fileGET("/a"), large)
+		expectSlotsFull(err, 1)
+		_, err = m.Acquire("b", fileGET("/b"), large)
+		expectSlotsFull(err, 2)
+
+		time.Sleep(30 * time.Millisecond)
+		_, err = m.Acquire("b", fileGET("/b"), large)
+		expectSlotsFull(err, 1)
+	})
+
+	It("should serve file lists, leaves and small files on mini slots", func() {
+		config.Slots = 0
+		config.MiniSlots = 4
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		requests := []*message.GETContent{
+			fileGET(filelist.FileName),
+			{Namespace: transfer.NamespaceList, Identifer: "/", Bytes: -1},
+			{Namespace: transfer.NamespaceTTHL, Identifer: "TTH/ABC", Bytes: -1},
+			fileGET("/small"),
+		}
+		var granted []*Slot
+		for _, get := range requests {
+			slot, err := m.Acquire("a", get, DefaultMiniSize)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(slot.Kind()).Should(Equal(KindMini))
+			granted = append(granted, slot)
+		}
+
+		_, err = m.Acquire("b", fileGET(filelist.FileName), large)
+		expectSlotsFull(err, 1)
+		_, err = m.Acquire("a", fileGET("/large"), DefaultMiniSize+1)
+		expectSlotsFull(err, 2)
+
+		granted[0].Release()
+		slot, err := m.Acquire("b", fileGET(filelist.FileName), large)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(slot.Kind()).Should(Equal(KindMini))
+
+		Ω(atomic.LoadInt32(&changes)).Should(BeEquivalentTo(0))
+	})
+
+	It("should disable mini slots", func() {
+		config.Slots = 0
+		config.MiniSlots = -1
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = m.Acquire("a", fileGET(filelist.FileName), large)
+		expectSlotsFull(err, 1)
+	})
+
+	It("should open automatic slots while the upload speed is low", func() {
+		config.Slots = 1
+		config.AutoSpeed = 1000
+		config.AutoMin = 2
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		for _, peer := range []string{"a", "b"} {
+			slot, err := m.Acquire(peer, fileGET("/"+peer), large)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(slot.Kind()).Should(Equal(KindNormal))
+		}
+
+		auto, err := m.Acquire("c", fileGET("/c"), large)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(auto.Kind()).Should(Equal(KindAuto))
+
+		// At most one automatic slot is opened until the speed of the new
+		// upload is known.
+		_, err = m.Acquire("d", fileGET("/d"), large)
+		expectSlotsFull(err, 1)
+
+		auto.Release()
+		Ω(m.Free()).Should(Equal(0))
+	})
+
+	It("should not open automatic slots while the upload speed is high", func() {
+		config.Slots = 1
+		config.AutoSpeed = 1000
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		m.Uploaded(1 << 20)
+		Ω(m.Speed()).Should(BeNumerically(">=", 1000))
+
+		_, err = m.Acquire("a", fileGET("/a"), large)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = m.Acquire("b", fileGET("/b"), large)
+		expectSlotsFull(err, 1)
+	})
+
+	It("should set the slot fields of the INF", func() {
+		config.AutoSpeed = 1000
+		config.AutoMin = 3
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		info := &message.INFContent{}
+		m.SetINF(info)
+		Ω(info.SL.Value).Should(Equal(3))
+		Ω(info.AS.Value).Should(Equal(1000))
+		Ω(info.AM.Value).Should(Equal(3))
+		Ω(info.Flags).Should(HaveKeyWithValue("FS", "3"))
+		Ω(info.Named()).Should(Equal(map[string]string{"SL": "3", "AS": "1000", "AM": "3", "FS": "3"}))
+
+		_, err = m.Acquire("a", fileGET("/a"), large)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		prev := &message.INFContent{}
+		prev.Merge(info)
+		m.SetINF(info)
+		update, changed := info.Diff(prev)
+		Ω(changed).Should(BeTrue())
+		Ω(update.SL.IsSet).Should(BeFalse())
+		Ω(update.Flags).Should(Equal(map[string]string{"FS": "2"}))
+	})
+
+	It("should announce changes of the number of slots", func() {
+		m, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(m.SetSlots(-1)).Should(Equal(ErrInvalidSlots))
+		Ω(m.SetSlots(5)).Should(Succeed())
+		Ω(atomic.LoadInt32(&changes)).Should(BeEquivalentTo(1))
+		Ω(m.Free()).Should(Equal(5))
+
+		info := &message.INFContent{}
+		m.SetINF(info)
+		Ω(info.SL.Value).Should(Equal(5))
+		Ω(info.AS.IsSet).Should(BeFalse())
+		Ω(info.AM.IsSet).Should(BeFalse())
+	})
+})
+
+var _ = Describe("Source", func() {
+	It("should serve peers holding a slot over client-client connections", func() {
+		m, err := New(Config{Slots: 1, MiniSize: 1})
+		Ω(err).ShouldNot(HaveOccurred())
+		mem := &memSource{data: bytes.Repeat([]byte("adcl"), 1000)}
+
+		uploader, err := identity.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		connect := func(name string) (*transfer.Conn, *Source, func()) {
+			peer, err := identity.Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+
+			peerConn, uploaderConn := net.Pipe()
+			src := NewSource(m, name, mem)
+			done := make(chan struct{})
+
+			go func(uploaderConn net.Conn, src *Source, cid *encoding.Base32Value) {
+				defer close(done)
+				defer uploaderConn.Close()
+				defer src.Close()
+				c := transfer.New(uploaderConn, transfer.Config{CID: uploader.CID})
+				if c.Accept(func(string) (*encoding.Base32Value, bool) { return cid, true }) == nil {
+					_ = c.Serve(src)
+				}
+			}(uploaderConn, src, peer.CID)
+
+			c := transfer.New(peerConn, transfer.Config{CID: peer.CID})
+			Ω(c.Connect("token", uploader.CID)).Should(Succeed())
+
+			return c, src, func() {
+				peerConn.Close()
+				<-done
+			}
+		}
+
+		a, _, closeA := connect("a")
+		b, _, closeB := connect("b")
+		defer closeB()
+
+		n, err := a.Download(ioutil.Discard, fileGET("/file"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(n).Should(BeEquivalentTo(len(mem.data)))
+		// The slot is kept for further requests.
+		_, err = a.Download(ioutil.Discard, fileGET("/file"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(m.Speed()).Should(BeNumerically(">", 0))
+
+		opened := atomic.LoadInt32(&mem.opened)
+		_, err = b.Download(ioutil.Discard, fileGET("/file"))
+		expectSlotsFull(err, 1)
+		// The content is not opened for peers refused a slot.
+		Ω(atomic.LoadInt32(&mem.opened)).Should(Equal(opened))
+
+		closeA()
+		Ω(m.Free()).Should(Equal(1))
+
+		n, err = b.Download(ioutil.Discard, fileGET("/file"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(n).Should(BeEquivalentTo(len(mem.data)))
+	})
+})
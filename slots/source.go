@@ -0,0 +1,134 @@
+package slots
+
+import (
+	"io"
+	"sync"
+
+	"github.com/seoester/adcl/filelist"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/transfer"
+)
+
+// Source serves the requests of a single peer from another transfer.Source,
+// as long as the peer has been granted a slot.
+//
+// A normal or automatic slot is held by the Source until Close is called, so
+// that the peer may request further segments on the connection. Mini slots
+// are released once the requested content has been served.
+type Source struct {
+	transfer.Source
+	m    *Manager
+	peer string
+
+	mu   sync.Mutex
+	slot *Slot
+}
+
+var _ transfer.Source = (*Source)(nil)
+
+// NewSource returns a Source serving the requests of the peer from src. peer
+// identifies the peer, e.g. by its CID.
+func NewSource(m *Manager, peer string, src transfer.Source) *Source {
+	return &Source{Source: src, m: m, peer: peer}
+}
+
+// Open returns the content requested by get, if the peer holds or is granted
+// a slot. Otherwise, the error returned by Manager.Acquire is returned, which
+// is reported to the peer by transfer.Conn.Serve. The underlying Source is
+// only asked to open the content once the slot has been granted.
+func (s *Source) Open(get *message.GETContent) (transfer.Content, error) {
+	s.mu.Lock()
+	held := s.slot != nil
+	s.mu.Unlock()
+	if held {
+		content, err := s.Source.Open(get)
+		if err != nil {
+			return nil, err
+		}
+		return &slotContent{Content: content, m: s.m}, nil
+	}
+
+	size, err := s.size(get)
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := s.m.Acquire(s.peer, get, size)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.Source.Open(get)
+	if err != nil {
+		slot.Release()
+		return nil, err
+	}
+
+	if slot.Kind() == KindMini {
+		return &slotContent{Content: content, m: s.m, slot: slot}, nil
+	}
+
+	s.mu.Lock()
+	s.slot = slot
+	s.mu.Unlock()
+
+	return &slotContent{Content: content, m: s.m}, nil
+}
+
+// size returns the size of the content requested by get without opening it.
+// Only the size of files is looked up, as all other requests may be served on
+// mini slots regardless of their size.
+func (s *Source) size(get *message.GETContent) (int64, error) {
+	if get.Namespace != transfer.NamespaceFile || get.Identifer == filelist.FileName {
+		return 0, nil
+	}
+
+	res, err := s.Source.Info(&message.GFIContent{Namespace: get.Namespace, Identifer: get.Identifer})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(res.SI), nil
+}
+
+// Close releases the slot held by the peer. It is to be called once the
+// connection has been closed.
+func (s *Source) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.slot != nil {
+		s.slot.Release()
+		s.slot = nil
+	}
+
+	return nil
+}
+
+// slotContent is content served to a peer holding a slot. The bytes read are
+// recorded for measuring the upload speed.
+type slotContent struct {
+	transfer.Content
+	m *Manager
+	// slot is the mini slot released by Close, if any.
+	slot *Slot
+}
+
+func (c *slotContent) ReadAt(p []byte, off int64) (n int, err error) {
+	n, err = c.Content.ReadAt(p, off)
+	c.m.Uploaded(int64(n))
+
+	return n, err
+}
+
+// Close releases the mini slot and closes the underlying content.
+func (c *slotContent) Close() error {
+	if c.slot != nil {
+		c.slot.Release()
+	}
+	if closer, ok := c.Content.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
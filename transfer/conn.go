@@ -0,0 +1,348 @@
+// Package transfer implements client-client connections, which are used to
+// transfer files, file lists and Tiger tree leaves.
+//
+// Connections are negotiated through the hub: a client sends CTM if it
+// listens for the connection, RCM if it wants the other client to listen (see
+// ConnectToMe and RevConnectToMe). The client opening the TCP connection runs
+// the handshake with Connect, the listening client with Accept. Afterwards
+// either side may request data with Get or serve requests with Serve.
+//
+// Usage:
+//
+//	c := transfer.New(conn, transfer.Config{CID: cid})
+//	if err := c.Connect(token, peerCID); err != nil {
+//	    ...
+//	}
+//
+//	n, err := c.Download(file, &message.GETContent{
+//	    Namespace: transfer.NamespaceFile,
+//	    Identifer: transfer.TTHIdentifier(tth),
+//	    Bytes:     -1,
+//	})
+//
+// Like the client package, Conn does not start any goroutines.
+package transfer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/protocol/parser"
+	"github.com/seoester/adcl/protocol/writer"
+)
+
+// Error variables related to Conn.
+var (
+	ErrMissingIdentity    = errors.New("CID is required")
+	ErrInvalidState       = errors.New("operation is not allowed in the current state")
+	ErrUnexpectedMessage  = errors.New("message is not allowed in the current state")
+	ErrBASENotSupported   = errors.New("peer does not support BASE")
+	ErrMissingINFField    = errors.New("INF of peer lacks ID or TO")
+	ErrUnknownToken       = errors.New("peer sent an unknown token")
+	ErrCIDMismatch        = errors.New("peer's CID does not match the expected CID")
+	ErrUnexpectedResponse = errors.New("response does not match the request")
+	ErrShortContent       = errors.New("content is shorter than announced in SND")
+)
+
+// Features which are always announced in the SUP sent by a Conn.
+var defaultFeatures = []string{"BASE", "TIGR"}
+
+// Config contains the parameters of a client-client connection.
+type Config struct {
+	// CID identifies the client, it is sent in the INF. It is required.
+	CID *encoding.Base32Value
+
+	// Features are announced in addition to BASE and TIGR in the SUP sent to
+	// the peer.
+	Features []string
+}
+
+// Conn is a client-client connection.
+//
+// The methods running the handshake and the data transfers (Connect, Accept,
+// Get, Download, Info and Serve) must not be called concurrently.
+type Conn struct {
+	config Config
+	r      *bufio.Reader
+	w      *bufio.Writer
+	parser *parser.Parser
+	writer *writer.Writer
+
+	mu           sync.Mutex
+	state        message.State
+	token        string
+	peerCID      *encoding.Base32Value
+	peerFeatures message.FeatureSet
+}
+
+// New creates a new Conn communicating over conn. Nothing is sent until
+// Connect or Accept is called.
+func New(conn io.ReadWriter, config Config) *Conn {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	return &Conn{
+		config:       config,
+		r:            r,
+		w:            w,
+		parser:       parser.New(r),
+		writer:       writer.New(w, writer.FlushPolicy{}),
+		state:        message.StateProtocol,
+		peerFeatures: message.NewFeatureSet(),
+	}
+}
+
+// State returns the current state of the connection. It is StateNormal after
+// the handshake and StateData while data is transferred.
+func (c *Conn) State() message.State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+func (c *Conn) setState(state message.State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state = state
+}
+
+// Token returns the token of the connection, it is set by the handshake.
+func (c *Conn) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+// PeerCID returns the CID of the peer, it is set by the handshake.
+func (c *Conn) PeerCID() *encoding.Base32Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.peerCID
+}
+
+// PeerFeatures returns the features announced by the peer.
+func (c *Conn) PeerFeatures() message.FeatureSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return message.NewFeatureSet(c.peerFeatures.Features()...)
+}
+
+// Connect runs the handshake as the client which opened the connection. token
+// is the token of the CTM or RCM the connection has been negotiated with. If
+// peerCID is not nil, the peer must identify with it, otherwise
+// ErrCIDMismatch is returned.
+func (c *Conn) Connect(token string, peerCID *encoding.Base32Value) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+
+	if err := c.sendSUP(); err != nil {
+		return err
+	}
+	if err := c.readSUP(); err != nil {
+		return err
+	}
+
+	c.setState(message.StateIdentify)
+
+	rawToken, err := encoding.EncodeToADCString(token)
+	if err != nil {
+		return err
+	}
+
+	info := c.ownINF()
+	cons := message.INFContentConstructor{Content: info}
+	cons.SetTO(token, string(message.INFFlagTO)+rawToken)
+	if err := c.send(message.CommandINF, info); err != nil {
+		return err
+	}
+
+	peer, err := c.readINF()
+	if err != nil {
+		return err
+	}
+	if peerCID != nil && peer.ID.Value.String() != peerCID.String() {
+		return ErrCIDMismatch
+	}
+
+	c.mu.Lock()
+	c.token = token
+	c.peerCID = peer.ID.Value
+	c.state = message.StateNormal
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Accept runs the handshake as the client which listened for the connection.
+// expect returns the CID of the client a connection has been negotiated with
+// using token, see Tokens.Take. ErrUnknownToken is returned if expect does
+// not know the token sent by the peer or returns a nil CID, ErrCIDMismatch
+// if the peer identifies with another CID. In both cases, the peer is sent a
+// fatal STA.
+func (c *Conn) Accept(expect func(token string) (*encoding.Base32Value, bool)) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+
+	if err := c.readSUP(); err != nil {
+		return err
+	}
+	if err := c.sendSUP(); err != nil {
+		return err
+	}
+
+	c.setState(message.StateIdentify)
+
+	peer, err := c.readINF()
+	if err != nil {
+		return err
+	}
+	if !peer.TO.IsSet {
+		c.sendStatus(message.SeverityFatal, message.ErrorCodeINFField, "TO missing")
+		return ErrMissingINFField
+	}
+
+	cid, ok := expect(peer.TO.Value)
+	if !ok || cid == nil {
+		c.sendStatus(message.SeverityFatal, message.ErrorCodeProtocolGeneric, "unknown token")
+		return ErrUnknownToken
+	}
+	if cid.String() != peer.ID.Value.String() {
+		c.sendStatus(message.SeverityFatal, message.ErrorCodeProtocolGeneric, "CID does not match the hub")
+		return ErrCIDMismatch
+	}
+
+	if err := c.send(message.CommandINF, c.ownINF()); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.token = peer.TO.Value
+	c.peerCID = peer.ID.Value
+	c.state = message.StateNormal
+	c.mu.Unlock()
+
+	return nil
+}
+
+// begin checks that the handshake may be run.
+func (c *Conn) begin() error {
+	if c.config.CID == nil {
+		return ErrMissingIdentity
+	}
+	if c.State() != message.StateProtocol {
+		return ErrInvalidState
+	}
+
+	return nil
+}
+
+func (c *Conn) sendSUP() error {
+	sup := &message.SUPContent{}
+	for _, feature := range append(defaultFeatures, c.config.Features...) {
+		sup.FeatureOps = append(sup.FeatureOps, message.FeatureOp{
+			OpAction: message.FeatureOpAdd,
+			Feature:  feature,
+		})
+	}
+
+	return c.send(message.CommandSUP, sup)
+}
+
+func (c *Conn) readSUP() error {
+	mes, err := c.read(message.CommandSUP)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.peerFeatures.Apply(mes.Content.(*message.SUPContent).FeatureOps)
+	hasBASE := c.peerFeatures.Has("BASE")
+	c.mu.Unlock()
+
+	if !hasBASE {
+		c.sendStatus(message.SeverityFatal, message.ErrorCodeFeatureMissing, "BASE not supported")
+		return ErrBASENotSupported
+	}
+
+	return nil
+}
+
+// readINF reads the INF of the peer, which must contain ID.
+func (c *Conn) readINF() (*message.INFContent, error) {
+	mes, err := c.read(message.CommandINF)
+	if err != nil {
+		return nil, err
+	}
+
+	info := mes.Content.(*message.INFContent)
+	if !info.ID.IsSet {
+		c.sendStatus(message.SeverityFatal, message.ErrorCodeINFField, "ID missing")
+		return nil, ErrMissingINFField
+	}
+
+	return info, nil
+}
+
+// ownINF returns the INF identifying the client.
+func (c *Conn) ownINF() *message.INFContent {
+	info := &message.INFContent{}
+	cons := message.INFContentConstructor{Content: info}
+	cons.SetID(c.config.CID, "ID"+c.config.CID.String())
+
+	return info
+}
+
+// read reads the next message, which must be a C message with command.
+// Status messages are returned as *message.StatusError.
+func (c *Conn) read(command message.Command) (*message.Message, error) {
+	mes, err := c.parser.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	if mes.Type != message.TypeClientmessage {
+		return nil, ErrUnexpectedMessage
+	}
+	if mes.Command == message.CommandSTA && command != message.CommandSTA {
+		sErr, err := message.NewStatusError(mes.Content.(*message.STAContent))
+		if err != nil {
+			return nil, err
+		}
+		return nil, sErr
+	}
+	if mes.Command != command {
+		return nil, ErrUnexpectedMessage
+	}
+
+	return &mes, nil
+}
+
+// send sends a C message to the peer.
+func (c *Conn) send(command message.Command, content message.ParamAccessor) error {
+	return c.writer.WriteMessage(&message.Message{
+		Type:         message.TypeClientmessage,
+		Command:      command,
+		HeaderFields: message.ClientHeaderFields{},
+		Content:      content,
+	})
+}
+
+// sendStatus sends a CSTA message, errors are ignored as the connection is
+// about to be closed or the error has already been reported to the peer.
+func (c *Conn) sendStatus(severity message.Severity, code message.ErrorCode, description string) {
+	_ = c.send(message.CommandSTA, &message.STAContent{
+		Code:        message.StatusCode{Severity: severity, Error: code},
+		Description: description,
+	})
+}
@@ -0,0 +1,91 @@
+package transfer
+
+import (
+	"crypto/rand"
+	"strconv"
+	"sync"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Constants related to connection negotiation.
+const (
+	// Protocol is the protocol of unencrypted client-client connections as
+	// sent in CTM and RCM.
+	Protocol = "ADC/1.0"
+	// TokenSize is the number of random bytes of tokens created by
+	// NewToken.
+	TokenSize = 10
+)
+
+// NewToken creates a random token identifying a connection negotiated with
+// CTM or RCM.
+func NewToken() (string, error) {
+	buf := make([]byte, TokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToBase32String(buf), nil
+}
+
+// ConnectToMe returns the message (DCTM) asking the client targetSID to
+// connect to port, the sending client then calls Accept on the connection.
+func ConnectToMe(mySID, targetSID *encoding.Base32Value, port int, token string) *message.Message {
+	return &message.Message{
+		Type:         message.TypeDirectmessage,
+		Command:      message.CommandCTM,
+		HeaderFields: message.DirectHeaderFields{MySID: mySID, TargetSID: targetSID},
+		Content: &message.CTMContent{
+			Protocol: Protocol,
+			Port:     strconv.Itoa(port),
+			Token:    token,
+		},
+	}
+}
+
+// RevConnectToMe returns the message (DRCM) asking the client targetSID to
+// send a CTM, i.e. to listen for a connection from the sending client.
+func RevConnectToMe(mySID, targetSID *encoding.Base32Value, token string) *message.Message {
+	return &message.Message{
+		Type:         message.TypeDirectmessage,
+		Command:      message.CommandRCM,
+		HeaderFields: message.DirectHeaderFields{MySID: mySID, TargetSID: targetSID},
+		Content: &message.RCMContent{
+			Protocol: Protocol,
+			Token:    token,
+		},
+	}
+}
+
+// Tokens stores the tokens of negotiated connections together with the CID
+// of the expected peer. It is safe for concurrent use.
+type Tokens struct {
+	mu     sync.Mutex
+	tokens map[string]*encoding.Base32Value
+}
+
+// Add adds token, which has been sent to or received from the client cid.
+// A token added with a nil CID is rejected by Conn.Accept.
+func (t *Tokens) Add(token string, cid *encoding.Base32Value) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tokens == nil {
+		t.tokens = make(map[string]*encoding.Base32Value)
+	}
+	t.tokens[token] = cid
+}
+
+// Take removes token and returns the CID it has been added with. Take is
+// meant to be passed to Conn.Accept.
+func (t *Tokens) Take(token string) (*encoding.Base32Value, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cid, ok := t.tokens[token]
+	delete(t.tokens, token)
+
+	return cid, ok
+}
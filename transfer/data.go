@@ -0,0 +1,247 @@
+package transfer
+
+import (
+	"io"
+
+	"github.com/seoester/adcl/protocol/message"
+)
+
+// Namespaces of GET and GFI requests.
+const (
+	NamespaceFile = "file"
+	NamespaceList = "list"
+	NamespaceTTHL = "tthl"
+)
+
+// Source provides the data served by Conn.Serve.
+//
+// Errors returned by Source are reported to the peer in a STA message, see
+// message.NewSTAContent, e.g. message.ErrorCodeFileNotAvailable.
+type Source interface {
+	// Open returns the content requested by get. get.StartPos and
+	// get.Bytes are checked against the size of the content by Serve. If
+	// the content implements io.Closer, it is closed once it has been sent.
+	Open(get *message.GETContent) (Content, error)
+	// Info returns the information about the file requested by gfi.
+	Info(gfi *message.GFIContent) (*message.RESContent, error)
+}
+
+// Content is data served in response to a GET, e.g. a *bytes.Reader.
+type Content interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// Get requests data from the peer. It returns the response (SND) and a
+// reader of the data, which must be read until io.EOF before the connection
+// can be used again. A STA sent by the peer is returned as
+// *message.StatusError.
+//
+// get.Bytes is the number of bytes requested starting at get.StartPos, -1
+// requests all remaining bytes.
+func (c *Conn) Get(get *message.GETContent) (*message.SNDContent, io.Reader, error) {
+	if c.State() != message.StateNormal {
+		return nil, nil, ErrInvalidState
+	}
+
+	if err := c.send(message.CommandGET, get); err != nil {
+		return nil, nil, err
+	}
+
+	mes, err := c.read(message.CommandSND)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snd := mes.Content.(*message.SNDContent)
+	if snd.Namespace != get.Namespace || snd.Identifer != get.Identifer ||
+		snd.StartPos != get.StartPos || snd.Bytes < 0 ||
+		(get.Bytes >= 0 && snd.Bytes > get.Bytes) {
+		return nil, nil, ErrUnexpectedResponse
+	}
+
+	r := &dataReader{c: c, n: int64(snd.Bytes)}
+	if r.n > 0 {
+		c.setState(message.StateData)
+	}
+
+	return snd, r, nil
+}
+
+// Download requests data from the peer like Get and writes it to w. It
+// returns the number of bytes written.
+func (c *Conn) Download(w io.Writer, get *message.GETContent) (n int64, err error) {
+	_, r, err := c.Get(get)
+	if err != nil {
+		return 0, err
+	}
+
+	return io.Copy(w, r)
+}
+
+// Info requests information about the file identifier in namespace from the
+// peer (GFI). A STA sent by the peer is returned as *message.StatusError.
+func (c *Conn) Info(namespace, identifier string) (*message.RESContent, error) {
+	if c.State() != message.StateNormal {
+		return nil, ErrInvalidState
+	}
+
+	err := c.send(message.CommandGFI, &message.GFIContent{
+		Namespace: namespace,
+		Identifer: identifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mes, err := c.read(message.CommandRES)
+	if err != nil {
+		return nil, err
+	}
+
+	return mes.Content.(*message.RESContent), nil
+}
+
+// dataReader reads the data following a SND message.
+type dataReader struct {
+	c *Conn
+	n int64
+}
+
+func (d *dataReader) Read(p []byte) (n int, err error) {
+	if d.n <= 0 {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > d.n {
+		p = p[:d.n]
+	}
+	n, err = d.c.r.Read(p)
+	d.n -= int64(n)
+
+	if d.n == 0 {
+		d.c.setState(message.StateNormal)
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+// Serve handles the requests of the peer (GET and GFI) until the peer closes
+// the connection, in which case nil is returned. Requests which cannot be
+// served are answered with a recoverable STA, an error is only returned if
+// the connection fails or the peer sends a fatal STA. ErrShortContent is
+// returned if the content ends before the number of bytes announced in SND
+// has been sent, the connection is out of sync and must be closed.
+func (c *Conn) Serve(src Source) error {
+	if c.State() != message.StateNormal {
+		return ErrInvalidState
+	}
+
+	for {
+		mes, err := c.parser.ReadMessage()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if mes.Type != message.TypeClientmessage {
+			return ErrUnexpectedMessage
+		}
+
+		switch mes.Command {
+		case message.CommandGET:
+			err = c.serveGET(src, mes.Content.(*message.GETContent))
+		case message.CommandGFI:
+			err = c.serveGFI(src, mes.Content.(*message.GFIContent))
+		case message.CommandSUP:
+			c.mu.Lock()
+			c.peerFeatures.Apply(mes.Content.(*message.SUPContent).FeatureOps)
+			c.mu.Unlock()
+		case message.CommandSTA:
+			err = c.handleSTA(mes.Content.(*message.STAContent))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Conn) serveGET(src Source, get *message.GETContent) error {
+	content, err := src.Open(get)
+	if err != nil {
+		return c.sendError(err)
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	start, size, n := int64(get.StartPos), content.Size(), int64(get.Bytes)
+	if n == -1 {
+		n = size - start
+	}
+	// n is compared with the remaining size, as start+n may overflow.
+	if start < 0 || start > size || n < 0 || n > size-start {
+		return c.sendError(message.ErrorCodeFilePartNotAvailable)
+	}
+
+	err = c.send(message.CommandSND, &message.SNDContent{
+		Namespace: get.Namespace,
+		Identifer: get.Identifer,
+		StartPos:  get.StartPos,
+		Bytes:     int(n),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.setState(message.StateData)
+	defer c.setState(message.StateNormal)
+
+	copied, err := io.Copy(c.w, io.NewSectionReader(content, start, n))
+	if err != nil {
+		return err
+	}
+	// The peer expects the number of bytes announced in SND, the connection
+	// cannot be used anymore if e.g. the file has shrunk since.
+	if copied < n {
+		return ErrShortContent
+	}
+
+	return c.w.Flush()
+}
+
+func (c *Conn) serveGFI(src Source, gfi *message.GFIContent) error {
+	res, err := src.Info(gfi)
+	if err != nil {
+		return c.sendError(err)
+	}
+
+	return c.send(message.CommandRES, res)
+}
+
+// handleSTA returns fatal errors reported by the peer.
+func (c *Conn) handleSTA(cnt *message.STAContent) error {
+	if cnt.Code.Severity != message.SeverityFatal {
+		return nil
+	}
+
+	sErr, err := message.NewStatusError(cnt)
+	if err != nil {
+		return err
+	}
+
+	return sErr
+}
+
+// sendError reports err to the peer in a recoverable STA.
+func (c *Conn) sendError(err error) error {
+	cnt, err := message.NewSTAContent(err)
+	if err != nil {
+		return err
+	}
+
+	return c.send(message.CommandSTA, cnt)
+}
@@ -0,0 +1,182 @@
+package transfer
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/seoester/adcl/filelist"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/share"
+)
+
+// tthPrefix is the prefix of file identifiers referring to a file by its
+// Tiger tree hash root.
+const tthPrefix = "TTH/"
+
+// TTHIdentifier returns the identifier of the file with the Tiger tree hash
+// root tth in the file and tthl namespaces.
+func TTHIdentifier(tth *encoding.Base32Value) string {
+	return tthPrefix + tth.String()
+}
+
+// errUnknownNamespace is reported to peers requesting an unsupported
+// namespace.
+var errUnknownNamespace = &message.StatusError{
+	Code: message.StatusCode{
+		Severity: message.SeverityRecoverable,
+		Error:    message.ErrorCodeTransferGeneric,
+	},
+	Description: "namespace not supported",
+}
+
+// ShareSource serves the files of a share, its file lists and the Tiger tree
+// leaves of its files. Files are identified by "TTH/" followed by their TTH
+// or by their virtual path.
+//
+// The compressed file list is built on the first request and kept until the
+// share changes. ShareSource is safe for concurrent use, a single ShareSource
+// should be used for all connections so that they share the cached list.
+type ShareSource struct {
+	Share *share.Share
+	// CID is the CID of the client, it is included in file lists.
+	CID *encoding.Base32Value
+
+	mu sync.Mutex
+	// fileList is the compressed file list built at the share generation
+	// listGen, nil if it has not been built yet.
+	fileList []byte
+	listGen  uint64
+}
+
+// fileContent is a shared file being served.
+type fileContent struct {
+	*os.File
+	size int64
+}
+
+func (f *fileContent) Size() int64 {
+	return f.size
+}
+
+// Open returns the content requested by get.
+func (s *ShareSource) Open(get *message.GETContent) (Content, error) {
+	switch get.Namespace {
+	case NamespaceFile:
+		if get.Identifer == filelist.FileName {
+			return s.compressedList()
+		}
+
+		f, ok := s.lookup(get.Identifer)
+		if !ok {
+			return nil, message.ErrorCodeFileNotAvailable
+		}
+		// The local path must not be disclosed in the STA sent to the
+		// peer.
+		file, err := os.Open(f.LocalPath)
+		if err != nil {
+			return nil, message.ErrorCodeFileNotAvailable
+		}
+		return &fileContent{File: file, size: f.Size}, nil
+
+	case NamespaceList:
+		return s.list(get)
+
+	case NamespaceTTHL:
+		f, ok := s.lookup(get.Identifer)
+		if !ok || !strings.HasPrefix(get.Identifer, tthPrefix) {
+			return nil, message.ErrorCodeFileNotAvailable
+		}
+		return bytes.NewReader(f.TTHL), nil
+
+	default:
+		return nil, errUnknownNamespace
+	}
+}
+
+// compressedList returns the complete compressed file list, it is rebuilt
+// only if the share has changed since it was built last.
+func (s *ShareSource) compressedList() (Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The generation is read before the files, a change in between only
+	// causes an unnecessary rebuild on the next request.
+	gen := s.Share.Generation()
+	if s.fileList == nil || s.listGen != gen {
+		var buf bytes.Buffer
+		err := filelist.WriteBZ2(&buf, s.Share.Files(), filelist.Options{CID: s.CID, Recursive: true})
+		if err != nil {
+			return nil, err
+		}
+		s.fileList, s.listGen = buf.Bytes(), gen
+	}
+
+	return bytes.NewReader(s.fileList), nil
+}
+
+// list returns the partial file list requested by get.
+func (s *ShareSource) list(get *message.GETContent) (Content, error) {
+	base, recursive, err := filelist.Request(get)
+	if err != nil {
+		return nil, message.ErrorCodeFileNotAvailable
+	}
+
+	files := s.Share.Files()
+	found := base == "/"
+	for i := 0; i < len(files) && !found; i++ {
+		found = strings.HasPrefix(files[i].Path, base)
+	}
+	if !found {
+		return nil, message.ErrorCodeFileNotAvailable
+	}
+
+	var buf bytes.Buffer
+	err = filelist.Write(&buf, files, filelist.Options{CID: s.CID, Base: base, Recursive: recursive})
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// Info returns the search result describing the file requested by gfi.
+func (s *ShareSource) Info(gfi *message.GFIContent) (*message.RESContent, error) {
+	if gfi.Namespace != NamespaceFile {
+		return nil, errUnknownNamespace
+	}
+
+	f, ok := s.lookup(gfi.Identifer)
+	if !ok {
+		return nil, message.ErrorCodeFileNotAvailable
+	}
+
+	fn, err := encoding.EncodeToADCString(f.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &message.RESContent{}
+	cons := message.RESContentConstructor{Content: res}
+	cons.SetFN(f.Path, string(message.RESFlagFN)+fn)
+	cons.SetSI(int(f.Size), string(message.RESFlagSI)+strconv.FormatInt(f.Size, 10))
+	cons.SetTR(f.TTH, string(message.RESFlagTR)+f.TTH.String())
+
+	return res, nil
+}
+
+// lookup returns the shared file identified by identifier.
+func (s *ShareSource) lookup(identifier string) (share.File, bool) {
+	if strings.HasPrefix(identifier, tthPrefix) {
+		tth, err := encoding.ParseBase32Value(strings.TrimPrefix(identifier, tthPrefix))
+		if err != nil {
+			return share.File{}, false
+		}
+		return s.Share.ByTTH(tth)
+	}
+
+	return s.Share.ByPath(identifier)
+}
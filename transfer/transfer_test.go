@@ -0,0 +1,293 @@
+package transfer_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/seoester/adcl/filelist"
+	"github.com/seoester/adcl/identity"
+	"github.com/seoester/adcl/protocol/encoding"
+	"github.com/seoester/adcl/protocol/message"
+	"github.com/seoester/adcl/share"
+	. "github.com/seoester/adcl/transfer"
+	"github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func tthOf(data string) *encoding.Base32Value {
+	sum := tth.Sum([]byte(data))
+	return encoding.NewBase32Value(sum[:])
+}
+
+func get(namespace, identifier string, start, n int) *message.GETContent {
+	return &message.GETContent{Namespace: namespace, Identifer: identifier, StartPos: start, Bytes: n}
+}
+
+var _ = Describe("Conn", func() {
+	var (
+		dir      string
+		s        *share.Share
+		uploader identity.Identity
+		loader   identity.Identity
+		tokens   *Tokens
+		token    string
+
+		l      net.Listener
+		served chan error
+	)
+
+	song := strings.Repeat("la", 2000)
+	lyrics := "la la la"
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "transfer")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(os.MkdirAll(filepath.Join(dir, "Band"), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "Band", "song.mp3"), []byte(song), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "lyrics.txt"), []byte(lyrics), 0644)).Should(Succeed())
+
+		s, err = share.New(share.Config{Dirs: map[string]string{"Music": dir}})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		uploader, err = identity.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		loader, err = identity.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		token, err = NewToken()
+		Ω(err).ShouldNot(HaveOccurred())
+		tokens = &Tokens{}
+		tokens.Add(token, loader.CID)
+
+		// The uploader listens (it sent CTM), the downloader connects.
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		served = make(chan error, 1)
+		go func(l net.Listener, s *share.Share, cid *encoding.Base32Value, tokens *Tokens, served chan<- error) {
+			conn, err := l.Accept()
+			if err != nil {
+				served <- err
+				return
+			}
+			defer conn.Close()
+
+			c := New(conn, Config{CID: cid})
+			if err := c.Accept(tokens.Take); err != nil {
+				served <- err
+				return
+			}
+			served <- c.Serve(&ShareSource{Share: s, CID: cid})
+		}(l, s, uploader.CID, tokens, served)
+	})
+
+	AfterEach(func() {
+		l.Close()
+		s.Close()
+		os.RemoveAll(dir)
+	})
+
+	connect := func(token string, peerCID *encoding.Base32Value) (*Conn, net.Conn, error) {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+
+		c := New(conn, Config{CID: loader.CID})
+		return c, conn, c.Connect(token, peerCID)
+	}
+
+	It("should run the handshake", func() {
+		c, conn, err := connect(token, uploader.CID)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(c.State()).Should(Equal(message.StateNormal))
+		Ω(c.PeerCID().String()).Should(Equal(uploader.CID.String()))
+		Ω(c.PeerFeatures().Has("TIGR")).Should(BeTrue())
+		Ω(c.Token()).Should(Equal(token))
+
+		conn.Close()
+		Eventually(served).Should(Receive(BeNil()))
+
+		// Tokens are only valid once.
+		_, ok := tokens.Take(token)
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("should reject unknown tokens", func() {
+		_, conn, err := connect("unknown", uploader.CID)
+		defer conn.Close()
+
+		Ω(errors.Is(err, message.ErrorCodeProtocolGeneric)).Should(BeTrue())
+		Eventually(served).Should(Receive(Equal(ErrUnknownToken)))
+	})
+
+	It("should reject tokens without an expected CID", func() {
+		tokens.Add("nil-cid", nil)
+
+		_, conn, err := connect("nil-cid", uploader.CID)
+		defer conn.Close()
+
+		Ω(errors.Is(err, message.ErrorCodeProtocolGeneric)).Should(BeTrue())
+		Eventually(served).Should(Receive(Equal(ErrUnknownToken)))
+	})
+
+	It("should verify the CID of the peer", func() {
+		_, conn, err := connect(token, loader.CID)
+		defer conn.Close()
+
+		Ω(err).Should(Equal(ErrCIDMismatch))
+	})
+
+	It("should transfer files", func() {
+		c, conn, err := connect(token, uploader.CID)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		var buf bytes.Buffer
+		n, err := c.Download(&buf, get(NamespaceFile, "TTH/"+tthOf(song).String(), 0, -1))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(n).Should(Equal(int64(len(song))))
+		Ω(buf.String()).Should(Equal(song))
+
+		snd, r, err := c.Get(get(NamespaceFile, "/Music/lyrics.txt", 3, 2))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(snd.Bytes).Should(Equal(2))
+		Ω(c.State()).Should(Equal(message.StateData))
+		data, err := ioutil.ReadAll(r)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(data)).Should(Equal("la"))
+		Ω(c.State()).Should(Equal(message.StateNormal))
+
+		buf.Reset()
+		_, err = c.Download(&buf, get(NamespaceTTHL, "TTH/"+tthOf(song).String(), 0, -1))
+		Ω(err).ShouldNot(HaveOccurred())
+		f, _ := s.ByTTH(tthOf(song))
+		Ω(buf.Bytes()).Should(Equal(f.TTHL))
+
+		res, err := c.Info(NamespaceFile, "TTH/"+tthOf(lyrics).String())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.FN).Should(Equal("/Music/lyrics.txt"))
+		Ω(res.SI).Should(Equal(len(lyrics)))
+
+		src := &ShareSource{Share: s}
+		res, err = src.Info(&message.GFIContent{Namespace: NamespaceFile, Identifer: "/Music/Band/song.mp3"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Named()).Should(Equal(map[string]string{
+			"FN": "/Music/Band/song.mp3",
+			"SI": strconv.Itoa(len(song)),
+			"TR": tthOf(song).String(),
+		}))
+	})
+
+	It("should transfer file lists", func() {
+		c, conn, err := connect(token, uploader.CID)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		var buf bytes.Buffer
+		_, err = c.Download(&buf, get(NamespaceFile, filelist.FileName, 0, -1))
+		Ω(err).ShouldNot(HaveOccurred())
+		list, err := filelist.Parse(&buf)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(list.CID.String()).Should(Equal(uploader.CID.String()))
+		_, f := list.Lookup("/Music/Band/song.mp3")
+		Ω(f).ShouldNot(BeNil())
+		Ω(f.TTH.String()).Should(Equal(tthOf(song).String()))
+
+		buf.Reset()
+		partial := get(NamespaceList, "/Music/", 0, -1)
+		_, err = c.Download(&buf, partial)
+		Ω(err).ShouldNot(HaveOccurred())
+		list, err = filelist.Parse(&buf)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(list.Base).Should(Equal("/Music/"))
+		Ω(list.Root.Dir("Band").Incomplete).Should(BeTrue())
+		Ω(list.Root.File("lyrics.txt")).ShouldNot(BeNil())
+	})
+
+	It("should report unavailable data", func() {
+		c, conn, err := connect(token, uploader.CID)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		_, _, err = c.Get(get(NamespaceFile, "/Music/missing.txt", 0, -1))
+		Ω(errors.Is(err, message.ErrorCodeFileNotAvailable)).Should(BeTrue())
+
+		_, _, err = c.Get(get(NamespaceFile, "/Music/lyrics.txt", 5, 10))
+		Ω(errors.Is(err, message.ErrorCodeFilePartNotAvailable)).Should(BeTrue())
+
+		_, _, err = c.Get(get(NamespaceFile, "/Music/lyrics.txt", 5, math.MaxInt64))
+		Ω(errors.Is(err, message.ErrorCodeFilePartNotAvailable)).Should(BeTrue())
+
+		_, _, err = c.Get(get(NamespaceList, "/Videos/", 0, -1))
+		Ω(errors.Is(err, message.ErrorCodeFileNotAvailable)).Should(BeTrue())
+
+		_, _, err = c.Get(get("blom", "TTH/"+tthOf(song).String(), 0, -1))
+		Ω(errors.Is(err, message.ErrorCodeTransferGeneric)).Should(BeTrue())
+
+		// The connection is still usable.
+		var buf bytes.Buffer
+		_, err = c.Download(&buf, get(NamespaceFile, "/Music/lyrics.txt", 0, -1))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal(lyrics))
+	})
+
+	It("should stop serving if a file is shorter than announced", func() {
+		c, conn, err := connect(token, uploader.CID)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		// The file shrinks after it has been indexed.
+		Ω(os.Truncate(filepath.Join(dir, "Band", "song.mp3"), 100)).Should(Succeed())
+
+		var buf bytes.Buffer
+		_, err = c.Download(&buf, get(NamespaceFile, "/Music/Band/song.mp3", 0, -1))
+		Ω(err).Should(HaveOccurred())
+		Eventually(served).Should(Receive(Equal(ErrShortContent)))
+	})
+
+	It("should cache the file list until the share changes", func() {
+		src := &ShareSource{Share: s, CID: uploader.CID}
+		readList := func() []byte {
+			content, err := src.Open(get(NamespaceFile, filelist.FileName, 0, -1))
+			ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+			data := make([]byte, content.Size())
+			_, err = content.ReadAt(data, 0)
+			ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+			return data
+		}
+
+		first := readList()
+		Ω(readList()).Should(Equal(first))
+
+		Ω(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644)).Should(Succeed())
+		Ω(s.Refresh()).Should(Succeed())
+		s.Wait()
+
+		list, err := filelist.Parse(bytes.NewReader(readList()))
+		Ω(err).ShouldNot(HaveOccurred())
+		_, f := list.Lookup("/Music/notes.txt")
+		Ω(f).ShouldNot(BeNil())
+	})
+
+	It("should create CTM and RCM messages", func() {
+		ctm := ConnectToMe(tthOf("a"), tthOf("b"), 4242, token)
+		Ω(ctm.Type).Should(Equal(message.TypeDirectmessage))
+		Ω(ctm.Content.(*message.CTMContent).Port).Should(Equal("4242"))
+		Ω(ctm.Content.(*message.CTMContent).Protocol).Should(Equal(Protocol))
+
+		rcm := RevConnectToMe(tthOf("a"), tthOf("b"), token)
+		Ω(rcm.Content.(*message.RCMContent).Token).Should(Equal(token))
+	})
+})
@@ -0,0 +1,180 @@
+package tth
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// Constants related to Tiger.
+const (
+	// Size is the size of Tiger hashes and thereby of all nodes of a Tiger
+	// tree in bytes.
+	Size int = 24
+	// BlockSize is the block size of Tiger in bytes.
+	BlockSize int = 64
+)
+
+// Initial state of Tiger.
+const (
+	init0 uint64 = 0x0123456789ABCDEF
+	init1 uint64 = 0xFEDCBA9876543210
+	init2 uint64 = 0xF096A5B4C3B2E187
+)
+
+// digest is the streaming implementation of Tiger.
+type digest struct {
+	s   [3]uint64
+	x   [BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// NewTiger returns a new hash.Hash computing the Tiger hash (with the
+// original 0x01 padding, as used by TTH).
+func NewTiger() hash.Hash {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+// Tiger returns the Tiger hash of data.
+func Tiger(data []byte) (sum [Size]byte) {
+	var d digest
+	d.Reset()
+	d.Write(data)
+	d.checkSum(&sum)
+	return
+}
+
+func (d *digest) Reset() {
+	d.s = [3]uint64{init0, init1, init2}
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *digest) Write(p []byte) (n int, err error) {
+	n = len(p)
+	d.len += uint64(n)
+
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+
+		if d.nx < BlockSize {
+			return
+		}
+
+		compress(&d.s, d.x[:])
+		d.nx = 0
+	}
+
+	for len(p) >= BlockSize {
+		compress(&d.s, p[:BlockSize])
+		p = p[BlockSize:]
+	}
+
+	d.nx = copy(d.x[:], p)
+
+	return
+}
+
+// Sum appends the hash of the data written so far to b. The state of d is
+// not changed.
+func (d *digest) Sum(b []byte) []byte {
+	var sum [Size]byte
+
+	d0 := *d
+	d0.checkSum(&sum)
+
+	return append(b, sum[:]...)
+}
+
+// checkSum pads the written data, which changes the state of d, and writes
+// the hash to sum.
+func (d *digest) checkSum(sum *[Size]byte) {
+	bitLen := d.len << 3
+
+	var pad [BlockSize + 8]byte
+	pad[0] = 0x01
+
+	if d.nx < 56 {
+		d.Write(pad[:56-d.nx])
+	} else {
+		d.Write(pad[:BlockSize+56-d.nx])
+	}
+
+	binary.LittleEndian.PutUint64(pad[:8], bitLen)
+	d.Write(pad[:8])
+
+	for i, s := range d.s {
+		binary.LittleEndian.PutUint64(sum[8*i:], s)
+	}
+}
+
+// compress applies the Tiger compression function to the 64 byte block.
+func compress(s *[3]uint64, block []byte) {
+	var x [8]uint64
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint64(block[8*i:])
+	}
+
+	a, b, c := s[0], s[1], s[2]
+
+	pass(&a, &b, &c, &x, 5)
+	keySchedule(&x)
+	pass(&c, &a, &b, &x, 7)
+	keySchedule(&x)
+	pass(&b, &c, &a, &x, 9)
+
+	s[0] ^= a
+	s[1] = b - s[1]
+	s[2] += c
+}
+
+func pass(a, b, c *uint64, x *[8]uint64, mul uint64) {
+	round(a, b, c, x[0], mul)
+	round(b, c, a, x[1], mul)
+	round(c, a, b, x[2], mul)
+	round(a, b, c, x[3], mul)
+	round(b, c, a, x[4], mul)
+	round(c, a, b, x[5], mul)
+	round(a, b, c, x[6], mul)
+	round(b, c, a, x[7], mul)
+}
+
+func round(a, b, c *uint64, x, mul uint64) {
+	*c ^= x
+	v := *c
+
+	*a -= sbox[0][byte(v)] ^ sbox[1][byte(v>>16)] ^ sbox[2][byte(v>>32)] ^ sbox[3][byte(v>>48)]
+	*b += sbox[3][byte(v>>8)] ^ sbox[2][byte(v>>24)] ^ sbox[1][byte(v>>40)] ^ sbox[0][byte(v>>56)]
+	*b *= mul
+}
+
+func keySchedule(x *[8]uint64) {
+	x[0] -= x[7] ^ 0xA5A5A5A5A5A5A5A5
+	x[1] ^= x[0]
+	x[2] += x[1]
+	x[3] -= x[2] ^ (^x[1] << 19)
+	x[4] ^= x[3]
+	x[5] += x[4]
+	x[6] -= x[5] ^ (^x[4] >> 23)
+	x[7] ^= x[6]
+	x[0] += x[7]
+	x[1] -= x[0] ^ (^x[7] << 19)
+	x[2] ^= x[1]
+	x[3] += x[2]
+	x[4] -= x[3] ^ (^x[2] >> 23)
+	x[5] ^= x[4]
+	x[6] += x[5]
+	x[7] -= x[6] ^ 0x0123456789ABCDEF
+}
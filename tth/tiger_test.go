@@ -0,0 +1,46 @@
+package tth_test
+
+import (
+	"encoding/hex"
+	"strings"
+
+	. "github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func hexTiger(data string) string {
+	sum := Tiger([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("Tiger", func() {
+	It("should compute the reference hashes", func() {
+		Ω(hexTiger("")).Should(Equal("3293ac630c13f0245f92bbb1766e16167a4e58492dde73f3"))
+		Ω(hexTiger("abc")).Should(Equal("2aab1484e8c158f2bfb8c5ff41b57a525129131c957b5f93"))
+		Ω(hexTiger("Tiger")).Should(Equal("dd00230799f5009fec6debc838bb6a27df2b9d6f110c7937"))
+		Ω(hexTiger("The quick brown fox jumps over the lazy dog")).
+			Should(Equal("6d12a41e72e644f017b6f0e2f7b44c6285f06dd5d2c5b075"))
+	})
+
+	It("should produce the same hash when streaming", func() {
+		data := strings.Repeat("0123456789", 100)
+
+		h := NewTiger()
+		for i := 0; i < len(data); i += 7 {
+			end := i + 7
+			if end > len(data) {
+				end = len(data)
+			}
+			h.Write([]byte(data[i:end]))
+		}
+
+		Ω(hex.EncodeToString(h.Sum(nil))).Should(Equal(hexTiger(data)))
+		// Sum does not change the state.
+		Ω(hex.EncodeToString(h.Sum(nil))).Should(Equal(hexTiger(data)))
+
+		h.Reset()
+		Ω(hex.EncodeToString(h.Sum(nil))).Should(Equal(hexTiger("")))
+	})
+})
@@ -0,0 +1,321 @@
+// Package tth implements the Tiger hash function and Tiger Tree Hashes (TTH)
+// as used by the TIGR extension of ADC.
+//
+// A TTH is the root of a Merkle tree over the Tiger hashes of the 1024 byte
+// leaves of a file (see the THEX specification). Internal nodes hash the
+// concatenation of their children, nodes without a sibling are promoted to
+// the next level unchanged. Leaf and internal node hashes are prefixed with
+// 0x00 and 0x01 respectively.
+//
+// Tree computes the TTH of a stream and retains the hashes of one tree level,
+// which is the content of tthl transfers. Verifier checks downloaded segments
+// of a file against such a level.
+package tth
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/seoester/adcl/protocol/encoding"
+)
+
+// Constants related to Tree.
+const (
+	// LeafSize is the size of the data blocks hashed to form the leaves of
+	// the tree.
+	LeafSize int = 1024
+	// DefaultDepth is the depth of the level retained by trees created
+	// by New. Level 10 consists of at most 1024 hashes (24 KiB).
+	DefaultDepth int = 10
+)
+
+// Error variables related to Tree.
+var (
+	ErrLevelUnavailable = errors.New("tree level is not available, it is deeper than the retained level")
+)
+
+// Tree computes the Tiger Tree Hash of the data written to it. It implements
+// hash.Hash, Sum appends the root hash.
+//
+// In addition to the root, Tree retains the hashes of a configurable level
+// of the tree (depth, counted from the root, which has depth 0, as in the TD
+// field of INF and SCH). Memory usage is bounded by the retained depth, not
+// by the amount of data written.
+type Tree struct {
+	depth int
+
+	leaf  [LeafSize]byte
+	nLeaf int
+	// leaves is the number of complete leaves.
+	leaves int64
+
+	// level is the height (counted from the leaves) of the nodes in blocks.
+	level int
+	// blocks contains all complete nodes of the tree at height level.
+	blocks [][Size]byte
+	// stack contains the roots of complete subtrees lower than level, which
+	// have not been combined yet. Their heights are strictly decreasing.
+	stack []node
+}
+
+type node struct {
+	hash   [Size]byte
+	height int
+}
+
+// New creates a new Tree retaining the level DefaultDepth.
+//
+// Equivalent to:
+//     NewWithDepth(DefaultDepth)
+func New() *Tree {
+	return NewWithDepth(DefaultDepth)
+}
+
+// NewWithDepth creates a new Tree retaining the tree level depth. If the
+// tree is shallower, i.e. the data consists of at most 2^depth leaves, all
+// leaves are retained.
+func NewWithDepth(depth int) *Tree {
+	if depth < 0 {
+		depth = 0
+	}
+
+	return &Tree{
+		depth: depth,
+	}
+}
+
+// Sum returns the Tiger Tree Hash of data.
+func Sum(data []byte) (root [Size]byte) {
+	// Only the root is of interest, depth 0 keeps the retained level small.
+	t := NewWithDepth(0)
+	t.Write(data)
+	return t.root()
+}
+
+func (t *Tree) Reset() {
+	*t = Tree{
+		depth:  t.depth,
+		blocks: t.blocks[:0],
+		stack:  t.stack[:0],
+	}
+}
+
+func (t *Tree) Size() int {
+	return Size
+}
+
+func (t *Tree) BlockSize() int {
+	return LeafSize
+}
+
+func (t *Tree) Write(p []byte) (n int, err error) {
+	n = len(p)
+
+	if t.nLeaf > 0 {
+		c := copy(t.leaf[t.nLeaf:], p)
+		t.nLeaf += c
+		p = p[c:]
+
+		if t.nLeaf < LeafSize {
+			return
+		}
+
+		t.addLeaf(leafHash(t.leaf[:]))
+		t.nLeaf = 0
+	}
+
+	for len(p) >= LeafSize {
+		t.addLeaf(leafHash(p[:LeafSize]))
+		p = p[LeafSize:]
+	}
+
+	t.nLeaf = copy(t.leaf[:], p)
+
+	return
+}
+
+// Sum appends the root hash of the data written so far to b. The state of t
+// is not changed.
+func (t *Tree) Sum(b []byte) []byte {
+	root := t.root()
+	return append(b, root[:]...)
+}
+
+// Root returns the root hash of the data written so far as a Base32Value, as
+// used by the TR fields of messages.
+func (t *Tree) Root() *encoding.Base32Value {
+	root := t.root()
+	return encoding.NewBase32Value(root[:])
+}
+
+// Leaves returns the number of leaves of the data written so far. The tree
+// of empty data consists of a single leaf.
+func (t *Tree) Leaves() int64 {
+	if t.nLeaf > 0 || t.leaves == 0 {
+		return t.leaves + 1
+	}
+
+	return t.leaves
+}
+
+// Height returns the depth of the leaves of the data written so far, i.e.
+// the deepest level of the tree.
+func (t *Tree) Height() int {
+	return height(t.Leaves())
+}
+
+// Depth returns the depth of the retained level for the data written so far.
+// It is the smaller of the depth configured for t and Height.
+func (t *Tree) Depth() int {
+	if h := t.Height(); h < t.depth {
+		return h
+	}
+
+	return t.depth
+}
+
+// Level returns the hashes of all nodes at depth of the data written so far.
+// Levels up to depth Depth are available, ErrLevelUnavailable is returned
+// for deeper levels.
+func (t *Tree) Level(depth int) ([][Size]byte, error) {
+	h := t.Height()
+	if depth < 0 || depth > h || h-depth < t.level {
+		return nil, ErrLevelUnavailable
+	}
+
+	level := t.finalBlocks()
+	for i := t.level; i < h-depth; i++ {
+		level = reduce(level)
+	}
+
+	return level, nil
+}
+
+// TTHL returns the concatenated hashes of the level Depth, which is the data
+// of tthl transfers.
+func (t *Tree) TTHL() []byte {
+	level, _ := t.Level(t.Depth())
+
+	data := make([]byte, 0, len(level)*Size)
+	for _, hash := range level {
+		data = append(data, hash[:]...)
+	}
+
+	return data
+}
+
+// addLeaf adds a complete leaf to the tree.
+func (t *Tree) addLeaf(hash [Size]byte) {
+	t.leaves++
+	t.stack = append(t.stack, node{hash: hash})
+
+	for len(t.stack) >= 2 {
+		l, r := t.stack[len(t.stack)-2], t.stack[len(t.stack)-1]
+		if l.height != r.height {
+			break
+		}
+
+		t.stack = t.stack[:len(t.stack)-2]
+		t.stack = append(t.stack, node{
+			hash:   internalHash(&l.hash, &r.hash),
+			height: l.height + 1,
+		})
+	}
+
+	if top := t.stack[len(t.stack)-1]; top.height == t.level {
+		// Heights in stack are strictly decreasing, top is the only node.
+		t.stack = t.stack[:0]
+		t.blocks = append(t.blocks, top.hash)
+	}
+
+	if len(t.blocks) == 2<<uint(t.depth) {
+		// Too many blocks, move one level up. As blocks are only reduced
+		// if there are twice as many as required, the retained level is
+		// always available.
+		t.blocks = reduce(t.blocks)
+		t.level++
+	}
+}
+
+// finalBlocks returns the blocks including the last, incomplete block formed
+// by the stack and the partial leaf. t is not changed.
+func (t *Tree) finalBlocks() [][Size]byte {
+	blocks := make([][Size]byte, len(t.blocks), len(t.blocks)+1)
+	copy(blocks, t.blocks)
+
+	var last [Size]byte
+	hasLast := false
+
+	if t.nLeaf > 0 || t.leaves == 0 {
+		last = leafHash(t.leaf[:t.nLeaf])
+		hasLast = true
+	}
+
+	for i := len(t.stack) - 1; i >= 0; i-- {
+		if hasLast {
+			last = internalHash(&t.stack[i].hash, &last)
+		} else {
+			last = t.stack[i].hash
+			hasLast = true
+		}
+	}
+
+	if hasLast {
+		blocks = append(blocks, last)
+	}
+
+	return blocks
+}
+
+func (t *Tree) root() [Size]byte {
+	level := t.finalBlocks()
+	for len(level) > 1 {
+		level = reduce(level)
+	}
+
+	return level[0]
+}
+
+// height returns the height of a tree with n leaves.
+func height(n int64) int {
+	if n <= 1 {
+		return 0
+	}
+
+	return bits.Len64(uint64(n - 1))
+}
+
+// reduce returns the next level above level. level is modified.
+func reduce(level [][Size]byte) [][Size]byte {
+	n := 0
+
+	for i := 0; i < len(level); i += 2 {
+		if i+1 < len(level) {
+			level[n] = internalHash(&level[i], &level[i+1])
+		} else {
+			level[n] = level[i]
+		}
+		n++
+	}
+
+	return level[:n]
+}
+
+func leafHash(data []byte) (sum [Size]byte) {
+	var d digest
+	d.Reset()
+	d.Write([]byte{0x00})
+	d.Write(data)
+	d.checkSum(&sum)
+	return
+}
+
+func internalHash(l, r *[Size]byte) (sum [Size]byte) {
+	var d digest
+	d.Reset()
+	d.Write([]byte{0x01})
+	d.Write(l[:])
+	d.Write(r[:])
+	d.checkSum(&sum)
+	return
+}
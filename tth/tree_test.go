@@ -0,0 +1,180 @@
+package tth_test
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/seoester/adcl/protocol/encoding"
+	. "github.com/seoester/adcl/tth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// referenceLevels builds the complete tree of data level by level, the first
+// element is the root level.
+func referenceLevels(data []byte) [][][Size]byte {
+	var leaves [][Size]byte
+	for i := 0; i == 0 || i < len(data); i += LeafSize {
+		end := i + LeafSize
+		if end > len(data) {
+			end = len(data)
+		}
+		leaves = append(leaves, Tiger(append([]byte{0x00}, data[i:end]...)))
+	}
+
+	levels := [][][Size]byte{leaves}
+	for len(levels[0]) > 1 {
+		var next [][Size]byte
+		below := levels[0]
+		for i := 0; i < len(below); i += 2 {
+			if i+1 == len(below) {
+				next = append(next, below[i])
+				continue
+			}
+			buf := append([]byte{0x01}, below[i][:]...)
+			next = append(next, Tiger(append(buf, below[i+1][:]...)))
+		}
+		levels = append([][][Size]byte{next}, levels...)
+	}
+
+	return levels
+}
+
+func base32Root(data []byte) string {
+	root := Sum(data)
+	return encoding.EncodeToBase32String(root[:])
+}
+
+func concat(level [][Size]byte) []byte {
+	var buf []byte
+	for _, hash := range level {
+		buf = append(buf, hash[:]...)
+	}
+	return buf
+}
+
+var _ = Describe("Tree", func() {
+	It("should compute the reference roots", func() {
+		Ω(base32Root(nil)).Should(Equal("LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"))
+		Ω(base32Root([]byte{0})).Should(Equal("VK54ZIEEVTWNAUI5D5RDFIL37LX2IQNSTAXFKSA"))
+		Ω(base32Root([]byte(strings.Repeat("A", 1024)))).Should(Equal("L66Q4YVNAFWVS23X2HJIRA5ZJ7WXR3F26RSASFA"))
+		Ω(base32Root([]byte(strings.Repeat("A", 1025)))).Should(Equal("PZMRYHGY6LTBEH63ZWAHDORHSYTLO4LEFUIKHWY"))
+	})
+
+	It("should match the reference tree for all sizes and depths", func() {
+		rnd := rand.New(rand.NewSource(1))
+		data := make([]byte, 40*LeafSize)
+		rnd.Read(data)
+
+		for _, size := range []int{0, 1, 1023, 1024, 1025, 2048, 3 * 1024, 5*1024 + 17, 16 * 1024, 40 * 1024} {
+			levels := referenceLevels(data[:size])
+
+			for depth := 0; depth <= 4; depth++ {
+				t := NewWithDepth(depth)
+				// Write in chunks not aligned to leaves.
+				for i := 0; i < size; i += 700 {
+					end := i + 700
+					if end > size {
+						end = size
+					}
+					t.Write(data[i:end])
+				}
+
+				Ω(t.Sum(nil)).Should(Equal(levels[0][0][:]), "size %d", size)
+				Ω(t.Root().Raw()).Should(Equal(levels[0][0][:]))
+				Ω(t.Height()).Should(Equal(len(levels) - 1))
+
+				for d := 0; d <= t.Depth(); d++ {
+					level, err := t.Level(d)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(level).Should(Equal(levels[d]), "size %d, depth %d, level %d", size, depth, d)
+				}
+
+				Ω(t.TTHL()).Should(Equal(concat(levels[t.Depth()])))
+			}
+		}
+	})
+
+	It("should bound the retained level", func() {
+		t := NewWithDepth(2)
+		t.Write(make([]byte, 64*LeafSize))
+
+		Ω(t.Height()).Should(Equal(6))
+		Ω(t.Depth()).Should(Equal(2))
+		Ω(t.TTHL()).Should(HaveLen(4 * Size))
+
+		_, err := t.Level(6)
+		Ω(err).Should(Equal(ErrLevelUnavailable))
+	})
+
+	It("should be reusable after Reset", func() {
+		t := New()
+		t.Write([]byte("data"))
+		t.Reset()
+
+		Ω(t.Root().String()).Should(Equal("LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"))
+	})
+})
+
+var _ = Describe("Verifier", func() {
+	var (
+		data []byte
+		tree *Tree
+	)
+
+	BeforeEach(func() {
+		data = make([]byte, 10*LeafSize+100)
+		rand.New(rand.NewSource(2)).Read(data)
+
+		tree = NewWithDepth(2)
+		tree.Write(data)
+	})
+
+	It("should verify segments against the tthl data", func() {
+		v, err := NewVerifier(tree.Root(), int64(len(data)), tree.TTHL())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.BlockSize()).Should(Equal(int64(4 * LeafSize)))
+
+		Ω(v.VerifySegment(0, data[:4*LeafSize])).Should(Succeed())
+		Ω(v.VerifySegment(4*int64(LeafSize), data[4*LeafSize:])).Should(Succeed())
+		Ω(v.VerifySegment(0, data)).Should(Succeed())
+
+		corrupted := append([]byte(nil), data[8*LeafSize:]...)
+		corrupted[5] ^= 0xFF
+		Ω(v.VerifySegment(8*int64(LeafSize), corrupted)).Should(Equal(ErrSegmentMismatch))
+	})
+
+	It("should reject unaligned segments", func() {
+		v, err := NewVerifier(tree.Root(), int64(len(data)), tree.TTHL())
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(v.VerifySegment(int64(LeafSize), data[LeafSize:5*LeafSize])).Should(Equal(ErrUnalignedSegment))
+		Ω(v.VerifySegment(0, data[:LeafSize])).Should(Equal(ErrUnalignedSegment))
+		Ω(v.VerifySegment(8*int64(LeafSize), append(data[8*LeafSize:], 0))).Should(Equal(ErrUnalignedSegment))
+	})
+
+	It("should reject tthl data not matching the root", func() {
+		tthl := tree.TTHL()
+		tthl[0] ^= 0xFF
+
+		_, err := NewVerifier(tree.Root(), int64(len(data)), tthl)
+		Ω(err).Should(Equal(ErrRootMismatch))
+
+		_, err = NewVerifier(tree.Root(), int64(len(data)), make([]byte, 5*Size))
+		Ω(err).Should(Equal(ErrInvalidTTHL))
+		_, err = NewVerifier(tree.Root(), int64(len(data)), tthl[:Size+1])
+		Ω(err).Should(Equal(ErrInvalidTTHL))
+	})
+
+	It("should verify entire files against the root", func() {
+		v, err := NewVerifier(tree.Root(), int64(len(data)), nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.VerifySegment(0, data)).Should(Succeed())
+
+		empty := New()
+		v, err = NewVerifier(empty.Root(), 0, empty.TTHL())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.VerifySegment(0, nil)).Should(Succeed())
+	})
+})
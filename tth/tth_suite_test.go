@@ -0,0 +1,13 @@
+package tth_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTTH(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TTH Suite")
+}
@@ -0,0 +1,124 @@
+package tth
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/seoester/adcl/protocol/encoding"
+)
+
+// Error variables related to Verifier.
+var (
+	ErrInvalidTTHL      = errors.New("invalid tthl data, it does not form a tree level of the file size")
+	ErrRootMismatch     = errors.New("tthl data does not match the root hash")
+	ErrUnalignedSegment = errors.New("segment does not consist of whole blocks of the verifier")
+	ErrSegmentMismatch  = errors.New("segment does not match the tree")
+)
+
+// Verifier verifies segments of a file against the hashes of one level of
+// its tree, e.g. as obtained by a tthl transfer. The level itself is
+// verified against the root hash when creating the Verifier.
+type Verifier struct {
+	size      int64
+	blockSize int64
+	level     [][Size]byte
+}
+
+// NewVerifier creates a Verifier for a file of size bytes with the root hash
+// root. tthl is the concatenation of the hashes of one level of the tree. If
+// tthl does not form a level of a file of size bytes, ErrInvalidTTHL is
+// returned. If the level does not hash to root, ErrRootMismatch is returned.
+//
+// An empty tthl is interpreted as the root level, i.e. only entire files can
+// be verified.
+func NewVerifier(root *encoding.Base32Value, size int64, tthl []byte) (*Verifier, error) {
+	if size < 0 || len(tthl)%Size != 0 || len(root.Raw()) != Size {
+		return nil, ErrInvalidTTHL
+	}
+	if len(tthl) == 0 {
+		tthl = root.Raw()
+	}
+
+	level := make([][Size]byte, len(tthl)/Size)
+	for i := range level {
+		copy(level[i][:], tthl[i*Size:])
+	}
+
+	leaves := (size + int64(LeafSize) - 1) / int64(LeafSize)
+	if leaves == 0 {
+		leaves = 1
+	}
+
+	// Find the depth with as many nodes as level.
+	h := height(leaves)
+	blockSize := int64(-1)
+	for depth := 0; depth <= h; depth++ {
+		blockLeaves := int64(1) << uint(h-depth)
+		if (leaves+blockLeaves-1)/blockLeaves == int64(len(level)) {
+			blockSize = blockLeaves * int64(LeafSize)
+			break
+		}
+	}
+	if blockSize < 0 {
+		return nil, ErrInvalidTTHL
+	}
+
+	rootLevel := make([][Size]byte, len(level))
+	copy(rootLevel, level)
+	for len(rootLevel) > 1 {
+		rootLevel = reduce(rootLevel)
+	}
+	if !bytes.Equal(rootLevel[0][:], root.Raw()) {
+		return nil, ErrRootMismatch
+	}
+
+	return &Verifier{
+		size:      size,
+		blockSize: blockSize,
+		level:     level,
+	}, nil
+}
+
+// BlockSize returns the size of the data covered by each hash of the level.
+// Segments passed to VerifySegment must consist of whole blocks, only the
+// last block of the file may be shorter.
+func (v *Verifier) BlockSize() int64 {
+	return v.blockSize
+}
+
+// VerifySegment verifies data, which is the segment of the file starting at
+// offset. offset must be a multiple of BlockSize and data must consist of
+// whole blocks or end at the end of the file, otherwise ErrUnalignedSegment
+// is returned. If data does not match the level, ErrSegmentMismatch is
+// returned.
+func (v *Verifier) VerifySegment(offset int64, data []byte) error {
+	end := offset + int64(len(data))
+
+	if offset < 0 || offset%v.blockSize != 0 || end > v.size ||
+		(int64(len(data))%v.blockSize != 0 && end != v.size) {
+		return ErrUnalignedSegment
+	}
+
+	if v.size == 0 {
+		// The empty file consists of a single, empty leaf.
+		if Sum(nil) != v.level[0] {
+			return ErrSegmentMismatch
+		}
+		return nil
+	}
+
+	for i := offset / v.blockSize; len(data) > 0; i++ {
+		n := v.blockSize
+		if int64(len(data)) < n {
+			n = int64(len(data))
+		}
+
+		if Sum(data[:n]) != v.level[i] {
+			return ErrSegmentMismatch
+		}
+
+		data = data[n:]
+	}
+
+	return nil
+}